@@ -0,0 +1,315 @@
+// Package accesslog implements a local, append-only log of decrypted secrets.
+// Each entry is encrypted to the user and contains a MAC of the previous
+// entry, so removed or modified entries can be detected. The MAC key and the
+// number of entries are kept in an encrypted state file next to the log, so
+// entries removed from the end are detected, too.
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+)
+
+// ErrBrokenChain is returned if an entry does not refer to its predecessor
+// or if entries are missing at the end of the log.
+var ErrBrokenChain = errors.New("access log has been modified")
+
+// tailSize is the number of bytes read from the end of the log to find the
+// last entry. Entries are much smaller than this.
+const tailSize = 64 * 1024
+
+// Path returns the location of the access log. It lives outside of all
+// stores, so it is never synced.
+func Path() string {
+	return filepath.Join(appdir.UserData(), "accesslog")
+}
+
+// Entry is a single access to a secret.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Secret  string    `json:"secret"`
+	Action  string    `json:"action"`
+	Command string    `json:"command,omitempty"`
+	Prev    string    `json:"prev,omitempty"`
+}
+
+// state is stored encrypted next to the log. Key authenticates the entries,
+// Count and Tail anchor the end of the log.
+type state struct {
+	Key   []byte `json:"key"`
+	Count int    `json:"count"`
+	Tail  string `json:"tail,omitempty"`
+}
+
+// Log is an access log file.
+type Log struct {
+	path   string
+	crypto backend.Crypto
+}
+
+// New returns an access log at path. Entries are encrypted with crypto to the
+// first identity of the user.
+func New(path string, crypto backend.Crypto) *Log {
+	return &Log{
+		path:   path,
+		crypto: crypto,
+	}
+}
+
+func (l *Log) statePath() string {
+	return l.path + ".state"
+}
+
+// Append adds a new entry to the log. It refuses to extend a log that has
+// been modified.
+func (l *Log) Append(ctx context.Context, secret, action string) error {
+	ids, err := l.crypto.ListIdentities(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
+	}
+	if len(ids) < 1 {
+		return fmt.Errorf("no identity to encrypt the access log to")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+
+	fh, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open access log: %w", err)
+	}
+	defer fh.Close() //nolint:errcheck
+
+	if err := lock(fh); err != nil {
+		return fmt.Errorf("failed to lock access log: %w", err)
+	}
+	defer unlock(fh) //nolint:errcheck
+
+	prev, err := lastLine(fh)
+	if err != nil {
+		return err
+	}
+
+	st, err := l.loadState(ctx)
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		if prev != "" {
+			return fmt.Errorf("%w: the state file is missing", ErrBrokenChain)
+		}
+		st = &state{Key: make([]byte, 32)}
+		if _, err := rand.Read(st.Key); err != nil {
+			return err
+		}
+	}
+	if prev != "" && st.Tail != st.mac(prev) {
+		return fmt.Errorf("%w: the last entry does not match the state file", ErrBrokenChain)
+	}
+
+	e := Entry{
+		Time:    time.Now().UTC(),
+		Secret:  secret,
+		Action:  action,
+		Command: ctxutil.GetCommandName(ctx),
+	}
+	if prev != "" {
+		e.Prev = st.mac(prev)
+	}
+
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := l.crypto.Encrypt(ctx, buf, ids[:1])
+	if err != nil {
+		return fmt.Errorf("failed to encrypt access log entry: %w", err)
+	}
+
+	line := base64.StdEncoding.EncodeToString(ciphertext)
+	if _, err := fmt.Fprintln(fh, line); err != nil {
+		return fmt.Errorf("failed to write access log: %w", err)
+	}
+
+	st.Count++
+	st.Tail = st.mac(line)
+
+	return l.saveState(ctx, st, ids[:1])
+}
+
+// Entries decrypts all entries and verifies the chain. If it is broken the
+// entries before the first broken link are returned along with
+// ErrBrokenChain.
+func (l *Log) Entries(ctx context.Context) ([]Entry, error) {
+	fh, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if _, err := os.Stat(l.statePath()); err == nil {
+				return nil, fmt.Errorf("%w: the log file is missing", ErrBrokenChain)
+			}
+
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read access log: %w", err)
+	}
+	defer fh.Close() //nolint:errcheck
+
+	if err := lock(fh); err != nil {
+		return nil, fmt.Errorf("failed to lock access log: %w", err)
+	}
+	defer unlock(fh) //nolint:errcheck
+
+	buf, err := io.ReadAll(fh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access log: %w", err)
+	}
+
+	st, err := l.loadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		if len(bytes.TrimSpace(buf)) == 0 {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%w: the state file is missing", ErrBrokenChain)
+	}
+
+	var entries []Entry
+	var prev string
+	for i, line := range strings.Split(strings.TrimSpace(string(buf)), "\n") {
+		if line == "" {
+			continue
+		}
+
+		ciphertext, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return entries, fmt.Errorf("%w: entry %d is invalid: %w", ErrBrokenChain, i+1, err)
+		}
+
+		plaintext, err := l.crypto.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return entries, fmt.Errorf("failed to decrypt entry %d: %w", i+1, err)
+		}
+
+		var e Entry
+		if err := json.Unmarshal(plaintext, &e); err != nil {
+			return entries, fmt.Errorf("%w: entry %d is invalid: %w", ErrBrokenChain, i+1, err)
+		}
+
+		want := ""
+		if prev != "" {
+			want = st.mac(prev)
+		}
+		if !hmac.Equal([]byte(e.Prev), []byte(want)) {
+			return entries, fmt.Errorf("%w: entry %d does not follow entry %d", ErrBrokenChain, i+1, i)
+		}
+
+		entries = append(entries, e)
+		prev = line
+	}
+
+	if len(entries) != st.Count || (prev != "" && st.Tail != st.mac(prev)) {
+		return entries, fmt.Errorf("%w: found %d of %d entries", ErrBrokenChain, len(entries), st.Count)
+	}
+
+	return entries, nil
+}
+
+// loadState decrypts the state file. It returns nil if there is none.
+func (l *Log) loadState(ctx context.Context) (*state, error) {
+	ciphertext, err := os.ReadFile(l.statePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read access log state: %w", err)
+	}
+
+	plaintext, err := l.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt access log state: %w", err)
+	}
+
+	st := &state{}
+	if err := json.Unmarshal(plaintext, st); err != nil || len(st.Key) < 1 {
+		return nil, fmt.Errorf("%w: the state file is invalid", ErrBrokenChain)
+	}
+
+	return st, nil
+}
+
+// saveState encrypts and replaces the state file.
+func (l *Log) saveState(ctx context.Context, st *state, recipients []string) error {
+	buf, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := l.crypto.Encrypt(ctx, buf, recipients)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt access log state: %w", err)
+	}
+
+	tmp := l.statePath() + ".tmp"
+	if err := os.WriteFile(tmp, ciphertext, 0o600); err != nil {
+		return fmt.Errorf("failed to write access log state: %w", err)
+	}
+
+	return os.Rename(tmp, l.statePath())
+}
+
+// mac authenticates a line of the log.
+func (st *state) mac(line string) string {
+	h := hmac.New(sha256.New, st.Key)
+	_, _ = h.Write([]byte(line))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lastLine returns the last entry of the log or an empty string.
+func lastLine(fh *os.File) (string, error) {
+	fi, err := fh.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	off := fi.Size() - tailSize
+	if off < 0 {
+		off = 0
+	}
+
+	buf := make([]byte, fi.Size()-off)
+	if _, err := fh.ReadAt(buf, off); err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	buf = bytes.TrimRight(buf, "\n")
+	if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+		buf = buf[i+1:]
+	}
+
+	return string(buf), nil
+}
@@ -0,0 +1,144 @@
+package accesslog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := ctxutil.WithCommandName(context.Background(), "show")
+	fn := filepath.Join(t.TempDir(), "log", "accesslog")
+	l := New(fn, plain.New())
+
+	entries, err := l.Entries(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	for _, name := range []string{"foo", "bar", "baz"} {
+		require.NoError(t, l.Append(ctx, name, "decrypt"))
+	}
+
+	entries, err = l.Entries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "foo", entries[0].Secret)
+	assert.Equal(t, "decrypt", entries[0].Action)
+	assert.Equal(t, "show", entries[0].Command)
+	assert.Empty(t, entries[0].Prev)
+	assert.NotEmpty(t, entries[1].Prev)
+
+	fi, err := os.Stat(fn)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	t.Run("removed entry", func(t *testing.T) {
+		buf, err := os.ReadFile(fn)
+		require.NoError(t, err)
+		lines := strings.Split(string(buf), "\n")
+		lines = append(lines[:1], lines[2:]...)
+		require.NoError(t, os.WriteFile(fn, []byte(strings.Join(lines, "\n")), 0o600))
+
+		entries, err := l.Entries(ctx)
+		require.ErrorIs(t, err, ErrBrokenChain)
+		assert.Len(t, entries, 1)
+	})
+}
+
+func TestLogTampering(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newLog := func(t *testing.T) (*Log, string) {
+		t.Helper()
+
+		fn := filepath.Join(t.TempDir(), "accesslog")
+		l := New(fn, plain.New())
+		for _, name := range []string{"foo", "bar", "baz"} {
+			require.NoError(t, l.Append(ctx, name, "decrypt"))
+		}
+
+		return l, fn
+	}
+
+	readLines := func(t *testing.T, fn string) []string {
+		t.Helper()
+
+		buf, err := os.ReadFile(fn)
+		require.NoError(t, err)
+
+		return strings.Split(strings.TrimSpace(string(buf)), "\n")
+	}
+
+	t.Run("truncated tail", func(t *testing.T) {
+		l, fn := newLog(t)
+		lines := readLines(t, fn)
+		require.NoError(t, os.WriteFile(fn, []byte(strings.Join(lines[:2], "\n")+"\n"), 0o600))
+
+		entries, err := l.Entries(ctx)
+		require.ErrorIs(t, err, ErrBrokenChain)
+		assert.Len(t, entries, 2)
+
+		// the log is not extended after it was modified.
+		require.ErrorIs(t, l.Append(ctx, "foo", "decrypt"), ErrBrokenChain)
+	})
+
+	t.Run("forged entry", func(t *testing.T) {
+		l, fn := newLog(t)
+		lines := readLines(t, fn)
+
+		// the entry refers to its predecessor, but without the key.
+		sum := sha256.Sum256([]byte(lines[2]))
+		buf, err := json.Marshal(Entry{Secret: "forged", Prev: hex.EncodeToString(sum[:])})
+		require.NoError(t, err)
+		lines = append(lines, base64.StdEncoding.EncodeToString(buf))
+		require.NoError(t, os.WriteFile(fn, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+
+		entries, err := l.Entries(ctx)
+		require.ErrorIs(t, err, ErrBrokenChain)
+		assert.Len(t, entries, 3)
+	})
+
+	t.Run("missing state", func(t *testing.T) {
+		l, fn := newLog(t)
+		require.NoError(t, os.Remove(fn+".state"))
+
+		_, err := l.Entries(ctx)
+		require.ErrorIs(t, err, ErrBrokenChain)
+		require.ErrorIs(t, l.Append(ctx, "foo", "decrypt"), ErrBrokenChain)
+	})
+
+	t.Run("concurrent appends", func(t *testing.T) {
+		l, _ := newLog(t)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				assert.NoError(t, New(l.path, plain.New()).Append(ctx, "foo", "decrypt"))
+			}()
+		}
+		wg.Wait()
+
+		entries, err := l.Entries(ctx)
+		require.NoError(t, err)
+		assert.Len(t, entries, 13)
+	})
+}
@@ -0,0 +1,43 @@
+package accesslog
+
+import "context"
+
+type contextKey int
+
+const (
+	ctxKeyInternalRead contextKey = iota
+	ctxKeyAction
+)
+
+// WithInternalRead returns a context that marks decryptions as internal,
+// e.g. to re-encrypt or move a secret. Those are not written to the access
+// log because the content is never shown or handed to the user.
+func WithInternalRead(ctx context.Context, internal bool) context.Context {
+	return context.WithValue(ctx, ctxKeyInternalRead, internal)
+}
+
+// IsInternalRead returns true if decryptions should not be logged.
+func IsInternalRead(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyInternalRead).(bool)
+	if !ok {
+		return false
+	}
+
+	return bv
+}
+
+// WithAction returns a context with the action that is recorded for the
+// secrets decrypted with it, e.g. "edit".
+func WithAction(ctx context.Context, action string) context.Context {
+	return context.WithValue(ctx, ctxKeyAction, action)
+}
+
+// GetAction returns the action set in the context or def.
+func GetAction(ctx context.Context, def string) string {
+	sv, ok := ctx.Value(ctxKeyAction).(string)
+	if !ok || sv == "" {
+		return def
+	}
+
+	return sv
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package accesslog
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive lock on fh. It blocks until the lock is available.
+func lock(fh *os.File) error {
+	return syscall.Flock(int(fh.Fd()), syscall.LOCK_EX)
+}
+
+func unlock(fh *os.File) error {
+	return syscall.Flock(int(fh.Fd()), syscall.LOCK_UN)
+}
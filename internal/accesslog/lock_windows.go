@@ -0,0 +1,16 @@
+package accesslog
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lock takes an exclusive lock on fh. It blocks until the lock is available.
+func lock(fh *os.File) error {
+	return windows.LockFileEx(windows.Handle(fh.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
+
+func unlock(fh *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(fh.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
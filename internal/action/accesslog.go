@@ -0,0 +1,75 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/accesslog"
+	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// AccessLog prints the entries of the local access log.
+func (s *Action) AccessLog(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	var since time.Time
+	if sv := c.String("since"); sv != "" {
+		ts, err := parseSince(sv)
+		if err != nil {
+			return exit.Error(exit.Usage, err, "Invalid value for --since: %s", err)
+		}
+		since = ts
+	}
+	secret := strings.TrimSuffix(c.String("secret"), "/")
+
+	if !config.AsBool(s.cfg.GetGlobal("core.accesslog")) {
+		out.Noticef(ctx, "The access log is disabled. Enable it with '%s config core.accesslog true'", s.Name)
+	}
+
+	entries, err := accesslog.New(accesslog.Path(), s.Store.Crypto(ctx, "")).Entries(ctx)
+	for _, e := range entries {
+		if e.Time.Before(since) {
+			continue
+		}
+		if secret != "" && e.Secret != secret && !strings.HasPrefix(e.Secret, secret+"/") {
+			continue
+		}
+
+		fmt.Fprintf(stdout, "%s - %s - %s", e.Time.Local().Format(time.RFC3339), e.Secret, e.Action)
+		if e.Command != "" {
+			fmt.Fprintf(stdout, " (%s %s)", s.Name, e.Command)
+		}
+		fmt.Fprintln(stdout)
+	}
+
+	if errors.Is(err, accesslog.ErrBrokenChain) {
+		return exit.Error(exit.Audit, err, "The access log has been tampered with: %s", err)
+	}
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "Failed to read the access log: %s", err)
+	}
+
+	return nil
+}
+
+// parseSince accepts a duration, e.g. 12h or 7d, or a date.
+func parseSince(sv string) (time.Time, error) {
+	if d, err := str2duration.ParseDuration(sv); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if ts, err := time.ParseInLocation(layout, sv, time.Local); err == nil {
+			return ts, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is neither a duration nor a date", sv)
+}
@@ -0,0 +1,101 @@
+package action
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+	}()
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("123")
+	require.NoError(t, act.Store.Set(ctx, "bar/baz", sec))
+
+	t.Run("disabled", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "foo")))
+		buf.Reset()
+
+		require.NoError(t, act.AccessLog(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "access log is disabled")
+		assert.NotContains(t, buf.String(), "foo")
+	})
+
+	require.NoError(t, act.cfg.Set("", "core.accesslog", "true"))
+
+	t.Run("log decrypted secrets", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "foo")))
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "bar/baz")))
+		buf.Reset()
+
+		require.NoError(t, act.AccessLog(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), " - foo - decrypt")
+		assert.Contains(t, buf.String(), " - bar/baz - decrypt")
+	})
+
+	t.Run("log the command action", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.NoError(t, act.Grep(gptest.CliCtx(ctx, t, "123")))
+		buf.Reset()
+
+		require.NoError(t, act.AccessLog(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, 2, strings.Count(buf.String(), " - decrypt"))
+		assert.Contains(t, buf.String(), " - bar/baz - grep")
+	})
+
+	t.Run("filter by secret", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.NoError(t, act.AccessLog(gptest.CliCtxWithFlags(ctx, t, map[string]string{"secret": "bar/"})))
+		assert.Contains(t, buf.String(), "bar/baz")
+		assert.NotContains(t, buf.String(), "foo")
+	})
+
+	t.Run("filter by time", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.NoError(t, act.AccessLog(gptest.CliCtxWithFlags(ctx, t, map[string]string{"since": "1h"})))
+		assert.Contains(t, buf.String(), "foo")
+		buf.Reset()
+
+		require.NoError(t, act.AccessLog(gptest.CliCtxWithFlags(ctx, t, map[string]string{"since": "2099-01-01"})))
+		assert.NotContains(t, buf.String(), "foo")
+
+		require.Error(t, act.AccessLog(gptest.CliCtxWithFlags(ctx, t, map[string]string{"since": "yesterday"})))
+	})
+}
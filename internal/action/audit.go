@@ -4,7 +4,9 @@ import (
 	"context"
 	"io"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/action/exit"
 	"github.com/gopasspw/gopass/internal/audit"
@@ -14,6 +16,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/fsutil"
 	"github.com/urfave/cli/v2"
+	"github.com/xhit/go-str2duration/v2"
 )
 
 // Audit validates passwords against common flaws.
@@ -39,6 +42,16 @@ func (s *Action) Audit(c *cli.Context) error {
 
 	list := t.List(tree.INF)
 
+	if tags := c.StringSlice("tag"); len(tags) > 0 {
+		tagged, err := s.Store.Tagged(ctx, tags...)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to read tag index: %s", err)
+		}
+		list = slices.DeleteFunc(list, func(name string) bool {
+			return !slices.Contains(tagged, name)
+		})
+	}
+
 	if len(list) < 1 {
 		out.Printf(ctx, "No secrets found")
 
@@ -61,6 +74,35 @@ func (s *Action) Audit(c *cli.Context) error {
 		return exit.Error(exit.Unknown, err, "failed to audit password store: %s", err)
 	}
 
+	if c.Bool("write-baseline") {
+		return s.writeAuditBaseline(ctx, c, r)
+	}
+
+	if !c.Bool("ignore-baseline") {
+		sub, err := s.Store.GetSubStore("")
+		if err != nil {
+			return exit.Error(exit.Unknown, err, "failed to get root store: %s", err)
+		}
+		bl, err := audit.LoadBaseline(ctx, sub)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to load audit baseline: %s", err)
+		}
+		if n := bl.Apply(r); n > 0 {
+			out.Noticef(ctx, "Suppressed %d acknowledged findings based on the audit baseline", n)
+		}
+	}
+
+	// open items of the rotation worklist can not be acknowledged.
+	for _, store := range append([]string{""}, s.Store.MountPoints()...) {
+		wl, err := s.rotationWorklist(ctx, store)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to load rotation worklist of %q: %s", store, err)
+		}
+		if n := wl.Apply(r, store); n > 0 {
+			out.Warningf(ctx, "%d secrets need to be rotated after offboarding a recipient", n)
+		}
+	}
+
 	if p := c.String("template"); p != "" && fsutil.IsFile(p) {
 		r.Template = p
 	}
@@ -93,6 +135,39 @@ func (s *Action) Audit(c *cli.Context) error {
 	}
 }
 
+func (s *Action) writeAuditBaseline(ctx context.Context, c *cli.Context, r *audit.Report) error {
+	var expires time.Time
+	if d := c.String("expires"); d != "" {
+		dur, err := str2duration.ParseDuration(d)
+		if err != nil {
+			return exit.Error(exit.Usage, err, "failed to parse expiry %q: %s", d, err)
+		}
+		expires = time.Now().UTC().Add(dur)
+	}
+
+	sub, err := s.Store.GetSubStore("")
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "failed to get root store: %s", err)
+	}
+
+	bl, err := audit.LoadBaseline(ctx, sub)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to load audit baseline: %s", err)
+	}
+
+	if err := bl.Update(r, c.String("justification"), expires); err != nil {
+		return exit.Error(exit.Unknown, err, "failed to update audit baseline: %s", err)
+	}
+
+	if err := audit.SaveBaseline(ctx, sub, bl); err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to write audit baseline: %s", err)
+	}
+
+	out.OKf(ctx, "Wrote audit baseline with %d acknowledged findings", len(bl.Entries))
+
+	return nil
+}
+
 func saveReport(ctx context.Context, f func(io.Writer) error, path, suffix string) error {
 	if path == "" {
 		out.Noticef(ctx, "No output filename given. Will use a random file name. Use `--output-file` to specify.")
@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/audit"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -58,3 +59,54 @@ func TestAudit(t *testing.T) {
 		buf.Reset()
 	})
 }
+
+func TestAuditBaseline(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("123")
+	require.NoError(t, act.Store.Set(ctx, "bar", sec))
+
+	t.Run("weak password is reported", func(t *testing.T) {
+		require.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"full": "true"})))
+		buf.Reset()
+	})
+
+	t.Run("write baseline", func(t *testing.T) {
+		require.NoError(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"write-baseline": "true", "justification": "test"})))
+		assert.True(t, act.Store.Storage(ctx, "").Exists(ctx, audit.BaselineFile))
+		buf.Reset()
+	})
+
+	t.Run("acknowledged findings are suppressed", func(t *testing.T) {
+		require.NoError(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"full": "true"})))
+		buf.Reset()
+	})
+
+	t.Run("ignore baseline", func(t *testing.T) {
+		require.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"full": "true", "ignore-baseline": "true"})))
+		buf.Reset()
+	})
+
+	t.Run("new findings are reported", func(t *testing.T) {
+		require.NoError(t, act.Store.Set(ctx, "baz", sec))
+		require.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"full": "true"})))
+		buf.Reset()
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/action/exit"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -263,7 +264,7 @@ func (s *Action) binaryCopyFromStoreToFile(ctx context.Context, from, to string,
 	// (which may already exist or not).
 
 	// copy from store to FS.
-	buf, err := s.binaryGet(ctx, from)
+	buf, err := s.binaryGet(accesslog.WithAction(ctx, "copy to file"), from)
 	if err != nil {
 		return fmt.Errorf("failed to read data from %q: %w", from, err)
 	}
@@ -34,6 +34,10 @@ func (s *Action) Clone(c *cli.Context) error {
 		ctx = backend.WithStorageBackendString(ctx, c.String("storage"))
 	}
 
+	if c.Bool("require-signed") {
+		ctx = backend.WithRequireSignedCommits(ctx, true)
+	}
+
 	path := c.String("path")
 
 	if c.Args().Len() < 1 {
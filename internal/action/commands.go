@@ -67,6 +67,52 @@ func ShowFlags() []cli.Flag {
 	}
 }
 
+// generatorFlags returns the flags that select and configure the password
+// generator. They are shared by all commands that generate passwords.
+func generatorFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "symbols",
+			Aliases: []string{"s"},
+			Usage:   "Use symbols in the password",
+		},
+		&cli.StringFlag{
+			Name:    "generator",
+			Aliases: []string{"g"},
+			Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd, mask or external. Default: cryptic",
+		},
+		&cli.StringFlag{
+			Name:  "mask",
+			Usage: "Mask for the mask generator, e.g. '?u?l?l?l-?d?d?d?d'. Implies --generator mask",
+		},
+		&cli.StringSliceFlag{
+			Name:  "mask-class",
+			Usage: "Define the custom character classes ?1 to ?9 for the mask generator, e.g. '1=abc'",
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Require strict character class rules",
+		},
+		&cli.StringFlag{
+			Name:    "sep",
+			Aliases: []string{"xkcdsep", "xs"},
+			Usage:   "Word separator for generated passwords. If no separator is specified, the words are combined without spaces/separator and the first character of words is capitalised.",
+			Value:   "",
+		},
+		&cli.StringFlag{
+			Name:    "lang",
+			Aliases: []string{"xkcdlang", "xl"},
+			Usage:   "Language to generate password from, currently only en (english, default) or de are supported",
+			Value:   "en",
+		},
+		&cli.StringFlag{
+			Name:    "wordlist",
+			Aliases: []string{"xkcdwordlist", "xw"},
+			Usage:   "Wordlist file or secret to draw words from, one word per line or in the EFF diceware format",
+		},
+	}
+}
+
 // GetCommands returns the cli commands exported by this module.
 func (s *Action) GetCommands() []*cli.Command {
 	cmds := []*cli.Command{
@@ -109,6 +155,26 @@ func (s *Action) GetCommands() []*cli.Command {
 					Usage: "Print a summary of the audit results. Default: true (print summary)",
 					Value: true,
 				},
+				&cli.BoolFlag{
+					Name:  "write-baseline",
+					Usage: "Acknowledge all current findings and store them in the encrypted audit baseline",
+				},
+				&cli.BoolFlag{
+					Name:  "ignore-baseline",
+					Usage: "Report all findings, including those acknowledged in the audit baseline",
+				},
+				&cli.StringFlag{
+					Name:  "justification",
+					Usage: "Justification recorded for acknowledged findings. Used with --write-baseline",
+				},
+				&cli.StringFlag{
+					Name:  "expires",
+					Usage: "Acknowledged findings expire after this duration, e.g. 90d. Used with --write-baseline",
+				},
+				&cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "Only audit secrets with this tag. Can be given multiple times",
+				},
 			},
 		},
 		{
@@ -155,6 +221,10 @@ func (s *Action) GetCommands() []*cli.Command {
 					Usage: "Check for valid decryption keys. Generate new keys if none are found.",
 					Value: true,
 				},
+				&cli.BoolFlag{
+					Name:  "require-signed",
+					Usage: "Only accept commits signed by the signers of the store (gitfs only)",
+				},
 			},
 		},
 		{
@@ -200,6 +270,18 @@ func (s *Action) GetCommands() []*cli.Command {
 					Name:  "storage",
 					Usage: fmt.Sprintf("Which storage backend? %v", backend.StorageRegistry.BackendNames()),
 				},
+				&cli.BoolFlag{
+					Name:  "dual",
+					Usage: "Encrypt all secrets with the old and the new crypto backend until the migration is finished",
+				},
+				&cli.BoolFlag{
+					Name:  "finish",
+					Usage: "Finish a dual-backend migration and remove the old ciphertext",
+				},
+				&cli.BoolFlag{
+					Name:  "status",
+					Usage: "Show the progress of a dual-backend migration",
+				},
 			},
 		},
 		{
@@ -244,6 +326,20 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"f"},
 					Usage:   "Force path selection",
 				},
+				&cli.StringFlag{
+					Name:    "template",
+					Aliases: []string{"t"},
+					Usage:   "Use the template with this name or prefix without asking any questions",
+				},
+				&cli.StringSliceFlag{
+					Name:  "set",
+					Usage: "Set an attribute of the template, e.g. --set username=john",
+				},
+				&cli.BoolFlag{
+					Name:    "print",
+					Aliases: []string{"p"},
+					Usage:   "Print the generated password instead of copying it to the clipboard",
+				},
 			},
 		},
 		{
@@ -336,6 +432,10 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"u", "force", "f"},
 					Usage:   "In the case of an exact match, display the password even if safecontent is enabled",
 				},
+				&cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "Only search secrets with this tag. Can be given multiple times",
+				},
 			},
 		},
 		{
@@ -399,7 +499,7 @@ func (s *Action) GetCommands() []*cli.Command {
 			Before:       s.IsInitialized,
 			Action:       s.Generate,
 			BashComplete: s.CompleteGenerate,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.BoolFlag{
 					Name:    "clip",
 					Aliases: []string{"c"},
@@ -420,38 +520,12 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"e"},
 					Usage:   "Open secret for editing after generating a password",
 				},
-				&cli.BoolFlag{
-					Name:    "symbols",
-					Aliases: []string{"s"},
-					Usage:   "Use symbols in the password",
-				},
-				&cli.StringFlag{
-					Name:    "generator",
-					Aliases: []string{"g"},
-					Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd or external. Default: cryptic",
-				},
-				&cli.BoolFlag{
-					Name:  "strict",
-					Usage: "Require strict character class rules",
-				},
 				&cli.BoolFlag{
 					Name:    "force-regen",
 					Aliases: []string{"t"},
 					Usage:   "Force full re-generation, incl. evaluation of templates. Will overwrite the entire secret!",
 				},
-				&cli.StringFlag{
-					Name:    "sep",
-					Aliases: []string{"xkcdsep", "xs"},
-					Usage:   "Word separator for generated passwords. If no separator is specified, the words are combined without spaces/separator and the first character of words is capitalised.",
-					Value:   "",
-				},
-				&cli.StringFlag{
-					Name:    "lang",
-					Aliases: []string{"xkcdlang", "xl"},
-					Usage:   "Language to generate password from, currently only en (english, default) or de are supported",
-					Value:   "en",
-				},
-			},
+			}, generatorFlags()...),
 		},
 		{
 			Name:      "grep",
@@ -468,6 +542,11 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"r"},
 					Usage:   "Interpret pattern as RE2 regular expression",
 				},
+				&cli.BoolFlag{
+					Name:    "unsafe",
+					Aliases: []string{"u", "force", "f"},
+					Usage:   "Also search the values of sensitive keys",
+				},
 			},
 		},
 		{
@@ -486,6 +565,15 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"p"},
 					Usage:   "Include passwords in output",
 				},
+				&cli.BoolFlag{
+					Name:    "diff",
+					Aliases: []string{"d"},
+					Usage:   "Show the changes of each revision",
+				},
+				&cli.BoolFlag{
+					Name:  "unsafe",
+					Usage: "Do not mask passwords in diffs",
+				},
 			},
 		},
 		{
@@ -597,6 +685,40 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"s"},
 					Usage:   "Strip this prefix from filtered entries",
 				},
+				&cli.BoolFlag{
+					Name:  "pending",
+					Usage: "Print a flat list of secrets with a pending password rotation. Needs to decrypt all secrets",
+				},
+				&cli.BoolFlag{
+					Name:  "worklist",
+					Usage: "Print a flat list of secrets that need to be rotated after offboarding a recipient",
+				},
+				&cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "Print a flat list of secrets with this tag. Can be given multiple times, secrets must have all tags",
+				},
+			},
+		},
+		{
+			Name:  "log",
+			Usage: "Show the local access log",
+			Description: "" +
+				"This command shows when secrets were decrypted or copied to the clipboard " +
+				"and by which command. The access log must be enabled with core.accesslog. " +
+				"It is kept in the local data directory and is never synced. Each entry is " +
+				"encrypted to you and linked to the previous one, so removed or modified " +
+				"entries are detected.",
+			Before: s.IsInitialized,
+			Action: s.AccessLog,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "since",
+					Usage: "Only show entries newer than this duration (e.g. 12h or 7d) or date",
+				},
+				&cli.StringFlag{
+					Name:  "secret",
+					Usage: "Only show entries for this secret or folder",
+				},
 			},
 		},
 		{
@@ -645,6 +767,10 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"f"},
 					Usage:   "Force to move the secret and overwrite existing one",
 				},
+				&cli.BoolFlag{
+					Name:  "refs",
+					Usage: "Search for references to the moved secrets and offer to update them. Searching needs to decrypt all secrets in the mount",
+				},
 			},
 		},
 		{
@@ -742,6 +868,13 @@ func (s *Action) GetCommands() []*cli.Command {
 				"and replace all variables with their values.",
 			Before: s.IsInitialized,
 			Action: s.Process,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "unsafe",
+					Aliases: []string{"u", "force", "f"},
+					Usage:   "Do not mask sensitive keys in secrets inserted with get",
+				},
+			},
 		},
 		{
 			Name:      "rcs",
@@ -828,6 +961,29 @@ func (s *Action) GetCommands() []*cli.Command {
 						},
 					},
 				},
+				{
+					Name:  "check",
+					Usage: "Check the keys of all recipients",
+					Description: "" +
+						"This command checks the keys of all recipients of all stores, or the " +
+						"given one, and reports missing, expired or revoked keys as well as keys " +
+						"that will expire within the configured warning window " +
+						"(recipients.expiry-warning). It exits with a non-zero status if any " +
+						"problem is found, so it can be used in CI. With --refresh it first " +
+						"imports updated public keys from the store.",
+					Before: s.IsInitialized,
+					Action: s.RecipientsCheck,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+						&cli.BoolFlag{
+							Name:  "refresh",
+							Usage: "Import updated public keys from the store before checking",
+						},
+					},
+				},
 				{
 					Name:    "add",
 					Aliases: []string{"authorize"},
@@ -837,7 +993,8 @@ func (s *Action) GetCommands() []*cli.Command {
 						"If none are given it will display a list of usable public keys. " +
 						"After adding the recipient to the list it will re-encrypt the whole " +
 						"affected store to make sure the recipient has access to all existing " +
-						"secrets.",
+						"secrets. With --group the recipient is added to a recipient group " +
+						"instead and only the secrets using this group are re-encrypted.",
 					Before: s.IsInitialized,
 					Action: s.RecipientsAdd,
 					Flags: []cli.Flag{
@@ -849,6 +1006,10 @@ func (s *Action) GetCommands() []*cli.Command {
 							Name:  "force",
 							Usage: "Force adding non-existing keys",
 						},
+						&cli.StringFlag{
+							Name:  "group",
+							Usage: "Add the recipients to this group, e.g. sre",
+						},
 					},
 				},
 				{
@@ -863,7 +1024,8 @@ func (s *Action) GetCommands() []*cli.Command {
 						"all existing secrets. Please note that the removed recipients will still " +
 						"be able to decrypt old revisions of the password store and any local " +
 						"copies they might have. The only way to reliably remove a recipient is to " +
-						"rotate all existing secrets.",
+						"rotate all existing secrets. Use --offboard to find all secrets the recipient " +
+						"could ever decrypt and put them on a rotation worklist.",
 					Before:       s.IsInitialized,
 					Action:       s.RecipientsRemove,
 					BashComplete: s.RecipientsComplete,
@@ -876,6 +1038,18 @@ func (s *Action) GetCommands() []*cli.Command {
 							Name:  "force",
 							Usage: "Force adding non-existing keys",
 						},
+						&cli.StringFlag{
+							Name:  "group",
+							Usage: "Remove the recipients from this group, e.g. sre",
+						},
+						&cli.BoolFlag{
+							Name:  "offboard",
+							Usage: "Write a worklist of all secrets the recipients could ever decrypt and need to be rotated",
+						},
+						&cli.BoolFlag{
+							Name:  "regenerate",
+							Usage: "Start the rotation of all exposed passwords that were generated by gopass (with --offboard)",
+						},
 					},
 				},
 			},
@@ -919,6 +1093,63 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "restore",
+			Usage:     "Restore an older revision of a secret",
+			ArgsUsage: "[secret]",
+			Description: "" +
+				"This command writes a past revision of a secret as a new revision. The secret " +
+				"is re-encrypted to the current recipients. Without --revision it restores a " +
+				"deleted secret. Revisions can be given as a hash or relative to the latest " +
+				"one, e.g. -1 for the previous revision.",
+			Before:       s.IsInitialized,
+			Action:       s.Restore,
+			BashComplete: s.Complete,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "revision",
+					Aliases: []string{"r"},
+					Usage:   "Revision to restore (hash or -N)",
+				},
+			},
+		},
+		{
+			Name:      "rotate",
+			Usage:     "Rotate the password of a secret",
+			ArgsUsage: "[secret] [length]",
+			Description: "" +
+				"This command generates a new password for an existing secret, following the " +
+				"password rules of its domain if known. The old password is kept in the " +
+				"'previous' field until the rotation is confirmed with --confirm or reverted " +
+				"with --rollback. If a password change URL is known it is printed or opened.",
+			Before:       s.IsInitialized,
+			Action:       s.Rotate,
+			BashComplete: s.Complete,
+			Flags: append([]cli.Flag{
+				&cli.BoolFlag{
+					Name:  "confirm",
+					Usage: "Finish a pending rotation and drop the previous password",
+				},
+				&cli.BoolFlag{
+					Name:  "rollback",
+					Usage: "Abort a pending rotation and restore the previous password",
+				},
+				&cli.BoolFlag{
+					Name:  "open",
+					Usage: "Open the password change URL in the default browser",
+				},
+				&cli.BoolFlag{
+					Name:    "clip",
+					Aliases: []string{"c"},
+					Usage:   "Copy the generated password to the clipboard",
+				},
+				&cli.BoolFlag{
+					Name:    "print",
+					Aliases: []string{"p"},
+					Usage:   "Print the generated password to the terminal",
+				},
+			}, generatorFlags()...),
+		},
 		{
 			Name:      "show",
 			Usage:     "Display the content of a secret",
@@ -960,6 +1191,62 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:  "tag",
+			Usage: "Manage the tags of secrets",
+			Description: "" +
+				"Tags are stored in the reserved 'tags' key of a secret. Each store keeps an " +
+				"encrypted index of all tags so 'gopass list --tag', 'find --tag' and " +
+				"'audit --tag' don't need to decrypt every secret.",
+			Before: s.IsInitialized,
+			Action: s.TagList,
+			Subcommands: []*cli.Command{
+				{
+					Name:         "add",
+					Usage:        "Add tags to a secret",
+					ArgsUsage:    "<secret> <tag> [<tag>...]",
+					Description:  "Add one or more tags to a secret. The rest of the secret is left untouched.",
+					Before:       s.IsInitialized,
+					Action:       s.TagAdd,
+					BashComplete: s.Complete,
+				},
+				{
+					Name:         "remove",
+					Aliases:      []string{"rm"},
+					Usage:        "Remove tags from a secret",
+					ArgsUsage:    "<secret> <tag> [<tag>...]",
+					Description:  "Remove one or more tags from a secret. The rest of the secret is left untouched.",
+					Before:       s.IsInitialized,
+					Action:       s.TagRemove,
+					BashComplete: s.Complete,
+				},
+				{
+					Name:         "list",
+					Aliases:      []string{"ls"},
+					Usage:        "List tags",
+					ArgsUsage:    "[secret]",
+					Description:  "List the tags of a secret or all tags with the number of secrets that have them.",
+					Before:       s.IsInitialized,
+					Action:       s.TagList,
+					BashComplete: s.Complete,
+				},
+				{
+					Name:  "rebuild",
+					Usage: "Rebuild the tag index",
+					Description: "" +
+						"Decrypt all secrets and recreate the tag index. Use this when secrets " +
+						"were changed without gopass, e.g. after a manual merge.",
+					Before: s.IsInitialized,
+					Action: s.TagRebuild,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Only rebuild the index of this mount point",
+						},
+					},
+				},
+			},
+		},
 		{
 			Name:  "templates",
 			Usage: "Edit templates",
@@ -998,6 +1285,16 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "trash",
+			Usage:     "List deleted secrets",
+			ArgsUsage: "[prefix]",
+			Description: "" +
+				"This command lists secrets that were deleted in the history of the stores, " +
+				"together with who deleted them and when. Use 'gopass undelete' to restore them.",
+			Before: s.IsInitialized,
+			Action: s.Trash,
+		},
 		{
 			Name:        "unclip",
 			Usage:       "Internal command to clear clipboard",
@@ -1015,6 +1312,16 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "undelete",
+			Usage:     "Restore a deleted secret",
+			ArgsUsage: "[secret]",
+			Description: "" +
+				"This command restores a deleted secret from the last revision before it was " +
+				"deleted. The secret is re-encrypted to the current recipients.",
+			Before: s.IsInitialized,
+			Action: s.Undelete,
+		},
 		{
 			Name:  "update",
 			Usage: "Check for updates",
@@ -1064,6 +1371,14 @@ func (s *Action) GetCommands() []*cli.Command {
 		cmds = append(cmds, nc...)
 	}
 
+	// the merge and textconv drivers need to decrypt secrets so they can not
+	// be provided by the gitfs backend itself.
+	for _, cmd := range cmds {
+		if cmd.Name == "git" {
+			cmd.Subcommands = append(cmd.Subcommands, s.gitMergeDriverCommand(), s.gitTextconvCommand())
+		}
+	}
+
 	return cmds
 }
 
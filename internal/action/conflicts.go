@@ -0,0 +1,115 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/cui"
+	"github.com/gopasspw/gopass/internal/editor"
+	"github.com/gopasspw/gopass/internal/merge"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+)
+
+// conflictSuffix is appended to the name of a secret to keep their version
+// next to ours.
+const conflictSuffix = ".conflict"
+
+// canResolveConflicts returns true if the user can resolve merge conflicts
+// interactively. This is only offered by gopass sync, not by autosync.
+func canResolveConflicts(ctx context.Context) bool {
+	return GetEditor(ctx) != "" && ctxutil.IsInteractive(ctx) && ctxutil.IsTerminal(ctx) && !ctxutil.IsAlwaysYes(ctx)
+}
+
+// syncResolveConflicts asks the user how to resolve each conflicting secret
+// and commits the result. This concludes the merge that was stopped by the
+// conflicts.
+func (s *Action) syncResolveConflicts(ctx context.Context, sub *leaf.Store) error {
+	conflicts, err := sub.Conflicts(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		out.Printf(ctx, "\n%s was changed locally and on the remote:\n", c.Name)
+		fmt.Fprintln(stdout, cui.SideBySide("mine", "theirs", conflictLines(c.Ours, c.Theirs), conflictLines(c.Theirs, c.Ours)))
+		fmt.Fprintln(stdout)
+
+		if err := s.resolveConflict(ctx, sub, c); err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", c.Name, err)
+		}
+		names = append(names, c.Name)
+	}
+
+	// the storage concludes a stopped merge even if our side was kept. There
+	// is nothing to commit if the merge was already concluded.
+	if err := sub.Storage().Commit(ctx, fmt.Sprintf("Resolved conflicts in %s", strings.Join(names, ", "))); err != nil && !errors.Is(err, store.ErrGitNothingToCommit) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Action) resolveConflict(ctx context.Context, sub *leaf.Store, c leaf.Conflict) error {
+	choices := []string{
+		"Keep mine",
+		"Keep theirs",
+		"Merge in editor",
+		fmt.Sprintf("Keep both (save theirs as %s%s)", c.Name, conflictSuffix),
+	}
+
+	act, sel := cui.GetSelection(ctx, "How do you want to resolve "+c.Name+"?", choices)
+	if act != "default" {
+		return fmt.Errorf("user aborted")
+	}
+
+	switch sel {
+	case 0:
+		return sub.ResolveConflict(ctx, c.Name, c.Ours)
+	case 1:
+		return sub.ResolveConflict(ctx, c.Name, c.Theirs)
+	case 2:
+		merged, _ := merge.Secrets(c.Base, c.Ours, c.Theirs)
+		content, err := editor.Invoke(ctx, GetEditor(ctx), merged)
+		if err != nil {
+			return err
+		}
+
+		return sub.ResolveConflict(ctx, c.Name, content)
+	default:
+		if err := sub.ResolveConflict(ctx, c.Name, c.Ours); err != nil {
+			return err
+		}
+		if c.Theirs == nil {
+			return nil
+		}
+
+		return sub.ResolveConflict(ctx, c.Name+conflictSuffix, c.Theirs)
+	}
+}
+
+// conflictLines returns the lines of one version of a secret with the password
+// masked. The password is marked if it differs from the other version.
+func conflictLines(content, other []byte) []string {
+	if content == nil {
+		return []string{"(deleted)"}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if lines[0] == "" {
+		return lines
+	}
+
+	if pw, _, _ := strings.Cut(string(other), "\n"); pw != lines[0] {
+		lines[0] = "***** (differs)"
+	} else {
+		lines[0] = "*****"
+	}
+
+	return lines
+}
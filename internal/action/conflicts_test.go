@@ -0,0 +1,158 @@
+package action
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/storage/gitfs"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncResolveConflicts(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	cfg := config.NewInMemory()
+	require.NoError(t, cfg.SetPath(u.StoreDir("")))
+
+	act, err := newAction(cfg, semver.Version{}, false)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	require.NoError(t, act.IsInitialized(gptest.CliCtx(ctx, t)))
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	require.NoError(t, exec.Command("git", "init", "--bare", remote).Run())
+	require.NoError(t, act.Store.RCSAddRemote(ctx, "", "origin", "file://"+remote))
+
+	sub, err := act.Store.GetSubStore("")
+	require.NoError(t, err)
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("base")
+	require.NoError(t, sec.Set("user", "base"))
+	require.NoError(t, act.Store.Set(ctx, "foo", sec))
+	require.NoError(t, sub.Storage().Push(ctx, "", ""))
+
+	// someone else changes the secret and pushes.
+	other, err := gitfs.Clone(ctx, "file://"+remote, filepath.Join(t.TempDir(), "other"), "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+	require.NoError(t, other.Set(ctx, "foo.txt", []byte("theirs\nuser: theirs\n")))
+	require.NoError(t, other.Add(ctx, "foo.txt"))
+	require.NoError(t, other.Commit(ctx, "changed foo"))
+	require.NoError(t, other.Push(ctx, "", ""))
+
+	require.NoError(t, sub.Storage().Set(ctx, "foo.txt", []byte("mine\nuser: base\n")))
+	require.NoError(t, sub.Storage().Add(ctx, "foo.txt"))
+	require.NoError(t, sub.Storage().Commit(ctx, "changed foo"))
+
+	ctx = ctxutil.WithAlwaysYes(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, true)
+	ctx = WithEditor(ctx, "true")
+	buf.Reset()
+
+	t.Run("conflict without resolver", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.Error(t, act.syncMount(WithEditor(ctx, ""), ""))
+		assert.Contains(t, buf.String(), "resolve the conflicts")
+	})
+
+	t.Run("keep both", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("3\n")
+		defer func() {
+			termio.Stdin = os.Stdin
+		}()
+
+		require.NoError(t, act.syncMount(ctx, ""))
+		assert.Contains(t, buf.String(), "***** (differs)")
+		assert.NotContains(t, buf.String(), "mine\n")
+
+		mine, err := sub.Storage().Get(ctx, "foo.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "mine\nuser: base\n", string(mine))
+
+		theirs, err := sub.Storage().Get(ctx, "foo.conflict.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "theirs\nuser: theirs\n", string(theirs))
+
+		require.NoError(t, other.Pull(ctx, "", ""))
+		assert.True(t, other.Exists(ctx, "foo.conflict.txt"))
+	})
+
+	t.Run("keep mine after they deleted it", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.NoError(t, other.Delete(ctx, "foo.txt"))
+		require.NoError(t, other.Add(ctx, "foo.txt"))
+		require.NoError(t, other.Commit(ctx, "removed foo"))
+		require.NoError(t, other.Push(ctx, "", ""))
+
+		require.NoError(t, sub.Storage().Set(ctx, "foo.txt", []byte("mine again\nuser: base\n")))
+		require.NoError(t, sub.Storage().Add(ctx, "foo.txt"))
+		require.NoError(t, sub.Storage().Commit(ctx, "changed foo again"))
+
+		// keeping our version leaves nothing to commit, but the merge must
+		// still be concluded.
+		termio.Stdin = strings.NewReader("0\n")
+		defer func() {
+			termio.Stdin = os.Stdin
+		}()
+
+		require.NoError(t, act.syncMount(ctx, ""))
+		assert.NoFileExists(t, filepath.Join(u.StoreDir(""), ".git", "MERGE_HEAD"))
+
+		mine, err := sub.Storage().Get(ctx, "foo.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "mine again\nuser: base\n", string(mine))
+
+		require.NoError(t, other.Pull(ctx, "", ""))
+		assert.True(t, other.Exists(ctx, "foo.txt"))
+	})
+}
+
+func TestConflictLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"(deleted)"}, conflictLines(nil, []byte("foo")))
+	assert.Equal(t, []string{"*****", "user: foo"}, conflictLines([]byte("foo\nuser: foo\n"), []byte("foo\nuser: bar")))
+	assert.Equal(t, []string{"***** (differs)"}, conflictLines([]byte("foo"), []byte("bar")))
+	assert.Equal(t, []string{"", "body"}, conflictLines([]byte("\nbody"), nil))
+}
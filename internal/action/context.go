@@ -14,6 +14,8 @@ const (
 	ctxKeyAlsoClip
 	ctxKeyPrintChars
 	ctxKeyWithQRBody
+	ctxKeyEditor
+	ctxKeyTags
 )
 
 // WithClip returns a context with the value for clip (for copy to clipboard)
@@ -167,3 +169,35 @@ func IsQRBody(ctx context.Context) bool {
 
 	return bv
 }
+
+// WithEditor returns a context with the editor that is used to resolve merge
+// conflicts during sync.
+func WithEditor(ctx context.Context, ed string) context.Context {
+	return context.WithValue(ctx, ctxKeyEditor, ed)
+}
+
+// GetEditor returns the editor set in this context or an empty string.
+func GetEditor(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyEditor).(string)
+	if !ok {
+		return ""
+	}
+
+	return sv
+}
+
+// WithTags returns a context with the tags that secrets must have to be
+// listed or searched.
+func WithTags(ctx context.Context, tags []string) context.Context {
+	return context.WithValue(ctx, ctxKeyTags, tags)
+}
+
+// GetTags returns the tags set in this context or nil.
+func GetTags(ctx context.Context) []string {
+	sv, ok := ctx.Value(ctxKeyTags).([]string)
+	if !ok {
+		return nil
+	}
+
+	return sv
+}
@@ -1,11 +1,15 @@
 package action
 
 import (
+	"context"
+	"strings"
+
 	"github.com/gopasspw/gopass/internal/action/exit"
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/backend/crypto/age"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/termio"
@@ -28,6 +32,17 @@ func (s *Action) Convert(c *cli.Context) error {
 	// we know it's a valid mount at this point
 	ctx = config.WithMount(ctx, store)
 
+	switch {
+	case c.Bool("status"):
+		return s.convertStatus(ctx, store, sub)
+	case c.Bool("finish"):
+		return s.convertFinish(ctx, store, sub)
+	}
+
+	if to := sub.Migrating(); to != "" && !c.Bool("dual") {
+		return exit.Error(exit.Usage, nil, "store %q is being migrated to %s. Use --finish to complete the migration", store, to)
+	}
+
 	oldStorage := sub.Storage().Name()
 
 	storage, err := backend.StorageRegistry.Backend(oldStorage)
@@ -64,6 +79,14 @@ func (s *Action) Convert(c *cli.Context) error {
 		return nil
 	}
 
+	if c.Bool("dual") {
+		if oldStorage != storage.String() {
+			return exit.Error(exit.Usage, nil, "can not change the storage backend in a dual-backend migration")
+		}
+
+		return s.convertDual(ctx, store, sub, crypto)
+	}
+
 	if oldCrypto != crypto.String() {
 		debug.Log("attempting to convert crypto from %q to %q", oldCrypto, crypto.String())
 
@@ -97,3 +120,90 @@ func (s *Action) Convert(c *cli.Context) error {
 
 	return nil
 }
+
+// convertDual starts a staged migration to another crypto backend. Until
+// it is finished every secret is encrypted with both backends.
+func (s *Action) convertDual(ctx context.Context, store string, sub *leaf.Store, crypto backend.CryptoBackend) error {
+	oldCrypto := sub.Crypto().Name()
+	if to := sub.Migrating(); to != "" && to != crypto.String() {
+		return exit.Error(exit.Usage, nil, "store %q is already being migrated to %s", store, to)
+	}
+
+	cbe, err := backend.NewCrypto(ctx, crypto)
+	if err != nil {
+		return err
+	}
+	if err := s.initCheckPrivateKeys(ctx, cbe); err != nil {
+		return err
+	}
+
+	out.Noticef(ctx, "Encrypting %q for %q in addition to %q", store, crypto, oldCrypto)
+	ok, err := termio.AskForBool(ctx, "Continue?", false)
+	if err != nil {
+		return err
+	}
+	if ctxutil.IsInteractive(ctx) && !ok {
+		out.Notice(ctx, "Aborted")
+
+		return nil
+	}
+
+	if err := sub.StartMigration(ctx, crypto); err != nil {
+		return exit.Error(exit.Unknown, err, "failed to start migration of %q: %s", store, err)
+	}
+
+	out.OKf(ctx, "Store %q is now encrypted for %q and %q. Run `gopass convert --store %q --finish` once all recipients have switched.", store, oldCrypto, crypto, store)
+
+	return nil
+}
+
+// convertStatus prints the progress of a staged migration.
+func (s *Action) convertStatus(ctx context.Context, store string, sub *leaf.Store) error {
+	if sub.Migrating() == "" {
+		out.Noticef(ctx, "Store %q is not being migrated. It uses %q.", store, sub.Crypto().Name())
+
+		return nil
+	}
+
+	ms, err := sub.MigrationStatus(ctx)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "failed to get migration status of %q: %s", store, err)
+	}
+
+	out.Printf(ctx, "Store %q is being migrated from %q to %q", store, ms.From, ms.To)
+	out.Printf(ctx, "Secrets: %d of %d migrated", ms.Migrated, ms.Secrets)
+	out.Printf(ctx, "%s recipients: %s", ms.From, strings.Join(ms.FromRecipients, ", "))
+	out.Printf(ctx, "%s recipients: %s", ms.To, strings.Join(ms.ToRecipients, ", "))
+	for _, name := range ms.Missing {
+		out.Warningf(ctx, "%s is not encrypted for %s", name, ms.To)
+	}
+
+	return nil
+}
+
+// convertFinish completes a staged migration by removing the old ciphertext.
+func (s *Action) convertFinish(ctx context.Context, store string, sub *leaf.Store) error {
+	to := sub.Migrating()
+	if to == "" {
+		return exit.Error(exit.Usage, nil, "store %q is not being migrated", store)
+	}
+
+	out.Noticef(ctx, "Removing all %q secrets and recipients from %q. Only %q will be used afterwards.", sub.Crypto().Name(), store, to)
+	ok, err := termio.AskForBool(ctx, "Continue?", false)
+	if err != nil {
+		return err
+	}
+	if ctxutil.IsInteractive(ctx) && !ok {
+		out.Notice(ctx, "Aborted")
+
+		return nil
+	}
+
+	if err := sub.FinishMigration(ctx); err != nil {
+		return exit.Error(exit.Unknown, err, "failed to finish migration of %q: %s", store, err)
+	}
+
+	out.OKf(ctx, "Successfully migrated %q to %q", store, to)
+
+	return nil
+}
@@ -3,6 +3,7 @@ package action
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gopasspw/gopass/internal/action/exit"
 	"github.com/gopasspw/gopass/internal/config"
@@ -33,6 +34,15 @@ func (s *Action) Create(c *cli.Context) error {
 	if len(acts) < 1 {
 		return exit.Error(exit.Unknown, nil, "no wizard actions available")
 	}
+	// select the template by name, e.g. for scripts.
+	if name := c.String("template"); name != "" {
+		i, found := wiz.Lookup(name)
+		if !found {
+			return exit.Error(exit.NotFound, nil, "template %q not found. Available: %s", name, strings.Join(acts.Selection(), ", "))
+		}
+
+		return acts.Run(ctx, c, i)
+	}
 	// no need to ask if there is only one action available.
 	if len(acts) == 1 {
 		return acts.Run(ctx, c, 0)
@@ -56,6 +66,7 @@ func (s *Action) createPrintOrCopy(ctx context.Context, c *cli.Context, name, pa
 	}
 
 	if c.Bool("print") {
+		s.Store.LogAccess(ctx, name, "print")
 		fmt.Fprintf(out.Stdout, "The generated password for %s is:\n%s\n", name, password)
 
 		return nil
@@ -64,6 +75,7 @@ func (s *Action) createPrintOrCopy(ctx context.Context, c *cli.Context, name, pa
 	if err := clipboard.CopyTo(ctx, name, []byte(password), config.Int(ctx, "core.cliptimeout")); err != nil {
 		return exit.Error(exit.IO, err, "failed to copy to clipboard: %s", err)
 	}
+	s.Store.LogAccess(ctx, name, "copy to clipboard")
 
 	return hook.InvokeRoot(ctx, "create.post-hook", name, s.Store)
 }
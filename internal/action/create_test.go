@@ -2,6 +2,7 @@ package action
 
 import (
 	"bytes"
+	"flag"
 	"os"
 	"testing"
 
@@ -10,7 +11,9 @@ import (
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 )
 
 func TestCreate(t *testing.T) {
@@ -41,3 +44,87 @@ func TestCreate(t *testing.T) {
 	require.Error(t, act.Create(c))
 	buf.Reset()
 }
+
+func TestCreateTemplate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	aclip.Unsupported = true
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	cliCtx := func(t *testing.T, args ...string) *cli.Context {
+		t.Helper()
+
+		fs := flag.NewFlagSet("default", flag.ContinueOnError)
+		for _, f := range []cli.Flag{
+			&cli.StringFlag{Name: "template"},
+			&cli.StringSliceFlag{Name: "set"},
+			&cli.BoolFlag{Name: "print"},
+			&cli.BoolFlag{Name: "force"},
+		} {
+			require.NoError(t, f.Apply(fs))
+		}
+		require.NoError(t, fs.Parse(args))
+		c := cli.NewContext(cli.NewApp(), fs, nil)
+		c.Context = ctx
+
+		return c
+	}
+
+	t.Run("unknown template", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Create(cliCtx(t, "--template=foo")))
+	})
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Create(cliCtx(t, "--template=websites", "--set=foo=bar")))
+	})
+
+	t.Run("missing required attribute", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Create(cliCtx(t, "--template=websites", "--set=url=example.org")))
+	})
+
+	t.Run("website", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Create(cliCtx(t, "--template=Website login", "--set=url=example.org", "--set=username=john", "--print")))
+		assert.Contains(t, buf.String(), "The generated password for websites/example.org/john is:")
+
+		sec, err := act.Store.Get(ctx, "websites/example.org/john")
+		require.NoError(t, err)
+		assert.NotEmpty(t, sec.Password())
+		un, _ := sec.Get("username")
+		assert.Equal(t, "john", un)
+	})
+
+	t.Run("existing secret", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Create(cliCtx(t, "--template=websites", "--set=url=example.org", "--set=username=john", "--set=password=foo")))
+		require.NoError(t, act.Create(cliCtx(t, "--template=websites", "--set=url=example.org", "--set=username=john", "--set=password=foo", "--force")))
+
+		sec, err := act.Store.Get(ctx, "websites/example.org/john")
+		require.NoError(t, err)
+		assert.Equal(t, "foo", sec.Password())
+	})
+
+	t.Run("default username", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.cfg.Set("", "create.default-username", "jane"))
+		require.NoError(t, act.Create(cliCtx(t, "--template=websites", "--set=url=example.com", "--set=password=bar")))
+		assert.True(t, act.Store.Exists(ctx, "websites/example.com/jane"))
+	})
+}
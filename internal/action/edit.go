@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/action/exit"
 	"github.com/gopasspw/gopass/internal/audit"
 	"github.com/gopasspw/gopass/internal/config"
@@ -98,7 +99,7 @@ func (s *Action) editGetContent(ctx context.Context, name string, create bool) (
 	// edit existing entry.
 	if s.Store.Exists(ctx, name) {
 		// we make sure we are not parsing the content of the file when editing.
-		sec, err := s.Store.Get(ctxutil.WithShowParsing(ctx, false), name)
+		sec, err := s.Store.Get(accesslog.WithAction(ctxutil.WithShowParsing(ctx, false), "edit"), name)
 		if err != nil {
 			return name, nil, false, exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
 		}
@@ -7,7 +7,9 @@ import (
 	"path"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/reference"
 	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -53,6 +55,7 @@ func (s *Action) Env(c *cli.Context) error {
 		keys = append(keys, name)
 	}
 
+	ctx = accesslog.WithAction(ctx, "export to environment")
 	env := make([]string, 0, 1)
 	for _, key := range keys {
 		debug.Log("exporting to environment key: %s", key)
@@ -60,6 +63,10 @@ func (s *Action) Env(c *cli.Context) error {
 		if err != nil {
 			return fmt.Errorf("failed to get entry for env prefix %q: %w", name, err)
 		}
+		sec, err = reference.Resolve(ctx, s.Store, key, sec)
+		if err != nil {
+			return fmt.Errorf("failed to resolve references in %q: %w", key, err)
+		}
 		envKey := path.Base(key)
 		if !keepCase {
 			envKey = strings.ToUpper(envKey)
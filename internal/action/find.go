@@ -37,7 +37,11 @@ func (s *Action) findCmd(c *cli.Context, cb showFunc, fuzzy bool) error {
 		ctx = ctxutil.WithForce(ctx, c.Bool("unsafe"))
 	}
 
-	if !c.Args().Present() {
+	if c.IsSet("tag") {
+		ctx = WithTags(ctx, c.StringSlice("tag"))
+	}
+
+	if !c.Args().Present() && len(GetTags(ctx)) < 1 {
 		return exit.Error(exit.Usage, nil, "Usage: %s find <pattern>", s.Name)
 	}
 
@@ -54,6 +58,14 @@ func (s *Action) find(ctx context.Context, c *cli.Context, needle string, cb sho
 		return exit.Error(exit.List, err, "failed to list store: %s", err)
 	}
 
+	// only search secrets with the given tags.
+	if tags := GetTags(ctx); len(tags) > 0 {
+		haystack, err = s.Store.Tagged(ctx, tags...)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to read tag index: %s", err)
+		}
+	}
+
 	// filter our the ones from the haystack matching the needle.
 	needle = strings.ToLower(needle)
 	choices := filter(haystack, needle)
@@ -58,6 +58,13 @@ func (s *Action) Fsck(c *cli.Context) error {
 	}
 	bar.Done()
 
+	// references can only be checked if the secrets are decrypted anyway.
+	if leaf.IsFsckDecrypt(ctx) {
+		if err := s.fsckRefs(ctx, pwList); err != nil {
+			return exit.Error(exit.Fsck, err, "fsck found errors: %s", err)
+		}
+	}
+
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"regexp"
 	"sort"
@@ -18,6 +19,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
 	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/pwgen"
@@ -122,6 +124,7 @@ func (s *Action) generateCopyOrPrint(ctx context.Context, c *cli.Context, name,
 		if err := clipboard.CopyTo(ctx, name, []byte(password), config.AsInt(s.cfg.Get("core.cliptimeout"))); err != nil {
 			return exit.Error(exit.IO, err, "failed to copy to clipboard: %s", err)
 		}
+		s.Store.LogAccess(ctx, name, "copy to clipboard")
 		// if autoclip is on and we're not printing the password to the terminal
 		// at least leave a notice that we did indeed copy it.
 		if config.AsBool(s.cfg.Get("generate.autoclip")) && !c.Bool("print") {
@@ -143,6 +146,7 @@ func (s *Action) generateCopyOrPrint(ctx context.Context, c *cli.Context, name,
 		return nil
 	}
 
+	s.Store.LogAccess(ctx, name, "print")
 	out.Printf(
 		ctx,
 		"⚠ The generated password is:\n\n%s\n",
@@ -184,10 +188,15 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 	generator := cfg.GetM(mp, "generate.generator")
 	if c.IsSet("generator") {
 		generator = c.String("generator")
+	} else if c.IsSet("mask") {
+		generator = "mask"
 	}
 
-	if generator == "xkcd" {
+	switch generator {
+	case "xkcd":
 		return s.generatePasswordXKCD(ctx, c, length)
+	case "mask":
+		return s.generatePasswordMask(ctx, c)
 	}
 
 	var pwlen int
@@ -286,6 +295,36 @@ func (s *Action) generatePasswordForRule(ctx context.Context, c *cli.Context, le
 	return pw, nil
 }
 
+// generatePasswordMask creates a password matching the mask given on the command line
+// or in the config.
+func (s *Action) generatePasswordMask(ctx context.Context, c *cli.Context) (string, error) {
+	mask := config.String(c.Context, "pwgen.mask")
+	if c.IsSet("mask") {
+		mask = c.String("mask")
+	}
+	if mask == "" {
+		return "", exit.Error(exit.Usage, nil, "the mask generator requires a mask. Use --mask or set pwgen.mask")
+	}
+
+	custom := make(map[rune]string, len(c.StringSlice("mask-class")))
+	for _, def := range c.StringSlice("mask-class") {
+		k, v, found := strings.Cut(def, "=")
+		if !found || len(k) != 1 || k[0] < '1' || k[0] > '9' || v == "" {
+			return "", exit.Error(exit.Usage, nil, "invalid custom character class %q. Use e.g. 1=abc", def)
+		}
+		custom[rune(k[0])] = v
+	}
+
+	m, err := pwgen.NewMask(mask, custom)
+	if err != nil {
+		return "", exit.Error(exit.Usage, err, "failed to parse mask %q: %s", mask, err)
+	}
+
+	out.Noticef(ctx, "Mask %q has an entropy of %.1f bits", mask, m.Entropy())
+
+	return m.Password(), nil
+}
+
 // generatePasswordXKCD walks through the steps necessary to create an XKCD-style
 // password.
 func (s *Action) generatePasswordXKCD(ctx context.Context, c *cli.Context, length string) (string, error) {
@@ -331,7 +370,52 @@ func (s *Action) generatePasswordXKCD(ctx context.Context, c *cli.Context, lengt
 		return "", exit.Error(exit.Usage, nil, "password length must not be zero")
 	}
 
-	return xkcdgen.RandomLengthDelim(pwlen, sep, lang, capitalize, num)
+	wordlist := config.String(c.Context, "pwgen.xkcd-wordlist")
+	if c.IsSet("wordlist") {
+		wordlist = c.String("wordlist")
+	}
+
+	if wordlist == "" {
+		if e, err := xkcdgen.LangEntropy(pwlen, lang); err == nil {
+			out.Noticef(ctx, "Passphrase entropy: %.1f bits", e)
+		}
+
+		return xkcdgen.RandomLengthDelim(pwlen, sep, lang, capitalize, num)
+	}
+
+	wl, err := s.loadWordlist(ctx, wordlist)
+	if err != nil {
+		return "", exit.Error(exit.Usage, err, "failed to load wordlist %q: %s", wordlist, err)
+	}
+
+	// without a separator the words of a passphrase must be unambiguous.
+	if err := wl.CheckSeparable(sep); err != nil {
+		return "", exit.Error(exit.Usage, err, "wordlist %q can not be used without a separator: %s", wordlist, err)
+	}
+
+	out.Noticef(ctx, "Passphrase entropy: %.1f bits (%d words from a list of %d)", wl.Entropy(pwlen), pwlen, len(wl))
+
+	return xkcdgen.RandomLengthDelimWordlist(pwlen, sep, wl, capitalize, num)
+}
+
+// loadWordlist reads a user supplied wordlist either from a file or,
+// if no such file exists, from a secret.
+func (s *Action) loadWordlist(ctx context.Context, src string) (xkcdgen.Wordlist, error) {
+	if fsutil.IsFile(src) {
+		buf, err := os.ReadFile(src)
+		if err != nil {
+			return nil, err
+		}
+
+		return xkcdgen.ParseWordlist(buf)
+	}
+
+	sec, err := s.Store.Get(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("no such file or secret: %w", err)
+	}
+
+	return xkcdgen.ParseWordlist(sec.Bytes())
 }
 
 // generateSetPassword will update or create a secret.
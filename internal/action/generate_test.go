@@ -15,6 +15,7 @@ import (
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -152,6 +153,32 @@ func TestGenerate(t *testing.T) {
 		buf.Reset()
 	})
 
+	// generate --force --mask ?u?l?l-?d?d --print foobar
+	t.Run("generate --force --mask ?u?l?l-?d?d --print foobar", func(t *testing.T) {
+		require.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "print": "true", "mask": "?u?l?l-?d?d"}, "foobar")))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Regexp(t, `^[A-Z][a-z]{2}-[0-9]{2}$`, lines[len(lines)-1])
+		assert.Contains(t, buf.String(), "bits")
+		buf.Reset()
+	})
+
+	// generate --force --generator xkcd --wordlist wordlists/fr --print foobar 3
+	t.Run("generate --force --generator xkcd --wordlist wordlists/fr --print foobar 3", func(t *testing.T) {
+		require.NoError(t, act.Store.Set(ctx, "wordlists/fr", secrets.ParseAKV([]byte("pomme\npoire\ncerise\n"))))
+
+		require.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "print": "true", "generator": "xkcd", "sep": "-", "wordlist": "wordlists/fr"}, "foobar", "3")))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Regexp(t, `^(pomme|poire|cerise)(-(pomme|poire|cerise)){2}$`, lines[len(lines)-1])
+		assert.Contains(t, buf.String(), "entropy")
+		buf.Reset()
+	})
+
+	// generate --force --generator mask foobar
+	t.Run("generate --force --generator mask foobar", func(t *testing.T) {
+		require.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "generator": "mask"}, "foobar")))
+		buf.Reset()
+	})
+
 	// generate --force foobar 24 w/ autoclip and output redirection
 	t.Run("generate --force foobar 24", func(t *testing.T) {
 		ov := act.cfg.Get("generate.autoclip")
@@ -5,8 +5,11 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/sensitive"
 	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/urfave/cli/v2"
@@ -15,6 +18,10 @@ import (
 // Grep searches a string inside the content of all files.
 func (s *Action) Grep(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	if c.IsSet("unsafe") {
+		ctx = ctxutil.WithForce(ctx, c.Bool("unsafe"))
+	}
+
 	if !c.Args().Present() {
 		return exit.Error(exit.Usage, nil, "Usage: %s grep arg", s.Name)
 	}
@@ -39,6 +46,7 @@ func (s *Action) Grep(c *cli.Context) error {
 		matchFn = re.MatchString
 	}
 
+	ctx = accesslog.WithAction(ctx, "grep")
 	var matches int
 	var errors int
 	for _, v := range haystack {
@@ -49,7 +57,13 @@ func (s *Action) Grep(c *cli.Context) error {
 			continue
 		}
 
-		if matchFn(string(sec.Bytes())) {
+		// do not reveal the values of sensitive keys by matching them.
+		content := string(sec.Bytes())
+		if !ctxutil.IsForce(ctx) {
+			content = sensitive.Content(config.WithMount(ctx, s.Store.MountPoint(v)), sec)
+		}
+
+		if matchFn(content) {
 			out.Printf(ctx, "%s matches", color.BlueString(v))
 		}
 	}
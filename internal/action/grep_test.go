@@ -10,6 +10,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -56,3 +57,41 @@ func TestGrep(t *testing.T) {
 		require.NoError(t, act.Grep(c))
 	})
 }
+
+func TestGrepSensitive(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.cfg.Set("", "show.sensitive-keys", "pin"))
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("foobar")
+	require.NoError(t, sec.Set("pin", "1234"))
+	require.NoError(t, act.Store.Set(ctx, "card", sec))
+
+	t.Run("sensitive values are not searched", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Grep(gptest.CliCtx(ctx, t, "1234")))
+		assert.NotContains(t, buf.String(), "card matches")
+	})
+
+	t.Run("unsafe", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"unsafe": "true"}, "1234")
+		require.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "card matches")
+	})
+}
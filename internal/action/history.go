@@ -1,9 +1,15 @@
 package action
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/diff"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -29,6 +35,10 @@ func (s *Action) History(c *cli.Context) error {
 		return exit.Error(exit.Unknown, err, "Failed to get revisions: %s", err)
 	}
 
+	if c.Bool("diff") {
+		return s.historyDiff(ctx, name, revs, c.Bool("unsafe"))
+	}
+
 	for _, rev := range revs {
 		pw := ""
 		if showPassword {
@@ -45,3 +55,69 @@ func (s *Action) History(c *cli.Context) error {
 
 	return nil
 }
+
+// historyDiff prints the changes each revision made to the secret. Passwords
+// are masked unless unsafe is set.
+func (s *Action) historyDiff(ctx context.Context, name string, revs []backend.Revision, unsafe bool) error {
+	// revisions are sorted newest first, so each revision is compared to
+	// the next one in the list.
+	contents := make([][]string, len(revs)+1)
+	for i, rev := range revs {
+		_, sec, err := s.Store.GetRevision(ctx, name, rev.Hash)
+		if err != nil {
+			// e.g. the secret was deleted in this revision.
+			debug.Log("Failed to get revision %q of %q: %s", rev.Hash, name, err)
+
+			continue
+		}
+		contents[i] = splitSecretLines(sec.Bytes())
+	}
+
+	for i, rev := range revs {
+		old, cur := contents[i+1], contents[i]
+		if !unsafe {
+			old, cur = maskPasswords(old, cur)
+		}
+
+		out.Printf(ctx, "%s - %s <%s> - %s - %s", rev.Hash, rev.AuthorName, rev.AuthorEmail, rev.Date.Format(time.RFC3339), rev.Subject)
+		// the diff is written directly to avoid leaking it into the debug log.
+		for _, line := range strings.SplitAfter(diff.Changes(old, cur), "\n") {
+			if line == "" {
+				continue
+			}
+			fmt.Fprint(stdout, "  "+line)
+		}
+	}
+
+	return nil
+}
+
+func splitSecretLines(buf []byte) []string {
+	s := strings.TrimSuffix(string(buf), "\n")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// maskPasswords replaces the passwords, i.e. the first lines, with a
+// placeholder. A changed password gets a different placeholder so the
+// change is still visible in the diff.
+func maskPasswords(old, cur []string) ([]string, []string) {
+	const mask = "*****"
+
+	old, cur = slices.Clone(old), slices.Clone(cur)
+	switch {
+	case len(old) > 0 && len(cur) > 0 && old[0] == cur[0]:
+		old[0], cur[0] = mask, mask
+	case len(old) > 0 && len(cur) > 0:
+		old[0], cur[0] = mask, mask+" (changed)"
+	case len(old) > 0:
+		old[0] = mask
+	case len(cur) > 0:
+		cur[0] = mask
+	}
+
+	return old, cur
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -68,4 +70,45 @@ func TestHistory(t *testing.T) {
 		defer buf.Reset()
 		require.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "bar")))
 	})
+
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+	}()
+
+	t.Run("history --diff", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Store.Set(ctx, "baz", secrets.ParseAKV([]byte("secret\nuser: john\n"))))
+		require.NoError(t, act.Store.Set(ctx, "baz", secrets.ParseAKV([]byte("changed\nuser: jane\n"))))
+
+		require.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"diff": "true"}, "baz")))
+		assert.Contains(t, buf.String(), "  - user: john\n")
+		assert.Contains(t, buf.String(), "  + user: jane\n")
+		assert.Contains(t, buf.String(), "  + ***** (changed)\n")
+		assert.NotContains(t, buf.String(), "  - secret\n")
+		assert.NotContains(t, buf.String(), "  + changed\n")
+	})
+
+	t.Run("history --diff --unsafe", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"diff": "true", "unsafe": "true"}, "baz")))
+		assert.Contains(t, buf.String(), "  - secret\n")
+		assert.Contains(t, buf.String(), "  + changed\n")
+	})
+}
+
+func TestMaskPasswords(t *testing.T) {
+	t.Parallel()
+
+	old, cur := maskPasswords([]string{"foo", "a"}, []string{"foo", "b"})
+	assert.Equal(t, []string{"*****", "a"}, old)
+	assert.Equal(t, []string{"*****", "b"}, cur)
+
+	old, cur = maskPasswords([]string{"foo"}, []string{"bar"})
+	assert.Equal(t, []string{"*****"}, old)
+	assert.Equal(t, []string{"***** (changed)"}, cur)
+
+	old, cur = maskPasswords(nil, []string{"bar"})
+	assert.Nil(t, old)
+	assert.Equal(t, []string{"*****"}, cur)
 }
@@ -30,6 +30,18 @@ func (s *Action) List(c *cli.Context) error {
 	stripPrefix := c.Bool("strip-prefix")
 	folders := c.Bool("folders")
 
+	if c.Bool("pending") {
+		return s.listPending(ctx, filter)
+	}
+
+	if c.Bool("worklist") {
+		return s.listWorklist(ctx, filter)
+	}
+
+	if tags := c.StringSlice("tag"); len(tags) > 0 {
+		return s.listTagged(ctx, filter, tags)
+	}
+
 	// print the path if the argument is a direct hit.
 	if s.Store.Exists(ctx, filter) && !s.Store.IsDir(ctx, filter) {
 		fmt.Println(filter)
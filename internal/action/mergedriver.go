@@ -0,0 +1,147 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// gitMergeDriverCommand returns the git subcommand that is used as a merge
+// driver for encrypted secrets. It's configured by the gitfs backend in
+// .gitattributes and the local git config.
+func (s *Action) gitMergeDriverCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "merge-driver",
+		Usage:     "Merge encrypted secrets (invoked by git)",
+		ArgsUsage: "<base> <ours> <theirs> [path]",
+		Description: "" +
+			"This command is invoked by git to merge concurrent changes to the same secret. " +
+			"It decrypts all three versions, merges the password, each key and the body " +
+			"separately and writes the re-encrypted result. Conflicts are marked inside the " +
+			"secret and can be resolved with 'gopass edit'.",
+		Before: s.IsInitialized,
+		Action: s.GitMergeDriver,
+		Hidden: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "store",
+				Usage: "Store to operate on. Defaults to the store in the current directory",
+			},
+		},
+	}
+}
+
+// GitMergeDriver does a three-way merge of encrypted secrets. The result is
+// written to the ours file, as expected by git.
+func (s *Action) GitMergeDriver(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if c.Args().Len() < 3 {
+		return exit.Error(exit.Usage, nil, "Usage: %s git merge-driver <base> <ours> <theirs> [path]", s.Name)
+	}
+
+	baseFn, oursFn, theirsFn := c.Args().Get(0), c.Args().Get(1), c.Args().Get(2)
+
+	sub, err := s.gitDriverStore(c)
+	if err != nil {
+		return exit.Error(exit.Git, err, "failed to find store: %s", err)
+	}
+
+	contents := make([][]byte, 0, 3)
+	for _, fn := range []string{baseFn, oursFn, theirsFn} {
+		buf, err := os.ReadFile(fn)
+		if err != nil {
+			return exit.Error(exit.IO, err, "failed to read %s: %s", fn, err)
+		}
+		contents = append(contents, buf)
+	}
+
+	// git passes the path of the file relative to the repository root. We need
+	// the secret name to find the right recipients.
+	fn := filepath.ToSlash(c.Args().Get(3))
+	if strings.HasPrefix(fn, leaf.TagIndex+".") {
+		return s.gitMergeTags(ctx, sub, fn, oursFn, contents)
+	}
+	name := strings.TrimSuffix(fn, "."+sub.Crypto().Ext())
+
+	merged, clean, err := sub.Merge(ctx, name, contents[0], contents[1], contents[2])
+	if err != nil {
+		return exit.Error(exit.Git, err, "failed to merge %s: %s", name, err)
+	}
+
+	if err := os.WriteFile(oursFn, merged, 0o600); err != nil {
+		return exit.Error(exit.IO, err, "failed to write %s: %s", oursFn, err)
+	}
+
+	if !clean {
+		return exit.Error(exit.Git, nil, "Merge conflict in %s. Run '%s edit %s' to resolve it.", name, s.Name, path.Join(sub.Alias(), name))
+	}
+
+	out.OKf(ctx, "Merged %s", name)
+
+	return nil
+}
+
+// gitMergeTags merges the tag index. It matches the same pattern in
+// .gitattributes as the secrets.
+func (s *Action) gitMergeTags(ctx context.Context, sub *leaf.Store, fn, oursFn string, contents [][]byte) error {
+	merged, err := sub.MergeTags(ctx, fn, contents[0], contents[1], contents[2])
+	if err != nil {
+		return exit.Error(exit.Git, err, "failed to merge the tag index: %s. Run '%s tag rebuild' after resolving the conflict.", err, s.Name)
+	}
+
+	if err := os.WriteFile(oursFn, merged, 0o600); err != nil {
+		return exit.Error(exit.IO, err, "failed to write %s: %s", oursFn, err)
+	}
+
+	out.OKf(ctx, "Merged the tag index")
+
+	return nil
+}
+
+// gitDriverStore returns the store given by the store flag or the one that
+// lives in the current directory. Git runs merge and diff drivers from the
+// root of the repository.
+func (s *Action) gitDriverStore(c *cli.Context) (*leaf.Store, error) {
+	if c.IsSet("store") {
+		return s.Store.GetSubStore(c.String("store"))
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	cwd = evalPath(cwd)
+
+	for _, mp := range append([]string{""}, s.Store.MountPoints()...) {
+		sub, err := s.Store.GetSubStore(mp)
+		if err != nil || sub == nil {
+			continue
+		}
+
+		debug.Log("checking %s (%s) against %s", mp, sub.Path(), cwd)
+		if evalPath(sub.Path()) == cwd {
+			return sub, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not a password store", cwd)
+}
+
+func evalPath(p string) string {
+	if ep, err := filepath.EvalSymlinks(p); err == nil {
+		return ep
+	}
+
+	return filepath.Clean(p)
+}
@@ -0,0 +1,99 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitMergeDriver(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	crypto := act.Store.Crypto(ctx, "")
+	dir := t.TempDir()
+	write := func(t *testing.T, fn, content string) string {
+		t.Helper()
+
+		buf, err := crypto.Encrypt(ctx, []byte(content), act.Store.ListRecipients(ctx, ""))
+		require.NoError(t, err)
+		fn = filepath.Join(dir, fn)
+		require.NoError(t, os.WriteFile(fn, buf, 0o600))
+
+		return fn
+	}
+	read := func(t *testing.T, fn string) string {
+		t.Helper()
+
+		buf, err := os.ReadFile(fn)
+		require.NoError(t, err)
+		plain, err := crypto.Decrypt(ctx, buf)
+		require.NoError(t, err)
+
+		return string(plain)
+	}
+	flags := map[string]string{"store": ""}
+
+	t.Run("missing args", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.GitMergeDriver(gptest.CliCtxWithFlags(ctx, t, flags, "foo")))
+	})
+
+	t.Run("clean merge", func(t *testing.T) {
+		defer buf.Reset()
+
+		base := write(t, "base", "secret\nuser: john\n")
+		ours := write(t, "ours", "secret\nuser: john\nurl: example.org\n")
+		theirs := write(t, "theirs", "changed\nuser: john\npin: 1234\n")
+
+		require.NoError(t, act.GitMergeDriver(gptest.CliCtxWithFlags(ctx, t, flags, base, ours, theirs, "foo."+crypto.Ext())))
+		assert.Equal(t, "changed\nuser: john\nurl: example.org\npin: 1234\n", read(t, ours))
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		defer buf.Reset()
+
+		base := write(t, "base", "secret\n")
+		ours := write(t, "ours", "foo\n")
+		theirs := write(t, "theirs", "bar\n")
+
+		require.Error(t, act.GitMergeDriver(gptest.CliCtxWithFlags(ctx, t, flags, base, ours, theirs, "foo."+crypto.Ext())))
+		assert.Equal(t, "<<<<<<< ours\nfoo\n=======\nbar\n>>>>>>> theirs\n", read(t, ours))
+	})
+
+	t.Run("tag index", func(t *testing.T) {
+		defer buf.Reset()
+
+		base := write(t, "base", "db/prod:\n    - prod\n")
+		ours := write(t, "ours", "db/prod:\n    - prod\n    - pci\n")
+		theirs := write(t, "theirs", "db/prod:\n    - prod\ndb/staging:\n    - staging\n")
+
+		require.NoError(t, act.GitMergeDriver(gptest.CliCtxWithFlags(ctx, t, flags, base, ours, theirs, leaf.TagIndex+"."+crypto.Ext())))
+		assert.Equal(t, "db/prod:\n    - prod\n    - pci\ndb/staging:\n    - staging\n", read(t, ours))
+	})
+}
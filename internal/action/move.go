@@ -26,9 +26,23 @@ func (s *Action) Move(c *cli.Context) error {
 		}
 	}
 
+	// searching for references needs to decrypt every secret in the mount,
+	// so it's opt-in.
+	updateRefs := c.Bool("refs")
+	rename := s.moveRenamer(ctx, from, to)
+	mount := s.Store.MountPoint(from)
+
 	if err := s.Store.Move(ctx, from, to); err != nil {
 		return exit.Error(exit.Unknown, err, "%s", err)
 	}
 
+	if !updateRefs {
+		return nil
+	}
+
+	if err := s.updateRefs(ctx, mount, rename); err != nil {
+		return exit.Error(exit.Unknown, err, "%s", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,194 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/accesslog"
+	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/audit"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/set"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// generatedMessages are the commit messages used when gopass writes a
+// generated password.
+var generatedMessages = []string{
+	"Generated Password",
+	"Generated password for key",
+	"Generated password for YAML key",
+	"Started password rotation",
+	"Confirmed password rotation",
+}
+
+// recipientsExposed returns all secrets of the store that the recipient could
+// decrypt now or in any earlier revision.
+func (s *Action) recipientsExposed(ctx context.Context, store string, crypto backend.Crypto, r string) ([]string, error) {
+	ids := []string{r}
+	if keys, err := crypto.FindRecipients(ctx, r); err == nil {
+		ids = append(ids, keys...)
+	}
+	if fp := crypto.Fingerprint(ctx, r); fp != "" {
+		ids = append(ids, fp)
+	}
+
+	out.Printf(ctx, "Looking for secrets exposed to %s. This may take some time ...", r)
+
+	return s.Store.ExposedSecrets(ctx, store, ids...)
+}
+
+// recipientsOffboard adds all exposed secrets to the rotation worklist of the
+// store. With --regenerate the rotation of generated passwords is started
+// right away instead.
+func (s *Action) recipientsOffboard(ctx context.Context, c *cli.Context, store string, exposed map[string][]string) error {
+	sub, err := s.Store.GetSubStore(store)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "failed to get store %q: %s", store, err)
+	}
+
+	wl, err := audit.LoadWorklist(ctx, sub)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to load rotation worklist: %s", err)
+	}
+
+	var regenerated int
+	for _, r := range set.SortedKeys(exposed) {
+		for _, name := range exposed[r] {
+			fullName := mountName(store, name)
+
+			sec, err := s.Store.Get(accesslog.WithInternalRead(ctx, true), fullName)
+			if err != nil {
+				out.Errorf(ctx, "Failed to read %s: %s", fullName, err)
+
+				continue
+			}
+
+			if c.Bool("regenerate") && !isRotationPending(sec) && s.wasGenerated(ctx, fullName) {
+				if _, err := s.startRotation(ctx, c, fullName, "", sec); err == nil {
+					regenerated++
+
+					continue
+				}
+				out.Errorf(ctx, "Failed to regenerate %s: %s", fullName, err)
+			}
+
+			if err := wl.Add(name, r, sec.Password()); err != nil {
+				return exit.Error(exit.Unknown, err, "failed to add %s to the rotation worklist: %s", fullName, err)
+			}
+		}
+	}
+
+	if err := audit.SaveWorklist(ctx, sub, wl); err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to write rotation worklist: %s", err)
+	}
+
+	if regenerated > 0 {
+		out.Noticef(ctx, "Started the rotation of %d generated passwords. See 'gopass list --pending' to finish them.", regenerated)
+	}
+	out.Noticef(ctx, "%d secrets need to be rotated. See 'gopass list --worklist' or 'gopass audit'.", len(wl.Entries))
+
+	return nil
+}
+
+// wasGenerated returns true if the current password of the secret was
+// generated by gopass according to the revision history. Re-encryptions are
+// skipped since they don't change the content.
+func (s *Action) wasGenerated(ctx context.Context, name string) bool {
+	revs, err := s.Store.ListRevisions(ctx, name)
+	if err != nil {
+		debug.Log("failed to list revisions of %s: %s", name, err)
+
+		return false
+	}
+
+	for _, r := range revs {
+		if !strings.HasPrefix(r.Subject, "Save secret to ") {
+			continue
+		}
+
+		for _, msg := range generatedMessages {
+			if strings.HasSuffix(r.Subject, ": "+msg) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return false
+}
+
+// rotationWorklist loads the rotation worklist of the given store and drops
+// all entries that are done, i.e. the password was changed or the secret
+// was removed.
+func (s *Action) rotationWorklist(ctx context.Context, store string) (*audit.Worklist, error) {
+	sub, err := s.Store.GetSubStore(store)
+	if err != nil {
+		return nil, err
+	}
+
+	wl, err := audit.LoadWorklist(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]string, 0, len(wl.Entries))
+	for _, e := range wl.Entries {
+		sec, err := s.Store.Get(accesslog.WithInternalRead(ctx, true), mountName(store, e.Secret))
+		if err != nil {
+			if !s.Store.Exists(ctx, mountName(store, e.Secret)) {
+				done = append(done, e.Secret)
+			}
+
+			continue
+		}
+
+		if e.Done(sec.Password()) {
+			done = append(done, e.Secret)
+		}
+	}
+
+	if wl.Remove(done...) < 1 {
+		return wl, nil
+	}
+
+	debug.Log("%d secrets in %q have been rotated", len(done), store)
+	if err := audit.SaveWorklist(ctx, sub, wl); err != nil {
+		return wl, err
+	}
+
+	return wl, nil
+}
+
+// listWorklist prints all secrets below filter that still need to be rotated
+// after offboarding a recipient.
+func (s *Action) listWorklist(ctx context.Context, filter string) error {
+	for _, store := range append([]string{""}, s.Store.MountPoints()...) {
+		wl, err := s.rotationWorklist(ctx, store)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to load rotation worklist of %q: %s", store, err)
+		}
+
+		for _, e := range wl.Entries {
+			name := mountName(store, e.Secret)
+			if !hasPrefixFolder(name, filter) {
+				continue
+			}
+			fmt.Fprintf(stdout, "%s (exposed to %s)\n", name, e.Recipient)
+		}
+	}
+
+	return nil
+}
+
+func mountName(store, name string) string {
+	if store == "" {
+		return name
+	}
+
+	return store + "/" + name
+}
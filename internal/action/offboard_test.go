@@ -0,0 +1,81 @@
+package action
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/audit"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecipientsOffboard(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.RecipientsAdd(gptest.CliCtx(ctx, t, "0xFEEDBEEF")))
+	buf.Reset()
+
+	t.Run("offboard with group", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.RecipientsRemove(gptest.CliCtxWithFlags(ctx, t, map[string]string{"offboard": "true", "group": "sre"}, "0xFEEDBEEF")))
+	})
+
+	t.Run("offboard", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.RecipientsRemove(gptest.CliCtxWithFlags(ctx, t, map[string]string{"offboard": "true"}, "0xFEEDBEEF")))
+		assert.Contains(t, buf.String(), "1 secrets need to be rotated")
+		assert.True(t, act.Store.Storage(ctx, "").Exists(ctx, audit.WorklistFile))
+	})
+
+	t.Run("list worklist", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"worklist": "true"})))
+		assert.Equal(t, "foo (exposed to 0xFEEDBEEF)\n", buf.String())
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		defer buf.Reset()
+		// the weak password is reported as well.
+		require.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"full": "true"})))
+		assert.Contains(t, buf.String(), "Exposed to removed recipient 0xFEEDBEEF")
+	})
+
+	t.Run("rotated secrets are done", func(t *testing.T) {
+		defer buf.Reset()
+
+		sec := secrets.NewAKV()
+		sec.SetPassword("rotated")
+		require.NoError(t, act.Store.Set(ctx, "foo", sec))
+
+		require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"worklist": "true"})))
+		assert.Empty(t, buf.String())
+		assert.False(t, act.Store.Storage(ctx, "").Exists(ctx, audit.WorklistFile))
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gopasspw/gopass/internal/action/exit"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/sensitive"
 	"github.com/gopasspw/gopass/internal/tpl"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/urfave/cli/v2"
@@ -13,6 +14,7 @@ import (
 // Process is a command to process a template and replace secrets contained in it.
 func (s *Action) Process(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = sensitive.WithMasking(ctx, !c.Bool("unsafe"))
 	file := c.Args().First()
 	if file == "" {
 		return exit.Error(exit.Usage, nil, "Usage: %s process <FILE>", s.Name)
@@ -56,6 +56,11 @@ func GetCommands() []*cli.Command {
 					Usage:   "Language to generate password from, currently only en (english, default) or de are supported",
 					Value:   "en",
 				},
+				&cli.StringFlag{
+					Name:    "wordlist",
+					Aliases: []string{"xkcdwordlist", "xw"},
+					Usage:   "Wordlist file to draw words from, one word per line or in the EFF diceware format",
+				},
 				&cli.BoolFlag{
 					Name:    "xkcdcapitalize",
 					Aliases: []string{"xc"},
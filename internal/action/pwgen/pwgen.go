@@ -5,6 +5,7 @@
 package pwgen
 
 import (
+	"os"
 	"strconv"
 
 	"github.com/gopasspw/gopass/internal/action/exit"
@@ -18,6 +19,9 @@ import (
 
 // Pwgen handles the pwgen subcommand.
 func Pwgen(c *cli.Context) error {
+	// stdout only contains the passwords, so they can be piped.
+	c.Context = out.WithNoticeStderr(c.Context, true)
+
 	pwLen := 12
 	if lenStr := c.Args().Get(0); lenStr != "" {
 		i, err := strconv.Atoi(lenStr)
@@ -71,8 +75,37 @@ func xkcdGen(c *cli.Context, length, num int) error {
 		numbers = c.Bool("xkcdnumbers")
 	}
 
+	wordlist := config.String(c.Context, "pwgen.xkcd-wordlist")
+	if c.IsSet("wordlist") {
+		wordlist = c.String("wordlist")
+	}
+
+	var wl xkcdgen.Wordlist
+	if wordlist != "" {
+		buf, err := os.ReadFile(wordlist)
+		if err != nil {
+			return exit.Error(exit.IO, err, "failed to read wordlist %q: %s", wordlist, err)
+		}
+		wl, err = xkcdgen.ParseWordlist(buf)
+		if err != nil {
+			return exit.Error(exit.Usage, err, "invalid wordlist %q: %s", wordlist, err)
+		}
+		if err := wl.CheckSeparable(sep); err != nil {
+			return exit.Error(exit.Usage, err, "wordlist %q can not be used without a separator: %s", wordlist, err)
+		}
+		out.Noticef(c.Context, "Passphrase entropy: %.1f bits (%d words from a list of %d)", wl.Entropy(length), length, len(wl))
+	} else if e, err := xkcdgen.LangEntropy(length, lang); err == nil {
+		out.Noticef(c.Context, "Passphrase entropy: %.1f bits", e)
+	}
+
 	for range num {
-		s, err := xkcdgen.RandomLengthDelim(length, sep, lang, capitalize, numbers)
+		var s string
+		var err error
+		if wl != nil {
+			s, err = xkcdgen.RandomLengthDelimWordlist(length, sep, wl, capitalize, numbers)
+		} else {
+			s, err = xkcdgen.RandomLengthDelim(length, sep, lang, capitalize, numbers)
+		}
 		if err != nil {
 			return err
 		}
@@ -3,6 +3,7 @@ package pwgen
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/config"
@@ -29,3 +30,28 @@ func TestPwgen(t *testing.T) {
 	require.NoError(t, Pwgen(gptest.CliCtxWithFlags(ctx, t, map[string]string{"one-per-line": "true"}, "24", "1")))
 	assert.GreaterOrEqual(t, len(buf.Bytes()), 24, buf.String())
 }
+
+func TestPwgenWordlist(t *testing.T) {
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	bufErr := &bytes.Buffer{}
+	out.Stderr = bufErr
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	fn := filepath.Join(t.TempDir(), "wordlist.txt")
+	require.NoError(t, os.WriteFile(fn, []byte("11111\tpomme\n11112\tpoire\n11113\tcerise\n"), 0o644))
+
+	require.NoError(t, Pwgen(gptest.CliCtxWithFlags(ctx, t, map[string]string{"xkcd": "true", "sep": "-", "wordlist": fn}, "3", "1")))
+	assert.Contains(t, bufErr.String(), "bits")
+	assert.Regexp(t, `^(pomme|poire|cerise)(-(pomme|poire|cerise)){2}\n$`, buf.String())
+	buf.Reset()
+
+	require.NoError(t, os.WriteFile(fn, []byte("pomme\npomme\n"), 0o644))
+	require.Error(t, Pwgen(gptest.CliCtxWithFlags(ctx, t, map[string]string{"xkcd": "true", "sep": "-", "wordlist": fn}, "3", "1")))
+}
@@ -3,12 +3,15 @@ package action
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/action/exit"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/cui"
 	"github.com/gopasspw/gopass/internal/out"
+	recps "github.com/gopasspw/gopass/internal/recipients"
 	"github.com/gopasspw/gopass/internal/set"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -81,6 +84,48 @@ func (s *Action) RecipientsAck(c *cli.Context) error {
 	return s.Store.SaveRecipients(ctxutil.WithHidden(ctx, true), true)
 }
 
+// RecipientsCheck checks the keys of all recipients and fails if any of them
+// is missing, expired, revoked or about to expire.
+func (s *Action) RecipientsCheck(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if c.Bool("refresh") {
+		if err := s.Store.ImportMissingPublicKeys(leaf.WithPubkeyUpdate(ctx, true)); err != nil {
+			out.Errorf(ctx, "Failed to import updated public keys: %s", err)
+		}
+	}
+
+	stores := append([]string{""}, s.Store.MountPoints()...)
+	if c.IsSet("store") {
+		stores = []string{c.String("store")}
+	}
+
+	var problems int
+	for _, store := range stores {
+		name := store
+		if name == "" {
+			name = "<root>"
+		}
+
+		invalid, expiring := s.Store.CheckRecipientKeys(ctx, store)
+		for _, k := range set.SortedKeys(invalid) {
+			fmt.Fprintf(stdout, "%s: %s: %s\n", name, k, invalid[k])
+		}
+		for _, k := range set.SortedKeys(expiring) {
+			fmt.Fprintf(stdout, "%s: %s: key expires on %s\n", name, k, expiring[k].Format(time.DateOnly))
+		}
+		problems += len(invalid) + len(expiring)
+	}
+
+	if problems > 0 {
+		return exit.Error(exit.Recipients, nil, "found %d problems with recipient keys", problems)
+	}
+
+	out.OKf(ctx, "All recipient keys are valid")
+
+	return nil
+}
+
 // RecipientsAdd adds new recipients.
 func (s *Action) RecipientsAdd(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
@@ -114,8 +159,23 @@ func (s *Action) RecipientsAdd(c *cli.Context) error {
 		recipients = r
 	}
 
+	group := c.String("group")
+
 	debug.Log("adding recipients: %+v", recipients)
 	for _, r := range recipients {
+		// group references are added as they are, there is no key to look up.
+		if recps.IsGroup(r) && group == "" {
+			if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to add the group %q as a recipient to the store %q?", r, store)) {
+				continue
+			}
+			if err := s.Store.AddRecipient(ctx, store, r); err != nil {
+				return exit.Error(exit.Recipients, err, "failed to add recipient %q: %s", r, err)
+			}
+			added++
+
+			continue
+		}
+
 		keys, err := crypto.FindRecipients(ctx, r)
 		if err != nil {
 			out.Warningf(ctx, "Failed to list public key %q: %s", r, err)
@@ -143,6 +203,18 @@ func (s *Action) RecipientsAdd(c *cli.Context) error {
 
 		debug.Log("found recipients for %q: %+v", r, keys)
 
+		if group != "" {
+			if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to add %q (key %q) to the group %q of the store %q?", crypto.FormatKey(ctx, r, ""), r, recps.GroupPrefix+recps.GroupName(group), store)) {
+				continue
+			}
+			if err := s.Store.AddGroupRecipient(ctx, store, group, r); err != nil {
+				return exit.Error(exit.Recipients, err, "failed to add recipient %q to group %q: %s", r, group, err)
+			}
+			added++
+
+			continue
+		}
+
 		if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to add %q (key %q) as a recipient to the store %q?", crypto.FormatKey(ctx, r, ""), r, store)) {
 			continue
 		}
@@ -188,7 +260,20 @@ func (s *Action) RecipientsRemove(c *cli.Context) error {
 		recipients = rs
 	}
 
+	offboard := c.Bool("offboard")
+	if c.Bool("regenerate") && !offboard {
+		return exit.Error(exit.Usage, nil, "--regenerate can only be used with --offboard")
+	}
+	if group := c.String("group"); group != "" {
+		if offboard {
+			return exit.Error(exit.Usage, nil, "--offboard can not be used with --group")
+		}
+
+		return s.recipientsRemoveFromGroup(ctx, store, group, recipients)
+	}
+
 	knownRecipients := s.Store.ListRecipients(ctx, store)
+	exposed := make(map[string][]string, len(recipients))
 
 	// try to remove all given recipients.
 	for _, r := range recipients {
@@ -202,6 +287,14 @@ func (s *Action) RecipientsRemove(c *cli.Context) error {
 
 		// if a literal recipient (e.g. ID) is given just remove that w/o any kind of lookups.
 		if set.Contains(knownRecipients, r) {
+			if offboard {
+				names, err := s.recipientsExposed(ctx, store, crypto, r)
+				if err != nil {
+					return exit.Error(exit.Recipients, err, "failed to find secrets exposed to %q: %s", r, err)
+				}
+				exposed[r] = names
+			}
+
 			debug.Log("Removing %q from %q (direct)", r, store)
 			if err := s.Store.RemoveRecipient(ctx, store, r); err != nil {
 				return exit.Error(exit.Recipients, err, "failed to remove recipient %q: %s", r, err)
@@ -242,6 +335,14 @@ func (s *Action) RecipientsRemove(c *cli.Context) error {
 			}
 		}
 
+		if offboard {
+			names, err := s.recipientsExposed(ctx, store, crypto, recp)
+			if err != nil {
+				return exit.Error(exit.Recipients, err, "failed to find secrets exposed to %q: %s", recp, err)
+			}
+			exposed[recp] = names
+		}
+
 		debug.Log("Removing %q from %q (indirect)", recp, store)
 		if err := s.Store.RemoveRecipient(ctx, store, recp); err != nil {
 			return exit.Error(exit.Recipients, err, "failed to remove recipient %q: %s", recp, err)
@@ -256,6 +357,13 @@ func (s *Action) RecipientsRemove(c *cli.Context) error {
 	}
 
 	out.Printf(ctx, "\nRemoved %d recipients", removed)
+
+	if offboard {
+		if err := s.recipientsOffboard(ctx, c, store, exposed); err != nil {
+			return err
+		}
+	}
+
 	out.Printf(ctx, "You need to run 'gopass sync' to push these changes")
 
 	return nil
@@ -308,3 +416,31 @@ func (s *Action) recipientsSelectForAdd(ctx context.Context, store string) ([]st
 		return nil, exit.Error(exit.Aborted, nil, "user aborted")
 	}
 }
+
+// recipientsRemoveFromGroup removes the recipients from a group. Recipients
+// are matched literally or by their fingerprint.
+func (s *Action) recipientsRemoveFromGroup(ctx context.Context, store, group string, recipients []string) error {
+	crypto := s.Store.Crypto(ctx, store)
+
+	removed := 0
+	for _, r := range recipients {
+		err := s.Store.RemoveGroupRecipient(ctx, store, group, r)
+		if err != nil {
+			if fp := crypto.Fingerprint(ctx, r); fp != "" && fp != r {
+				debug.Log("Fingerprint translated %q into %q", r, fp)
+				err = s.Store.RemoveGroupRecipient(ctx, store, group, fp)
+			}
+		}
+		if err != nil {
+			return exit.Error(exit.Recipients, err, "failed to remove recipient %q from group %q: %s", r, group, err)
+		}
+
+		fmt.Fprintf(stdout, removalWarning, r)
+		removed++
+	}
+
+	out.Printf(ctx, "\nRemoved %d recipients from %s%s", removed, recps.GroupPrefix, recps.GroupName(group))
+	out.Printf(ctx, "You need to run 'gopass sync' to push these changes")
+
+	return nil
+}
@@ -57,6 +57,12 @@ func TestRecipients(t *testing.T) {
 		assert.Equal(t, want, buf.String())
 	})
 
+	t.Run("check recipients", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.RecipientsCheck(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "All recipient keys are valid")
+	})
+
 	t.Run("add recipients w/o args", func(t *testing.T) {
 		defer buf.Reset()
 		require.Error(t, act.RecipientsAdd(gptest.CliCtx(ctx, t)))
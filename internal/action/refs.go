@@ -0,0 +1,126 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/reference"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+// fsckRefs reports references that point to secrets or fields that don't
+// exist and reference cycles.
+func (s *Action) fsckRefs(ctx context.Context, names []string) error {
+	var dangling int
+	for _, name := range names {
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			debug.Log("failed to decrypt %s: %s", name, err)
+
+			continue
+		}
+
+		for _, err := range reference.Check(ctx, s.Store, name, sec) {
+			out.Errorf(ctx, "Invalid reference in %s: %s", name, err)
+			dangling++
+		}
+	}
+
+	if dangling > 0 {
+		return fmt.Errorf("found %d invalid references", dangling)
+	}
+
+	return nil
+}
+
+// moveRenamer returns a function that maps the names of the secrets that
+// will be moved from one location to another to their new names. It
+// follows the rules of the store move.
+func (s *Action) moveRenamer(ctx context.Context, from, to string) func(string) (string, bool) {
+	srcIsDir := s.Store.IsDir(ctx, from) && !s.Store.Exists(ctx, from)
+	dstIsDir := s.Store.IsDir(ctx, to)
+	if to == "." || to == "/" {
+		dstIsDir = false
+		to = ""
+	}
+	from = strings.Trim(from, "/")
+
+	return func(name string) (string, bool) {
+		if (!srcIsDir && name != from) || (srcIsDir && !strings.HasPrefix(name, from+"/")) {
+			return "", false
+		}
+
+		switch {
+		case dstIsDir && !srcIsDir:
+			return path.Join(to, path.Base(name)), true
+		case dstIsDir:
+			return path.Join(to, name), true
+		case !srcIsDir:
+			return strings.Trim(to, "/"), true
+		default:
+			return strings.Trim(path.Join(to, strings.TrimPrefix(name, from)), "/"), true
+		}
+	}
+}
+
+// updateRefs offers to update the references to moved secrets. References
+// never leave their mount so only the secrets in the given mount are
+// searched.
+func (s *Action) updateRefs(ctx context.Context, mount string, rename func(string) (string, bool)) error {
+	names, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return fmt.Errorf("failed to list store: %w", err)
+	}
+
+	updates := make(map[string]gopass.Secret)
+	for _, name := range names {
+		if s.Store.MountPoint(name) != mount {
+			continue
+		}
+
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			debug.Log("failed to decrypt %s: %s", name, err)
+
+			continue
+		}
+
+		if upd, changed := reference.Rename(sec, rename); changed {
+			updates[name] = upd
+		}
+	}
+
+	if len(updates) < 1 {
+		return nil
+	}
+
+	refs := make([]string, 0, len(updates))
+	for name := range updates {
+		refs = append(refs, name)
+	}
+	slices.Sort(refs)
+
+	out.Noticef(ctx, "%d secrets reference the moved secrets: %s", len(refs), strings.Join(refs, ", "))
+	if !termio.AskForConfirmation(ctx, "Update the references?") {
+		return nil
+	}
+
+	ctx = ctxutil.WithCommitMessage(ctx, "Update references to moved secrets")
+	for _, name := range refs {
+		if err := s.Store.Set(ctx, name, updates[name]); err != nil {
+			return fmt.Errorf("failed to update references in %s: %w", name, err)
+		}
+	}
+
+	out.OKf(ctx, "Updated references in %d secrets", len(refs))
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package action
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefs(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+	color.NoColor = true
+
+	for name, content := range map[string]string{
+		"db/prod":   "dbpass\nuser: admin\n",
+		"svc/api":   "!ref db/prod\nuser: !ref db/prod#user\n",
+		"svc/cron":  "cronpw\ndb: ref+gopass://db/prod#password\n",
+		"svc/stale": "stalepw\ndb: !ref db/staging\n",
+	} {
+		require.NoError(t, act.Store.Set(ctx, name, secrets.ParseAKV([]byte(content))))
+	}
+
+	t.Run("show resolves references", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "svc/api")))
+		assert.Equal(t, "dbpass\nuser: admin\n", buf.String())
+	})
+
+	t.Run("show --noparsing", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"noparsing": "true"}, "svc/api")
+		require.NoError(t, act.Show(c))
+		assert.Equal(t, "!ref db/prod\nuser: !ref db/prod#user\n", buf.String())
+	})
+
+	t.Run("show dangling reference", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Show(gptest.CliCtx(ctx, t, "svc/stale")))
+	})
+
+	t.Run("fsck --decrypt", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"decrypt": "true"})
+		require.Error(t, act.Fsck(c))
+		assert.Contains(t, buf.String(), "Invalid reference in svc/stale")
+		assert.NotContains(t, buf.String(), "Invalid reference in svc/api")
+	})
+
+	t.Run("move --refs updates references", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"refs": "true"}, "db", "database")
+		require.NoError(t, act.Move(c))
+
+		sec, err := act.Store.Get(ctx, "svc/api")
+		require.NoError(t, err)
+		assert.Equal(t, "!ref database/prod\nuser: !ref database/prod#user\n", string(sec.Bytes()))
+
+		sec, err = act.Store.Get(ctx, "svc/cron")
+		require.NoError(t, err)
+		assert.Equal(t, "cronpw\ndb: ref+gopass://database/prod#password\n", string(sec.Bytes()))
+	})
+
+	t.Run("move without --refs", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Move(gptest.CliCtx(ctx, t, "database/prod", "db/prod")))
+
+		sec, err := act.Store.Get(ctx, "svc/api")
+		require.NoError(t, err)
+		assert.Equal(t, "!ref database/prod\nuser: !ref database/prod#user\n", string(sec.Bytes()))
+	})
+}
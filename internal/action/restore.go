@@ -0,0 +1,120 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// Restore writes a past revision of a secret as a new revision. The secret
+// is re-encrypted to the current recipients. Without a revision it restores
+// a deleted secret, like undelete.
+func (s *Action) Restore(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	revision := c.String("revision")
+
+	if name == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s restore <NAME> [--revision <hash|-N>]", s.Name)
+	}
+
+	if revision == "" {
+		if s.Store.Exists(ctx, name) {
+			return exit.Error(exit.Usage, nil, "Secret %s exists. Use --revision to restore an older revision", name)
+		}
+
+		return s.undelete(ctx, name)
+	}
+
+	rev, err := s.parseRevision(ctx, name, revision)
+	if err != nil {
+		return exit.Error(exit.Usage, err, "Failed to parse revision %q: %s", revision, err)
+	}
+
+	ctx, sec, err := s.Store.GetRevision(ctx, name, rev)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "Failed to get revision %q of %s: %s", rev, name, err)
+	}
+
+	ctx = ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Restored %s from revision %s", name, rev))
+	if err := s.Store.Set(ctx, name, sec); err != nil {
+		return exit.Error(exit.Encrypt, err, "Failed to restore %s: %s", name, err)
+	}
+
+	out.OKf(ctx, "Restored %s from revision %s", name, rev)
+
+	return nil
+}
+
+// Trash lists the secrets that were deleted in the history of the stores.
+func (s *Action) Trash(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	prefix := c.Args().First()
+
+	dels, err := s.Store.ListDeleted(ctx)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "Failed to list deleted secrets: %s", err)
+	}
+
+	for _, d := range dels {
+		if prefix != "" && !strings.HasPrefix(d.Name, prefix) {
+			continue
+		}
+		out.Printf(ctx, "%s - deleted by %s <%s> on %s - %s - %s", d.Name, d.AuthorName, d.AuthorEmail, d.Date.Format(time.RFC3339), d.Hash, d.Subject)
+	}
+
+	return nil
+}
+
+// Undelete restores a deleted secret from the last revision before it was
+// deleted.
+func (s *Action) Undelete(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+
+	if name == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s undelete <NAME>", s.Name)
+	}
+
+	if s.Store.Exists(ctx, name) {
+		return exit.Error(exit.Usage, nil, "Secret %s exists. Use '%s restore --revision' to restore an older revision", name, s.Name)
+	}
+
+	return s.undelete(ctx, name)
+}
+
+func (s *Action) undelete(ctx context.Context, name string) error {
+	revs, err := s.Store.ListRevisions(ctx, name)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "Failed to get revisions of %s: %s", name, err)
+	}
+
+	// revisions are sorted newest first. The newest one is usually the
+	// deletion, so we use the first one that still has the secret.
+	for _, rev := range revs {
+		_, sec, err := s.Store.GetRevision(ctx, name, rev.Hash)
+		if err != nil {
+			debug.Log("Failed to get revision %q of %q: %s", rev.Hash, name, err)
+
+			continue
+		}
+
+		ctx := ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Restored deleted secret %s from revision %s", name, rev.Hash))
+		if err := s.Store.Set(ctx, name, sec); err != nil {
+			return exit.Error(exit.Encrypt, err, "Failed to restore %s: %s", name, err)
+		}
+
+		out.OKf(ctx, "Restored %s from revision %s", name, rev.Hash)
+
+		return nil
+	}
+
+	return exit.Error(exit.NotFound, nil, "No revision of %s found. See '%s trash' for deleted secrets", name, s.Name)
+}
@@ -0,0 +1,136 @@
+package action
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestore(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	cfg := config.NewInMemory()
+	require.NoError(t, cfg.SetPath(u.StoreDir("")))
+
+	act, err := newAction(cfg, semver.Version{}, false)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	require.NoError(t, act.IsInitialized(gptest.CliCtx(ctx, t)))
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+
+	for _, pw := range []string{"first", "second", "third"} {
+		sec := secrets.New()
+		sec.SetPassword(pw)
+		require.NoError(t, act.Store.Set(ctxutil.WithCommitMessage(ctx, "set "+pw), "bar", sec))
+	}
+	buf.Reset()
+
+	t.Run("no args", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Restore(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("existing secret without revision", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Restore(gptest.CliCtx(ctx, t, "bar")))
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Restore(gptest.CliCtxWithFlags(ctx, t, map[string]string{"revision": "-10"}, "bar")))
+	})
+
+	t.Run("restore previous revision", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Restore(gptest.CliCtxWithFlags(ctx, t, map[string]string{"revision": "-1"}, "bar")))
+
+		_, sec, err := act.Store.GetRevision(ctx, "bar", "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, "second", sec.Password())
+
+		revs, err := act.Store.ListRevisions(ctx, "bar")
+		require.NoError(t, err)
+		assert.Len(t, revs, 4)
+		assert.Contains(t, revs[0].Subject, "Restored bar from revision")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Delete(gptest.CliCtx(ctx, t, "bar")))
+		assert.False(t, act.Store.Exists(ctx, "bar"))
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Trash(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "bar - deleted by foo bar <foo.bar@example.org>")
+	})
+
+	t.Run("trash with other prefix", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Trash(gptest.CliCtx(ctx, t, "foo")))
+		assert.NotContains(t, buf.String(), "bar")
+	})
+
+	t.Run("undelete no args", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Undelete(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("undelete unknown", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Undelete(gptest.CliCtx(ctx, t, "unknown")))
+	})
+
+	t.Run("undelete", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Undelete(gptest.CliCtx(ctx, t, "bar")))
+
+		sec, err := act.Store.Get(ctx, "bar")
+		require.NoError(t, err)
+		assert.Equal(t, "second", sec.Password())
+	})
+
+	t.Run("undelete existing", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Undelete(gptest.CliCtx(ctx, t, "bar")))
+	})
+
+	t.Run("trash is empty", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Trash(gptest.CliCtx(ctx, t)))
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,234 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// rotatePreviousKey holds the old password while a rotation is pending.
+	rotatePreviousKey = "previous"
+	// rotateStartedKey holds the time when a pending rotation was started.
+	// A rotation is only pending if it is set, a previous key alone may
+	// belong to the user.
+	rotateStartedKey = "rotation-started"
+)
+
+// openURL opens the given URL in the default browser. It's a variable so
+// tests can replace it.
+var openURL = func(ctx context.Context, u string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "open", u)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.CommandContext(ctx, "xdg-open", u)
+	}
+
+	debug.Log("opening %s with %s", u, cmd.Path)
+
+	return cmd.Start()
+}
+
+// Rotate replaces the password of a secret with a newly generated one. The old
+// password is kept in the previous field until the rotation is either confirmed
+// or rolled back.
+func (s *Action) Rotate(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = WithClip(ctx, c.Bool("clip"))
+	name := c.Args().First()
+
+	if name == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s rotate [--confirm|--rollback] <secret> [length]", s.Name)
+	}
+
+	ctx = config.WithMount(ctx, s.Store.MountPoint(name))
+
+	switch {
+	case c.Bool("confirm") && c.Bool("rollback"):
+		return exit.Error(exit.Usage, nil, "--confirm and --rollback are mutually exclusive")
+	case c.Bool("confirm"):
+		return s.rotateConfirm(ctx, name)
+	case c.Bool("rollback"):
+		return s.rotateRollback(ctx, name)
+	default:
+		return s.rotateStart(ctx, c, name)
+	}
+}
+
+func (s *Action) rotateStart(ctx context.Context, c *cli.Context, name string) error {
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to read %q: %s", name, err)
+	}
+
+	if isRotationPending(sec) {
+		return exit.Error(exit.Aborted, nil, "A rotation of %q is already pending. Use --confirm or --rollback to finish it first.", name)
+	}
+	if _, found := sec.Get(rotatePreviousKey); found {
+		return exit.Error(exit.Aborted, nil, "%q already has a %q field. Please rename it before rotating the password.", name, rotatePreviousKey)
+	}
+
+	password, err := s.startRotation(ctx, c, name, c.Args().Get(1), sec)
+	if err != nil {
+		return err
+	}
+
+	if err := s.generateCopyOrPrint(ctx, c, name, "", password); err != nil {
+		return err
+	}
+
+	if u := rotateChangeURL(ctx, name, sec); u != "" {
+		if c.Bool("open") {
+			if err := openURL(ctx, u); err != nil {
+				out.Errorf(ctx, "Failed to open %s: %s", u, err)
+			}
+		}
+		out.Noticef(ctx, "Change your password at %s", u)
+	}
+
+	out.Printf(ctx, "Run 'gopass rotate --confirm %s' once the new password is active or 'gopass rotate --rollback %s' to restore the old one.", name, name)
+
+	return nil
+}
+
+// startRotation generates a new password for the secret and keeps the old one
+// until the rotation is confirmed or rolled back.
+func (s *Action) startRotation(ctx context.Context, c *cli.Context, name, length string, sec gopass.Secret) (string, error) {
+	password, err := s.generatePassword(ctx, c, length, name)
+	if err != nil {
+		return "", err
+	}
+
+	_ = sec.Set(rotatePreviousKey, sec.Password())
+	_ = sec.Set(rotateStartedKey, time.Now().UTC().Format(time.RFC3339))
+	sec.SetPassword(password)
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Started password rotation"), name, sec); err != nil {
+		return "", exit.Error(exit.Encrypt, err, "failed to write %q: %s", name, err)
+	}
+
+	return password, nil
+}
+
+func (s *Action) rotateConfirm(ctx context.Context, name string) error {
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to read %q: %s", name, err)
+	}
+
+	if !isRotationPending(sec) {
+		return exit.Error(exit.NotFound, nil, "No pending rotation for %q", name)
+	}
+
+	_ = sec.Del(rotatePreviousKey)
+	_ = sec.Del(rotateStartedKey)
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Confirmed password rotation"), name, sec); err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to write %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Password rotation of %q confirmed", name)
+
+	return nil
+}
+
+func (s *Action) rotateRollback(ctx context.Context, name string) error {
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to read %q: %s", name, err)
+	}
+
+	if !isRotationPending(sec) {
+		return exit.Error(exit.NotFound, nil, "No pending rotation for %q", name)
+	}
+
+	prev, _ := sec.Get(rotatePreviousKey)
+	sec.SetPassword(prev)
+	_ = sec.Del(rotatePreviousKey)
+	_ = sec.Del(rotateStartedKey)
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Rolled back password rotation"), name, sec); err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to write %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Restored the previous password of %q", name)
+
+	return nil
+}
+
+// listPending prints all secrets below filter with a pending rotation. This
+// needs to decrypt every secret.
+func (s *Action) listPending(ctx context.Context, filter string) error {
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return exit.Error(exit.List, err, "failed to list store: %s", err)
+	}
+
+	for _, name := range list {
+		if !hasPrefixFolder(name, filter) {
+			continue
+		}
+
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			debug.Log("failed to decrypt %s: %s", name, err)
+
+			continue
+		}
+
+		if !isRotationPending(sec) {
+			continue
+		}
+
+		started, _ := sec.Get(rotateStartedKey)
+		if started == "" {
+			fmt.Fprintln(stdout, name)
+
+			continue
+		}
+		fmt.Fprintf(stdout, "%s (rotation started %s)\n", name, started)
+	}
+
+	return nil
+}
+
+func hasPrefixFolder(name, folder string) bool {
+	if folder == "" || folder == "/" {
+		return true
+	}
+
+	return strings.HasPrefix(name, strings.TrimSuffix(folder, "/")+"/")
+}
+
+func isRotationPending(sec gopass.Secret) bool {
+	_, found := sec.Get(rotateStartedKey)
+
+	return found
+}
+
+// rotateChangeURL returns the password change URL stored in the secret or
+// the well-known one for the domain of the secret.
+func rotateChangeURL(ctx context.Context, name string, sec gopass.Secret) string {
+	if u, found := sec.Get("password-change-url"); found && u != "" {
+		return u
+	}
+
+	return hasChangeURL(ctx, name)
+}
@@ -0,0 +1,180 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	require.NoError(t, act.cfg.Set("", "generate.autoclip", "false"))
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+	color.NoColor = true
+
+	t.Run("no args", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Rotate(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("confirm without pending rotation", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"confirm": "true"}, "foo")))
+	})
+
+	t.Run("start rotation", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true"}, "foo", "24")))
+
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 24)
+		assert.NotEqual(t, "secret", sec.Password())
+		prev, found := sec.Get(rotatePreviousKey)
+		assert.True(t, found)
+		assert.Equal(t, "secret", prev)
+		_, found = sec.Get(rotateStartedKey)
+		assert.True(t, found)
+	})
+
+	t.Run("start twice", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true"}, "foo")))
+	})
+
+	t.Run("list pending", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"pending": "true"})))
+		assert.Contains(t, buf.String(), "foo (rotation started ")
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"rollback": "true"}, "foo")))
+
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+		_, found := sec.Get(rotatePreviousKey)
+		assert.False(t, found)
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true"}, "foo", "16")))
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"confirm": "true"}, "foo")))
+
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 16)
+		_, found := sec.Get(rotatePreviousKey)
+		assert.False(t, found)
+		_, found = sec.Get(rotateStartedKey)
+		assert.False(t, found)
+	})
+
+	t.Run("nothing pending", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"pending": "true"})))
+		assert.NotContains(t, buf.String(), "foo")
+	})
+
+	t.Run("user key named previous", func(t *testing.T) {
+		defer buf.Reset()
+
+		sec := secrets.NewAKV()
+		sec.SetPassword("old")
+		require.NoError(t, sec.Set("previous", "older"))
+		require.NoError(t, act.Store.Set(ctx, "bar", sec))
+
+		require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"pending": "true"})))
+		assert.NotContains(t, buf.String(), "bar")
+
+		// the field of the user must not be overwritten.
+		require.Error(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true"}, "bar")))
+		require.Error(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"rollback": "true"}, "bar")))
+
+		got, err := act.Store.Get(ctx, "bar")
+		require.NoError(t, err)
+		assert.Equal(t, "old", got.Password())
+		prev, found := got.Get("previous")
+		assert.True(t, found)
+		assert.Equal(t, "older", prev)
+
+		assert.True(t, got.Del("previous"))
+		require.NoError(t, act.Store.Set(ctx, "bar", got))
+	})
+
+	t.Run("open change url", func(t *testing.T) {
+		defer buf.Reset()
+
+		var opened string
+		oldOpen := openURL
+		openURL = func(_ context.Context, u string) error {
+			opened = u
+
+			return nil
+		}
+		defer func() {
+			openURL = oldOpen
+		}()
+
+		sec := secrets.NewAKV()
+		sec.SetPassword("old")
+		require.NoError(t, sec.Set("password-change-url", "https://example.org/change"))
+		require.NoError(t, act.Store.Set(ctx, "baz", sec))
+
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true", "open": "true"}, "baz")))
+		assert.Equal(t, "https://example.org/change", opened)
+		assert.Contains(t, buf.String(), "https://example.org/change")
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"rollback": "true"}, "baz")))
+	})
+
+	t.Run("generator flags", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true", "mask": "?d?d?d?d"}, "bar")))
+
+		sec, err := act.Store.Get(ctx, "bar")
+		require.NoError(t, err)
+		assert.Regexp(t, "^[0-9]{4}$", sec.Password())
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"confirm": "true"}, "bar")))
+
+		require.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true", "generator": "xkcd", "sep": "-", "lang": "en"}, "bar", "3")))
+
+		sec, err = act.Store.Get(ctx, "bar")
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(sec.Password(), "-"), 3)
+	})
+
+	t.Run("confirm and rollback", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"confirm": "true", "rollback": "true"}, "foo")))
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/gopasspw/gopass/internal/backend/crypto/age"
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	gpgcli "github.com/gopasspw/gopass/internal/backend/crypto/gpg/cli"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg/openpgp"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store/root"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -128,7 +129,7 @@ func (s *Action) initCheckPrivateKeys(ctx context.Context, crypto backend.Crypto
 func (s *Action) initGenerateIdentity(ctx context.Context, crypto backend.Crypto, name, email string) error {
 	out.Printf(ctx, "🧪 Creating cryptographic key pair (%s) ...", crypto.Name())
 
-	if crypto.Name() == gpgcli.Name {
+	if crypto.Name() == gpgcli.Name || crypto.Name() == openpgp.Name {
 		var err error
 
 		out.Printf(ctx, "🎩 Gathering information for the %s key pair ...", crypto.Name())
@@ -302,7 +303,7 @@ func (s *Action) initLocal(ctx context.Context, remote string) error {
 		return fmt.Errorf("failed to init local store: %w", err)
 	}
 
-	if backend.GetStorageBackend(ctx) == backend.GitFS {
+	if be := backend.GetStorageBackend(ctx); be == backend.GitFS || be == backend.GoGit || be == backend.FossilFS {
 		debug.Log("configuring git remotes")
 		if want, err := termio.AskForBool(ctx, "❓ Do you want to add a git remote?", false); (err == nil && want) || remote != "" {
 			out.Printf(ctx, "Configuring the git remote ...")
@@ -311,7 +312,6 @@ func (s *Action) initLocal(ctx context.Context, remote string) error {
 			}
 		}
 	}
-	// TODO remotes for fossil, etc.
 
 	// detect and add mount a for passage
 	if err := s.initDetectPassage(ctx); err != nil {
@@ -12,6 +12,8 @@ import (
 	"github.com/gopasspw/gopass/internal/hook"
 	"github.com/gopasspw/gopass/internal/notify"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/reference"
+	"github.com/gopasspw/gopass/internal/sensitive"
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -120,6 +122,14 @@ func (s *Action) show(ctx context.Context, c *cli.Context, name string, recurse
 		return s.showHandleError(ctx, c, name, recurse, err)
 	}
 
+	// references are shown as is with --noparsing.
+	if ctxutil.IsShowParsing(ctx) {
+		sec, err = reference.Resolve(ctx, s.Store, name, sec)
+		if err != nil {
+			return exit.Error(exit.NotFound, err, "failed to resolve references in %s: %s", name, err)
+		}
+	}
+
 	return s.showHandleOutput(ctx, name, sec)
 }
 
@@ -156,13 +166,15 @@ func (s *Action) parseRevision(ctx context.Context, name, revision string) (stri
 		return "", err
 	}
 
-	if len(revs) < offset {
+	// revisions are sorted newest first, so -N is the N-th revision
+	// before the latest one (cf. HEAD~N).
+	if offset < 0 || len(revs) <= offset {
 		debug.Log("Not enough revisions (%d)", len(revs))
 
-		return revStr, nil
+		return "", fmt.Errorf("only %d revisions of %s available", len(revs), name)
 	}
 
-	revision = revs[len(revs)-offset].Hash
+	revision = revs[offset].Hash
 	debug.Log("Found %s for offset %d", revision, offset)
 
 	return revision, nil
@@ -217,6 +229,7 @@ func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.S
 		if err := clipboard.CopyTo(ctx, name, []byte(pw), config.AsInt(s.cfg.Get("core.cliptimeout"))); err != nil {
 			return err
 		}
+		s.Store.LogAccess(ctx, name, "copy to clipboard")
 	}
 
 	if body == "" {
@@ -247,6 +260,11 @@ func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string,
 			return "", "", exit.Error(exit.NotFound, store.ErrNoKey, "%v", store.ErrNoKey)
 		}
 		val := strings.Join(values, "\n")
+		// explicitly requested keys are never masked. With -c they are only
+		// copied to the clipboard.
+		if IsOnlyClip(ctx) {
+			return val, "", nil
+		}
 
 		return val, val, nil
 	}
@@ -272,7 +290,7 @@ func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string,
 
 	// everything but the first line.
 	if config.Bool(ctx, "show.safecontent") && !ctxutil.IsForce(ctx) && ctxutil.IsShowParsing(ctx) {
-		body := showSafeContent(sec)
+		body := sensitive.Redact(ctx, sec, true)
 		if IsAlsoClip(ctx) {
 			return pw, body, nil
 		}
@@ -280,63 +298,13 @@ func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string,
 		return "", body, nil
 	}
 
-	// everything (default).
-	return pw, fullBody, nil
-}
-
-func showSafeContent(sec gopass.Secret) string {
-	var sb strings.Builder
-	for i, k := range sec.Keys() {
-		sb.WriteString(k)
-		sb.WriteString(": ")
-		// check if this key should be obstructed.
-		if isUnsafeKey(k, sec) {
-			debug.V(1).Log("obstructing unsafe key %s", k)
-			sb.WriteString(randAsterisk())
-		} else {
-			v, found := sec.Values(k)
-			if !found {
-				continue
-			}
-			sb.WriteString(strings.Join(v, "\n"+k+": "))
-		}
-		// we only add a final new line if the body is non-empty.
-		if sec.Body() != "" || i < len(sec.Keys())-1 {
-			sb.WriteString("\n")
-		}
-	}
-
-	sb.WriteString(sec.Body())
-
-	return sb.String()
-}
-
-func isUnsafeKey(key string, sec gopass.Secret) bool {
-	if strings.ToLower(key) == "password" {
-		return true
-	}
-
-	uks, found := sec.Get("unsafe-keys")
-	if !found || uks == "" {
-		return false
-	}
-
-	for _, uk := range strings.Split(uks, ",") {
-		uk = strings.TrimSpace(uk)
-		if uk == "" {
-			continue
-		}
-		if strings.EqualFold(uk, key) {
-			return true
-		}
+	// everything but sensitive keys.
+	if !ctxutil.IsForce(ctx) && ctxutil.IsShowParsing(ctx) && sensitive.HasSensitive(ctx, sec) {
+		return pw, sensitive.Content(ctx, sec), nil
 	}
 
-	return false
-}
-
-func randAsterisk() string {
-	// we could also have a random number of asterisks but testing becomes painful.
-	return strings.Repeat("*", 5)
+	// everything (default).
+	return pw, fullBody, nil
 }
 
 // hasAliasDomain will try to find a possible alias mapping for the secret
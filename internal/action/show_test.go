@@ -420,3 +420,65 @@ func TestShowHasAliasDomain(t *testing.T) {
 	alias := act.hasAliasDomain(ctx, "websites/foo.com/user")
 	assert.Equal(t, "websites/foo.de/user", alias)
 }
+
+func TestShowSensitive(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.cfg.Set("", "show.sensitive-keys", "pin, *-secret"))
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("123")
+	require.NoError(t, sec.Set("user", "john"))
+	require.NoError(t, sec.Set("pin", "4711"))
+	require.NoError(t, sec.Set("api-secret", "s3cr3t"))
+	require.NoError(t, sec.Set("cvv", "999"))
+	require.NoError(t, sec.Set("unsafe-keys", "cvv"))
+	require.NoError(t, act.Store.Set(ctx, "card", sec))
+	buf.Reset()
+
+	t.Run("masked", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "card")))
+		assert.Contains(t, buf.String(), "123")
+		assert.Contains(t, buf.String(), "user: john")
+		assert.Contains(t, buf.String(), "pin: *****")
+		assert.Contains(t, buf.String(), "api-secret: *****")
+		assert.Contains(t, buf.String(), "cvv: *****")
+		assert.NotContains(t, buf.String(), "4711")
+		assert.NotContains(t, buf.String(), "s3cr3t")
+		assert.NotContains(t, buf.String(), "999")
+	})
+
+	t.Run("unsafe", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"unsafe": "true"}, "card")
+		require.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "4711")
+		assert.Contains(t, buf.String(), "999")
+	})
+
+	t.Run("explicit key", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "card", "pin")))
+		assert.Equal(t, "4711", buf.String())
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/diff"
+	"github.com/gopasspw/gopass/internal/editor"
 	"github.com/gopasspw/gopass/internal/notify"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
@@ -46,7 +47,10 @@ func init() {
 
 // Sync all stores with their remotes.
 func (s *Action) Sync(c *cli.Context) error {
-	return s.sync(ctxutil.WithGlobalFlags(c), c.String("store"))
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = WithEditor(ctx, editor.Path(c))
+
+	return s.sync(ctx, c.String("store"))
 }
 
 func (s *Action) autoSync(ctx context.Context) error {
@@ -167,6 +171,7 @@ func (s *Action) syncMount(ctx context.Context, mp string) error {
 		return nil
 	}
 
+	ctx = config.WithMount(ctx, mp)
 	ctxno := out.WithNewline(ctx, false)
 	name := mp
 	if mp == "" {
@@ -194,10 +199,27 @@ func (s *Action) syncMount(ctx context.Context, mp string) error {
 
 	out.Printf(ctxno, "\n   "+color.GreenString("%s pull and push ... ", sub.Storage().Name()))
 
-	switch err := sub.Storage().Push(ctx, "", ""); {
+	status, err := syncPush(ctx, sub.Storage())
+	if errors.Is(err, store.ErrGitConflict) && canResolveConflicts(ctx) {
+		out.Printf(ctxno, color.YellowString("Conflict"))
+		if err = s.syncResolveConflicts(ctx, sub); err == nil {
+			out.Printf(ctxno, "\n   "+color.GreenString("%s push ... ", sub.Storage().Name()))
+			status, err = syncPush(ctx, sub.Storage())
+		}
+	}
+	if len(status) > 1 && status[0].Err == nil {
+		// the primary remote is up to date, failed mirrors are only reported.
+		err = nil
+	}
+
+	switch {
 	case err == nil:
 		debug.Log("Push succeeded")
-		out.Printf(ctxno, color.GreenString("OK"))
+		if len(status) > 1 {
+			syncPrintRemotes(ctxno, status)
+		} else {
+			out.Printf(ctxno, color.GreenString("OK"))
+		}
 	case errors.Is(err, store.ErrGitNoRemote):
 		out.Printf(ctx, "Skipped (no remote)")
 		debug.Log("Failed to push %q to its remote: %s", name, err)
@@ -207,7 +229,19 @@ func (s *Action) syncMount(ctx context.Context, mp string) error {
 		out.Printf(ctxno, "Skipped (not supported)")
 	case errors.Is(err, store.ErrGitNotInit):
 		out.Printf(ctxno, "Skipped (no Git repo)")
+	case errors.Is(err, store.ErrGitConflict):
+		out.Errorf(ctx, "Failed to merge %q: %s", name, err)
+		abort := s.Name + " git merge --abort"
+		if mp != "" {
+			abort = s.Name + " git --store=" + mp + " merge --abort"
+		}
+		out.Noticef(ctx, "Run '%s sync' in a terminal to resolve the conflicts or '%s' to abort the merge", s.Name, abort)
+
+		return err
 	default: // any other error
+		if len(status) > 1 {
+			syncPrintRemotes(ctxno, status)
+		}
 		out.Errorf(ctx, "Failed to push %q to its remote: %s", name, err)
 
 		return err
@@ -234,6 +268,34 @@ func (s *Action) syncMount(ctx context.Context, mp string) error {
 	return nil
 }
 
+// remotePusher is implemented by storage backends that can push to several
+// remotes.
+type remotePusher interface {
+	PushRemotes(ctx context.Context) ([]backend.RemoteStatus, error)
+}
+
+// syncPush pushes the storage to its remotes and returns the status of each
+// remote, if the backend supports that.
+func syncPush(ctx context.Context, st backend.Storage) ([]backend.RemoteStatus, error) {
+	if rp, ok := st.(remotePusher); ok {
+		return rp.PushRemotes(ctx)
+	}
+
+	return nil, st.Push(ctx, "", "")
+}
+
+func syncPrintRemotes(ctxno context.Context, status []backend.RemoteStatus) {
+	for _, rs := range status {
+		out.Printf(ctxno, "\n     %s ... ", rs.Remote)
+		if rs.Err != nil {
+			out.Printf(ctxno, color.RedString("FAILED (%s)", rs.Err))
+
+			continue
+		}
+		out.Printf(ctxno, color.GreenString("OK"))
+	}
+}
+
 func syncImportKeys(ctx context.Context, sub *leaf.Store, name string) error {
 	// import keys.
 	if err := sub.ImportMissingPublicKeys(ctx); err != nil {
@@ -0,0 +1,144 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+// TagAdd adds tags to a secret.
+func (s *Action) TagAdd(c *cli.Context) error {
+	return s.tagUpdate(c, "add", func(cur, tags []string) []string {
+		return append(cur, tags...)
+	})
+}
+
+// TagRemove removes tags from a secret.
+func (s *Action) TagRemove(c *cli.Context) error {
+	return s.tagUpdate(c, "remove", func(cur, tags []string) []string {
+		return slices.DeleteFunc(cur, func(t string) bool {
+			return slices.Contains(tags, t)
+		})
+	})
+}
+
+func (s *Action) tagUpdate(c *cli.Context, op string, fn func(cur, tags []string) []string) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	tags := c.Args().Tail()
+
+	if name == "" || len(tags) < 1 {
+		return exit.Error(exit.Usage, nil, "Usage: %s tag %s <secret> <tag> [<tag>...]", s.Name, op)
+	}
+
+	for i, t := range tags {
+		if err := secrets.ValidTag(t); err != nil {
+			return exit.Error(exit.Usage, err, "invalid tag: %s", err)
+		}
+		tags[i] = strings.TrimSpace(t)
+	}
+
+	ctx = config.WithMount(ctx, s.Store.MountPoint(name))
+
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
+	}
+
+	cur := secrets.Tags(sec)
+	updated := fn(slices.Clone(cur), tags)
+	if err := secrets.SetTags(sec, updated); err != nil {
+		return exit.Error(exit.Unknown, err, "failed to set tags of %s: %s", name, err)
+	}
+
+	if slices.Equal(cur, secrets.Tags(sec)) {
+		out.Noticef(ctx, "Tags of %s did not change", name)
+
+		return nil
+	}
+
+	ctx = ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Update tags of %s", name))
+	if err := s.Store.Set(ctx, name, sec); err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to save %s: %s", name, err)
+	}
+
+	out.OKf(ctx, "Tags of %s: %s", name, strings.Join(secrets.Tags(sec), ", "))
+
+	return nil
+}
+
+// TagList prints the tags of a secret or all tags with the number of secrets
+// that have them.
+func (s *Action) TagList(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if name := c.Args().First(); name != "" {
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
+		}
+
+		for _, t := range secrets.Tags(sec) {
+			fmt.Fprintln(stdout, t)
+		}
+
+		return nil
+	}
+
+	tags, err := s.Store.Tags(ctx)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to read tag index: %s", err)
+	}
+
+	for _, t := range slices.Sorted(maps.Keys(tags)) {
+		fmt.Fprintf(stdout, "%s (%d)\n", t, len(tags[t]))
+	}
+
+	return nil
+}
+
+// TagRebuild decrypts all secrets and recreates the tag index of each store.
+func (s *Action) TagRebuild(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	stores := append([]string{""}, s.Store.MountPoints()...)
+	if c.IsSet("store") {
+		stores = []string{c.String("store")}
+	}
+
+	for _, store := range stores {
+		if err := s.Store.RebuildTags(ctx, store); err != nil {
+			return exit.Error(exit.Encrypt, err, "failed to rebuild tag index of %q: %s", store, err)
+		}
+	}
+
+	out.OKf(ctx, "Rebuilt tag index")
+
+	return nil
+}
+
+// listTagged prints all secrets below filter that have all of the given tags.
+func (s *Action) listTagged(ctx context.Context, filter string, tags []string) error {
+	names, err := s.Store.Tagged(ctx, tags...)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to read tag index: %s", err)
+	}
+
+	for _, name := range names {
+		if !hasPrefixFolder(name, filter) {
+			continue
+		}
+		fmt.Fprintln(stdout, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package action
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestTag(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	tagCtx := func(t *testing.T, args ...string) *cli.Context {
+		t.Helper()
+
+		fs := flag.NewFlagSet("default", flag.ContinueOnError)
+		f := &cli.StringSliceFlag{Name: "tag"}
+		require.NoError(t, f.Apply(fs))
+		require.NoError(t, fs.Parse(args))
+		c := cli.NewContext(cli.NewApp(), fs, nil)
+		c.Context = ctx
+
+		return c
+	}
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("secret")
+	require.NoError(t, sec.Set("user", "john"))
+	require.NoError(t, act.Store.Set(ctx, "db/prod", sec))
+	require.NoError(t, act.Store.Set(ctx, "db/staging", sec))
+
+	t.Run("add", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.TagAdd(gptest.CliCtx(ctx, t, "db/prod", "prod", "pci")))
+		require.NoError(t, act.TagAdd(gptest.CliCtx(ctx, t, "db/staging", "staging")))
+
+		sec, err := act.Store.Get(ctx, "db/prod")
+		require.NoError(t, err)
+		assert.Equal(t, "secret\nuser: john\ntags: prod, pci\n", string(sec.Bytes()))
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.TagAdd(gptest.CliCtx(ctx, t, "db/prod", "a,b")))
+		require.Error(t, act.TagAdd(gptest.CliCtx(ctx, t, "db/prod")))
+	})
+
+	t.Run("list secret", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.TagList(gptest.CliCtx(ctx, t, "db/prod")))
+		assert.Equal(t, "prod\npci\n", buf.String())
+	})
+
+	t.Run("list all", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.TagList(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "pci (1)\nprod (1)\nstaging (1)\n", buf.String())
+	})
+
+	t.Run("list --tag", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.List(tagCtx(t, "--tag=prod")))
+		assert.Equal(t, "db/prod\n", buf.String())
+	})
+
+	t.Run("find --tag", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Find(tagCtx(t, "--tag=staging", "db")))
+		assert.Contains(t, buf.String(), "db/staging")
+		assert.NotContains(t, buf.String(), "db/prod")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.TagRemove(gptest.CliCtx(ctx, t, "db/prod", "pci")))
+
+		sec, err := act.Store.Get(ctx, "db/prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"prod"}, secrets.Tags(sec))
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.TagRebuild(gptest.CliCtx(ctx, t)))
+
+		names, err := act.Store.Tagged(ctx, "prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db/prod"}, names)
+	})
+}
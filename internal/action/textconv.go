@@ -0,0 +1,158 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/action/exit"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+)
+
+// textconvAttributes are written to .git/info/attributes to enable the
+// textconv driver. This file is not versioned, so enabling it only affects
+// the local clone.
+const textconvAttributes = "*.gpg diff=gopass\n*.age diff=gopass\n"
+
+// gitTextconvCommand returns the git subcommand that is used as a textconv
+// driver to show decrypted diffs in git log -p and git diff.
+func (s *Action) gitTextconvCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "textconv",
+		Usage:     "Decrypt a secret for git diff (invoked by git)",
+		ArgsUsage: "<file>",
+		Description: "" +
+			"This command is invoked by git to convert encrypted secrets to text before " +
+			"showing diffs. Passwords are masked unless --unsafe is given. " +
+			"Use --install to enable it for the local clone of a store.",
+		Before: s.IsInitialized,
+		Action: s.GitTextconv,
+		Hidden: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "store",
+				Usage: "Store to operate on. Defaults to the store in the current directory",
+			},
+			&cli.BoolFlag{
+				Name:  "unsafe",
+				Usage: "Do not mask passwords",
+			},
+			&cli.BoolFlag{
+				Name:  "install",
+				Usage: "Configure git to use this command for the store",
+			},
+		},
+	}
+}
+
+// GitTextconv prints the decrypted content of an encrypted secret.
+func (s *Action) GitTextconv(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if c.Bool("install") {
+		sub, err := s.Store.GetSubStore(c.String("store"))
+		if err != nil {
+			return exit.Error(exit.Git, err, "failed to find store: %s", err)
+		}
+
+		return s.installTextconv(ctx, sub, c.Bool("unsafe"))
+	}
+
+	fn := c.Args().First()
+	if fn == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s git textconv [--unsafe] <file>", s.Name)
+	}
+
+	sub, err := s.gitDriverStore(c)
+	if err != nil {
+		return exit.Error(exit.Git, err, "failed to find store: %s", err)
+	}
+
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return exit.Error(exit.IO, err, "failed to read %s: %s", fn, err)
+	}
+
+	if len(buf) < 1 {
+		return nil
+	}
+
+	content, err := sub.Decrypt(ctx, textconvName(sub, fn), buf)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", fn, err)
+	}
+
+	lines := splitSecretLines(content)
+	if !c.Bool("unsafe") && len(lines) > 0 {
+		lines[0] = "*****"
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+
+	return nil
+}
+
+// textconvName returns the name of the secret in file. git passes older
+// versions as temporary files, those are named by their base name.
+func textconvName(sub *leaf.Store, fn string) string {
+	if rel, err := filepath.Rel(sub.Path(), fn); err == nil && !strings.HasPrefix(rel, "..") {
+		fn = rel
+	} else {
+		fn = filepath.Base(fn)
+	}
+
+	return strings.TrimSuffix(filepath.ToSlash(fn), "."+sub.Crypto().Ext())
+}
+
+type gitConfigSetter interface {
+	ConfigSet(context.Context, string, string) error
+}
+
+// installTextconv configures the textconv driver in the local git config of
+// the given store.
+func (s *Action) installTextconv(ctx context.Context, sub *leaf.Store, unsafe bool) error {
+	cs, ok := sub.Storage().(gitConfigSetter)
+	if !ok {
+		return exit.Error(exit.Unsupported, nil, "Storage backend %s does not support textconv", sub.Storage().Name())
+	}
+
+	cmd := "gopass git textconv"
+	if unsafe {
+		cmd += " --unsafe"
+	}
+
+	if err := cs.ConfigSet(ctx, "diff.gopass.textconv", cmd); err != nil {
+		return exit.Error(exit.Git, err, "failed to set diff.gopass.textconv: %s", err)
+	}
+
+	fn := filepath.Join(sub.Path(), ".git", "info", "attributes")
+	buf, err := os.ReadFile(fn)
+	if err != nil && !os.IsNotExist(err) {
+		return exit.Error(exit.IO, err, "failed to read %s: %s", fn, err)
+	}
+
+	if !strings.Contains(string(buf), textconvAttributes) {
+		if len(buf) > 0 && !strings.HasSuffix(string(buf), "\n") {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, []byte(textconvAttributes)...)
+
+		if err := os.MkdirAll(filepath.Dir(fn), 0o700); err != nil {
+			return exit.Error(exit.IO, err, "failed to create %s: %s", filepath.Dir(fn), err)
+		}
+		if err := os.WriteFile(fn, buf, 0o600); err != nil {
+			return exit.Error(exit.IO, err, "failed to write %s: %s", fn, err)
+		}
+	}
+
+	out.OKf(ctx, "Enabled decrypted diffs for %s. Try 'gopass git log -p'.", sub.Path())
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitTextconv(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	ctx = act.cfg.WithConfig(ctx)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	crypto := act.Store.Crypto(ctx, "")
+	ciphertext, err := crypto.Encrypt(ctx, []byte("secret\nuser: john\n"), act.Store.ListRecipients(ctx, ""))
+	require.NoError(t, err)
+	fn := filepath.Join(t.TempDir(), "foo."+crypto.Ext())
+	require.NoError(t, os.WriteFile(fn, ciphertext, 0o600))
+
+	t.Run("missing file", func(t *testing.T) {
+		defer buf.Reset()
+		require.Error(t, act.GitTextconv(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": ""})))
+	})
+
+	t.Run("masked", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.GitTextconv(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": ""}, fn)))
+		assert.Equal(t, "*****\nuser: john\n", buf.String())
+	})
+
+	t.Run("unsafe", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.GitTextconv(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "", "unsafe": "true"}, fn)))
+		assert.Equal(t, "secret\nuser: john\n", buf.String())
+	})
+
+	t.Run("install", func(t *testing.T) {
+		defer buf.Reset()
+		ctx := backend.WithStorageBackend(ctx, backend.GitFS)
+		require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+		require.NoError(t, act.GitTextconv(gptest.CliCtxWithFlags(ctx, t, map[string]string{"install": "true"})))
+		// installing twice must not duplicate the attributes.
+		require.NoError(t, act.GitTextconv(gptest.CliCtxWithFlags(ctx, t, map[string]string{"install": "true"})))
+
+		attrs, err := os.ReadFile(filepath.Join(u.StoreDir(""), ".git", "info", "attributes"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, bytes.Count(attrs, []byte("*.age diff=gopass")))
+	})
+}
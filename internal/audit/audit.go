@@ -14,6 +14,7 @@ import (
 
 	"github.com/gopasspw/gopass-hibp/pkg/hibp/api"
 	"github.com/gopasspw/gopass-hibp/pkg/hibp/dump"
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/hashsum"
@@ -205,7 +206,7 @@ func (a *Auditor) auditSecret(ctx context.Context, secret string) {
 		a.r.SetAge(secret, time.Since(revs[0].Date))
 	}
 
-	sec, err := a.s.Get(ctx, secret)
+	sec, err := a.s.Get(accesslog.WithAction(ctx, "audit"), secret)
 	if err != nil {
 		debug.Log("Failed to check %s: %s", secret, err)
 
@@ -0,0 +1,265 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/set"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"gopkg.in/yaml.v3"
+)
+
+// BaselineFile is the name of the encrypted audit baseline in the root of a store.
+const BaselineFile = leaf.BaselineFile
+
+// BaselineEntry is a single acknowledged (secret, finding) pair.
+type BaselineEntry struct {
+	Secret        string    `yaml:"secret"`
+	Finding       string    `yaml:"finding"`
+	Justification string    `yaml:"justification,omitempty"`
+	Added         time.Time `yaml:"added"`
+	Expires       time.Time `yaml:"expires,omitempty"`
+	// Digest is a salted hash of the password the finding was acknowledged
+	// for. The entry no longer applies once the password changes.
+	Digest string `yaml:"digest,omitempty"`
+}
+
+// Expired returns true if the entry has an expiry date and it is before now.
+func (e BaselineEntry) Expired(now time.Time) bool {
+	if e.Expires.IsZero() {
+		return false
+	}
+
+	return now.After(e.Expires)
+}
+
+// matches returns true if the entry was added for the password with the
+// given hash. Entries for secrets without a password have no digest.
+func (e BaselineEntry) matches(pwHash string) bool {
+	if e.Digest == "" {
+		return pwHash == ""
+	}
+
+	salt, _, found := strings.Cut(e.Digest, "$")
+	if !found {
+		return false
+	}
+
+	return digest(salt, pwHash) == e.Digest
+}
+
+// Baseline is a list of acknowledged findings. Acknowledged findings are not
+// reported by later audit runs until they expire.
+type Baseline struct {
+	Entries []BaselineEntry `yaml:"entries"`
+}
+
+// Lookup returns the entry for the given secret and finding, if any.
+func (b *Baseline) Lookup(secret, finding string) (BaselineEntry, bool) {
+	if b == nil {
+		return BaselineEntry{}, false
+	}
+
+	for _, e := range b.Entries {
+		if e.Secret == secret && e.Finding == finding {
+			return e, true
+		}
+	}
+
+	return BaselineEntry{}, false
+}
+
+// Add adds or replaces an entry for the given secret and finding.
+func (b *Baseline) Add(e BaselineEntry) {
+	for i, o := range b.Entries {
+		if o.Secret == e.Secret && o.Finding == e.Finding {
+			b.Entries[i] = e
+
+			return
+		}
+	}
+
+	b.Entries = append(b.Entries, e)
+}
+
+// Update adds all current findings from the given report to the baseline.
+// New entries are bound to the current password of the secret.
+// Entries for audited secrets whose findings are no longer reported are
+// removed. Entries for secrets not contained in the report are kept.
+func (b *Baseline) Update(r *Report, justification string, expires time.Time) error {
+	now := time.Now().UTC()
+
+	entries := make([]BaselineEntry, 0, len(b.Entries))
+	for _, e := range b.Entries {
+		if _, found := r.Secrets[e.Secret]; !found {
+			entries = append(entries, e)
+		}
+	}
+	for _, name := range set.SortedKeys(r.Secrets) {
+		sr := r.Secrets[name]
+		for _, finding := range set.SortedKeys(sr.Findings) {
+			if sr.Findings[finding].Severity == "none" {
+				continue
+			}
+
+			e := BaselineEntry{
+				Secret:        name,
+				Finding:       finding,
+				Justification: justification,
+				Added:         now,
+				Expires:       expires,
+			}
+			// keep the justification and dates of existing, still valid entries
+			// unless we were given a new justification or the password changed.
+			// Entries written before digests were added are kept as well.
+			if o, found := b.Lookup(name, finding); found && !o.Expired(now) && justification == "" && (o.Digest == "" || o.matches(sr.pwHash)) {
+				e = o
+			}
+			if e.Digest == "" && sr.pwHash != "" {
+				d, err := saltedDigest(sr.pwHash)
+				if err != nil {
+					return err
+				}
+				e.Digest = d
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Secret == entries[j].Secret {
+			return entries[i].Finding < entries[j].Finding
+		}
+
+		return entries[i].Secret < entries[j].Secret
+	})
+
+	b.Entries = entries
+
+	return nil
+}
+
+// Apply removes all acknowledged and not yet expired findings from the report.
+// Entries that were added for a different password are ignored.
+// It returns the number of suppressed findings.
+func (b *Baseline) Apply(r *Report) int {
+	if b == nil || r == nil {
+		return 0
+	}
+
+	now := time.Now().UTC()
+
+	var suppressed int
+	for name, sr := range r.Secrets {
+		for finding, f := range sr.Findings {
+			if f.Severity == "none" {
+				continue
+			}
+
+			e, found := b.Lookup(name, finding)
+			if !found {
+				continue
+			}
+
+			if e.Expired(now) {
+				debug.Log("Baseline entry for %s / %s expired at %s", name, finding, e.Expires)
+				f.Message += fmt.Sprintf(" (acknowledgement expired on %s)", e.Expires.Format(time.DateOnly))
+				sr.Findings[finding] = f
+
+				continue
+			}
+
+			if !e.matches(sr.pwHash) {
+				debug.Log("Baseline entry for %s / %s was added for a different password", name, finding)
+				f.Message += " (acknowledged for a previous password)"
+				sr.Findings[finding] = f
+
+				continue
+			}
+
+			debug.Log("Suppressing acknowledged finding %s / %s", name, finding)
+			sr.Findings[finding] = Finding{
+				Severity: "none",
+				Message:  "acknowledged",
+			}
+			suppressed++
+
+			if ss, found := r.Findings[finding]; found {
+				ss.Discard(name)
+				if ss.Len() < 1 {
+					delete(r.Findings, finding)
+				}
+			}
+		}
+		r.Secrets[name] = sr
+	}
+
+	return suppressed
+}
+
+// Marshal serializes the baseline.
+func (b *Baseline) Marshal() ([]byte, error) {
+	return yaml.Marshal(b)
+}
+
+// UnmarshalBaseline parses a serialized baseline.
+func UnmarshalBaseline(buf []byte) (*Baseline, error) {
+	b := &Baseline{}
+	if err := yaml.Unmarshal(buf, b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+
+	return b, nil
+}
+
+// Files reads and writes the encrypted files in the root of a store, e.g.
+// leaf.Store. They are kept for both crypto backends during a migration.
+type Files interface {
+	ReadFile(ctx context.Context, name string) ([]byte, error)
+	WriteFile(ctx context.Context, name string, content []byte) error
+	Storage() backend.Storage
+}
+
+// LoadBaseline reads and decrypts the baseline of the given store.
+// It returns an empty baseline if none exists.
+func LoadBaseline(ctx context.Context, st Files) (*Baseline, error) {
+	buf, err := st.ReadFile(ctx, BaselineFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		debug.Log("No audit baseline found in %s", st.Storage())
+
+		return &Baseline{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	return UnmarshalBaseline(buf)
+}
+
+// SaveBaseline encrypts the baseline for the recipients of the given store
+// and commits it.
+func SaveBaseline(ctx context.Context, st Files, b *Baseline) error {
+	buf, err := b.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to serialize baseline: %w", err)
+	}
+
+	if err := st.WriteFile(ctx, BaselineFile, buf); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	if err := st.Storage().TryCommit(ctx, "Update audit baseline"); err != nil {
+		return fmt.Errorf("failed to commit baseline: %w", err)
+	}
+
+	debug.Log("Wrote audit baseline with %d entries to %s", len(b.Entries), st.Storage())
+
+	return nil
+}
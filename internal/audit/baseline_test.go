@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaselineApply(t *testing.T) {
+	t.Parallel()
+
+	rb := newReport()
+	rb.AddFinding("foo", "zxcvbn", "weak password (1 / 4)", "warning")
+	rb.AddFinding("foo", "crunchy", "ok", "none")
+	rb.AddFinding("bar", "zxcvbn", "weak password (0 / 4)", "warning")
+	rb.AddFinding("baz", "crunchy", "too short", "warning")
+	r := rb.Finalize()
+
+	bl := &Baseline{}
+	bl.Add(BaselineEntry{Secret: "foo", Finding: "zxcvbn", Justification: "legacy pin"})
+	bl.Add(BaselineEntry{Secret: "baz", Finding: "crunchy", Expires: time.Now().Add(-time.Hour)})
+
+	assert.Equal(t, 1, bl.Apply(r))
+
+	foo, bar, baz := r.Secrets["foo"], r.Secrets["bar"], r.Secrets["baz"]
+	assert.False(t, foo.HasFindings())
+	assert.True(t, bar.HasFindings())
+	assert.True(t, baz.HasFindings())
+	assert.Contains(t, r.Secrets["baz"].Findings["crunchy"].Message, "acknowledgement expired")
+
+	assert.Equal(t, []string{"bar"}, r.Findings["zxcvbn"].Elements())
+	assert.Equal(t, []string{"baz"}, r.Findings["crunchy"].Elements())
+}
+
+func TestBaselineUpdate(t *testing.T) {
+	t.Parallel()
+
+	rb := newReport()
+	rb.AddFinding("foo", "zxcvbn", "weak password (1 / 4)", "warning")
+	rb.AddFinding("bar", "crunchy", "ok", "none")
+	r := rb.Finalize()
+
+	exp := time.Now().Add(24 * time.Hour).UTC()
+	bl := &Baseline{}
+	bl.Add(BaselineEntry{Secret: "foo", Finding: "zxcvbn", Justification: "known"})
+	bl.Add(BaselineEntry{Secret: "bar", Finding: "zxcvbn", Justification: "fixed"})
+	bl.Add(BaselineEntry{Secret: "other", Finding: "zxcvbn", Justification: "not audited"})
+
+	require.NoError(t, bl.Update(r, "", exp))
+	require.Len(t, bl.Entries, 2)
+	assert.Equal(t, "foo", bl.Entries[0].Secret)
+	assert.Equal(t, "known", bl.Entries[0].Justification)
+	assert.True(t, bl.Entries[0].Expires.IsZero())
+	assert.Equal(t, "other", bl.Entries[1].Secret)
+
+	require.NoError(t, bl.Update(r, "accepted", exp))
+	e, found := bl.Lookup("foo", "zxcvbn")
+	require.True(t, found)
+	assert.Equal(t, "accepted", e.Justification)
+	assert.Equal(t, exp, e.Expires)
+}
+
+func TestBaselinePasswordChange(t *testing.T) {
+	t.Parallel()
+
+	rb := newReport()
+	rb.AddPassword("foo", "1234")
+	rb.AddFinding("foo", "zxcvbn", "weak password (0 / 4)", "warning")
+	r := rb.Finalize()
+
+	bl := &Baseline{}
+	require.NoError(t, bl.Update(r, "legacy pin", time.Time{}))
+	require.Len(t, bl.Entries, 1)
+	assert.NotEmpty(t, bl.Entries[0].Digest)
+	assert.NotContains(t, bl.Entries[0].Digest, "1234")
+	assert.Equal(t, 1, bl.Apply(r))
+
+	// the same weak password is still acknowledged.
+	rb = newReport()
+	rb.AddPassword("foo", "1234")
+	rb.AddFinding("foo", "zxcvbn", "weak password (0 / 4)", "warning")
+	assert.Equal(t, 1, bl.Apply(rb.Finalize()))
+
+	// a new password has to be acknowledged again.
+	rb = newReport()
+	rb.AddPassword("foo", "4321")
+	rb.AddFinding("foo", "zxcvbn", "weak password (0 / 4)", "warning")
+	r = rb.Finalize()
+	assert.Equal(t, 0, bl.Apply(r))
+	assert.Contains(t, r.Secrets["foo"].Findings["zxcvbn"].Message, "previous password")
+
+	d := bl.Entries[0].Digest
+	require.NoError(t, bl.Update(r, "", time.Time{}))
+	require.Len(t, bl.Entries, 1)
+	assert.NotEqual(t, d, bl.Entries[0].Digest)
+	assert.Empty(t, bl.Entries[0].Justification)
+}
+
+func TestBaselineMarshal(t *testing.T) {
+	t.Parallel()
+
+	bl := &Baseline{}
+	bl.Add(BaselineEntry{
+		Secret:        "foo",
+		Finding:       "zxcvbn",
+		Justification: "legacy pin",
+		Added:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+
+	buf, err := bl.Marshal()
+	require.NoError(t, err)
+
+	bl2, err := UnmarshalBaseline(buf)
+	require.NoError(t, err)
+	assert.Equal(t, bl, bl2)
+
+	_, err = UnmarshalBaseline([]byte("entries: foo"))
+	require.Error(t, err)
+}
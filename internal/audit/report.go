@@ -20,6 +20,9 @@ type SecretReport struct {
 	// analyzer -> finding details
 	Findings map[string]Finding
 	Age      time.Duration
+	// pwHash is the SHA256 of the password, it identifies the password that
+	// the findings refer to.
+	pwHash string
 }
 
 func (s *SecretReport) HasFindings() bool {
@@ -92,6 +95,11 @@ func (r *ReportBuilder) AddPassword(name, pw string) {
 	d.Add(name)
 	r.duplicates[s256] = d
 
+	sr := r.secrets[name]
+	sr.Name = name
+	sr.pwHash = s256
+	r.secrets[name] = sr
+
 	s1 := hashsum.SHA1Hex(pw)
 	s := r.sha1sums[s1]
 	s.Add(name)
@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/set"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"gopkg.in/yaml.v3"
+)
+
+// WorklistFile is the name of the encrypted rotation worklist in the root of a store.
+const WorklistFile = leaf.WorklistFile
+
+// WorklistEntry is a secret that needs to be rotated because it was exposed
+// to a recipient that has been removed.
+type WorklistEntry struct {
+	Secret    string    `yaml:"secret"`
+	Recipient string    `yaml:"recipient"`
+	Added     time.Time `yaml:"added"`
+	// Digest is a salted hash of the password at the time the entry was
+	// added. The entry is done once the password changes.
+	Digest string `yaml:"digest"`
+}
+
+// Done returns true if the given password differs from the one that was
+// exposed.
+func (e WorklistEntry) Done(password string) bool {
+	salt, _, found := strings.Cut(e.Digest, "$")
+	if !found {
+		return false
+	}
+
+	return digest(salt, password) != e.Digest
+}
+
+// Worklist is a list of secrets that need to be rotated after offboarding
+// one or more recipients.
+type Worklist struct {
+	Entries []WorklistEntry `yaml:"entries"`
+}
+
+// Add adds the given secret to the worklist. An existing entry for the same
+// secret is kept so the earliest exposure is retained.
+func (w *Worklist) Add(secret, recipient, password string) error {
+	for _, e := range w.Entries {
+		if e.Secret == secret {
+			return nil
+		}
+	}
+
+	d, err := saltedDigest(password)
+	if err != nil {
+		return err
+	}
+
+	w.Entries = append(w.Entries, WorklistEntry{
+		Secret:    secret,
+		Recipient: recipient,
+		Added:     time.Now().UTC(),
+		Digest:    d,
+	})
+
+	sort.Slice(w.Entries, func(i, j int) bool {
+		return w.Entries[i].Secret < w.Entries[j].Secret
+	})
+
+	return nil
+}
+
+// Remove removes the given secrets from the worklist. It returns the number
+// of removed entries.
+func (w *Worklist) Remove(secrets ...string) int {
+	rm := set.New(secrets...)
+
+	entries := make([]WorklistEntry, 0, len(w.Entries))
+	for _, e := range w.Entries {
+		if rm.Contains(e.Secret) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	n := len(w.Entries) - len(entries)
+	w.Entries = entries
+
+	return n
+}
+
+// Apply adds a finding for every entry of the worklist to the report. Only
+// secrets that are contained in the report are considered. Prefix is the
+// mount point of the store the worklist belongs to.
+func (w *Worklist) Apply(r *Report, prefix string) int {
+	if w == nil || r == nil {
+		return 0
+	}
+
+	var n int
+	for _, e := range w.Entries {
+		name := e.Secret
+		if prefix != "" {
+			name = prefix + "/" + name
+		}
+
+		sr, found := r.Secrets[name]
+		if !found {
+			continue
+		}
+
+		if sr.Findings == nil {
+			sr.Findings = make(map[string]Finding, 1)
+		}
+		sr.Findings["offboarding"] = Finding{
+			Severity: "error",
+			Message:  fmt.Sprintf("Exposed to removed recipient %s on %s. Please rotate.", e.Recipient, e.Added.Format(time.DateOnly)),
+		}
+		r.Secrets[name] = sr
+
+		ss := r.Findings["offboarding"]
+		ss.Add(name)
+		r.Findings["offboarding"] = ss
+		n++
+	}
+
+	return n
+}
+
+// saltedDigest returns the digest of the password with a new random salt.
+func saltedDigest(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return digest(hex.EncodeToString(salt), password), nil
+}
+
+func digest(salt, password string) string {
+	sum := sha256.Sum256([]byte(salt + password))
+
+	return salt + "$" + hex.EncodeToString(sum[:])
+}
+
+// Marshal serializes the worklist.
+func (w *Worklist) Marshal() ([]byte, error) {
+	return yaml.Marshal(w)
+}
+
+// UnmarshalWorklist parses a serialized worklist.
+func UnmarshalWorklist(buf []byte) (*Worklist, error) {
+	w := &Worklist{}
+	if err := yaml.Unmarshal(buf, w); err != nil {
+		return nil, fmt.Errorf("failed to parse worklist: %w", err)
+	}
+
+	return w, nil
+}
+
+// LoadWorklist reads and decrypts the rotation worklist of the given store.
+// It returns an empty worklist if none exists.
+func LoadWorklist(ctx context.Context, st Files) (*Worklist, error) {
+	buf, err := st.ReadFile(ctx, WorklistFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		debug.Log("No rotation worklist found in %s", st.Storage())
+
+		return &Worklist{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worklist: %w", err)
+	}
+
+	return UnmarshalWorklist(buf)
+}
+
+// SaveWorklist encrypts the worklist for the recipients of the given store
+// and commits it. An empty worklist is removed.
+func SaveWorklist(ctx context.Context, st Files, w *Worklist) error {
+	var buf []byte
+	if len(w.Entries) > 0 {
+		var err error
+		buf, err = w.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to serialize worklist: %w", err)
+		}
+	}
+
+	if err := st.WriteFile(ctx, WorklistFile, buf); err != nil {
+		return fmt.Errorf("failed to write worklist: %w", err)
+	}
+
+	if err := st.Storage().TryCommit(ctx, "Update rotation worklist"); err != nil {
+		return fmt.Errorf("failed to commit worklist: %w", err)
+	}
+
+	debug.Log("Wrote rotation worklist with %d entries to %s", len(w.Entries), st.Storage())
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorklist(t *testing.T) {
+	t.Parallel()
+
+	wl := &Worklist{}
+	require.NoError(t, wl.Add("foo", "0xDEADBEEF", "secret"))
+	require.NoError(t, wl.Add("bar", "0xDEADBEEF", "other"))
+	// the first exposure is kept.
+	require.NoError(t, wl.Add("foo", "0xFEEDBEEF", "changed"))
+	require.Len(t, wl.Entries, 2)
+
+	assert.Equal(t, "bar", wl.Entries[0].Secret)
+	foo := wl.Entries[1]
+	assert.Equal(t, "0xDEADBEEF", foo.Recipient)
+	assert.NotContains(t, foo.Digest, "secret")
+	assert.False(t, foo.Done("secret"))
+	assert.True(t, foo.Done("changed"))
+
+	buf, err := wl.Marshal()
+	require.NoError(t, err)
+	wl2, err := UnmarshalWorklist(buf)
+	require.NoError(t, err)
+	assert.Equal(t, wl.Entries[0].Digest, wl2.Entries[0].Digest)
+	assert.False(t, wl2.Entries[0].Done("other"))
+
+	rb := newReport()
+	rb.AddFinding("foo", "crunchy", "ok", "none")
+	rb.AddFinding("work/bar", "crunchy", "ok", "none")
+	r := rb.Finalize()
+
+	// bar is not part of the report and foo is in the wrong mount.
+	assert.Equal(t, 0, wl.Apply(r, "other"))
+	assert.Equal(t, 1, wl.Apply(r, "work"))
+	bar := r.Secrets["work/bar"]
+	assert.True(t, bar.HasFindings())
+	assert.Equal(t, []string{"work/bar"}, r.Findings["offboarding"].Elements())
+
+	assert.Equal(t, 1, wl.Remove("foo", "baz"))
+	assert.Len(t, wl.Entries, 1)
+}
@@ -7,6 +7,7 @@ type contextKey int
 const (
 	ctxKeyCryptoBackend contextKey = iota
 	ctxKeyStorageBackend
+	ctxKeyRequireSignedCommits
 )
 
 // CryptoBackendName returns the name of the given backend.
@@ -88,3 +89,17 @@ func StorageBackendName(sb StorageBackend) string {
 
 	return ""
 }
+
+// WithRequireSignedCommits returns a context with the flag to require signed
+// commits set. It is used when cloning a new store.
+func WithRequireSignedCommits(ctx context.Context, require bool) context.Context {
+	return context.WithValue(ctx, ctxKeyRequireSignedCommits, require)
+}
+
+// IsRequireSignedCommits returns the value of the require signed commits flag
+// or false.
+func IsRequireSignedCommits(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyRequireSignedCommits).(bool)
+
+	return ok && bv
+}
@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -18,6 +19,8 @@ const (
 	GPGCLI
 	// Age - age-encryption.org.
 	Age
+	// OpenPGP is a pure Go OpenPGP backend compatible with GPGCLI.
+	OpenPGP
 )
 
 func (c CryptoBackend) String() string {
@@ -59,6 +62,51 @@ type Crypto interface {
 	Concurrency() int
 }
 
+// KeyStatus is the revocation and expiry status of a key.
+type KeyStatus struct {
+	Revoked bool
+	// Expires is zero if the key does not expire.
+	Expires time.Time
+}
+
+// Expired returns true if the key has expired before now.
+func (k KeyStatus) Expired(now time.Time) bool {
+	return !k.Expires.IsZero() && k.Expires.Before(now)
+}
+
+// ExpiresWithin returns true if the key will expire within the given window.
+func (k KeyStatus) ExpiresWithin(now time.Time, window time.Duration) bool {
+	return !k.Expires.IsZero() && k.Expires.Before(now.Add(window))
+}
+
+// KeyChecker is implemented by crypto backends whose keys can expire or be
+// revoked, e.g. gpg.
+type KeyChecker interface {
+	KeyStatus(ctx context.Context, id string) (KeyStatus, error)
+}
+
+// PostQuantumChecker is implemented by crypto backends that support
+// post-quantum recipients, e.g. age.
+type PostQuantumChecker interface {
+	// IsPostQuantumRecipient returns true if the recipient uses a
+	// post-quantum key.
+	IsPostQuantumRecipient(id string) bool
+	// IsPostQuantum returns true if the ciphertext can only be decrypted
+	// with post-quantum keys.
+	IsPostQuantum(ctx context.Context, ciphertext []byte) bool
+}
+
+// Signer is implemented by crypto backends that can create and verify
+// detached signatures, e.g. gpg.
+type Signer interface {
+	// Sign returns a detached signature of data made with the given
+	// identity.
+	Sign(ctx context.Context, id string, data []byte) ([]byte, error)
+	// Verify checks a detached signature and returns the fingerprint of the
+	// signing key.
+	Verify(ctx context.Context, data, sig []byte) (string, error)
+}
+
 // NewCrypto instantiates a new crypto backend.
 func NewCrypto(ctx context.Context, id CryptoBackend) (Crypto, error) {
 	if be, err := CryptoRegistry.Get(id); err == nil {
@@ -77,7 +77,7 @@ func (l loader) Commands() []*cli.Command {
 										return exit.Error(exit.Unknown, err, "failed to read age identity")
 									}
 								}
-								if len(recEncm) < 1 && !strings.HasPrefix(idS, "AGE-SECRET-KEY-1") {
+								if len(recEncm) < 1 && !strings.HasPrefix(idS, "AGE-SECRET-KEY-1") && !strings.HasPrefix(idS, hybridIdentityPrefix) {
 									recEncm, err = termio.AskForString(ctx, "Provide the corresponding age recipient", "")
 									if err != nil || recEncm == "" {
 										return exit.Error(exit.Unknown, err, "failed to read corresponding age recipient")
@@ -108,9 +108,19 @@ func (l loader) Commands() []*cli.Command {
 							Name:  "keygen",
 							Usage: "Generate a new age identity",
 							Description: "" +
-								"Generate a new age identity",
+								"Generate a new age identity. With --post-quantum it generates a hybrid " +
+								"ML-KEM-768 + X25519 identity.",
+							Flags: []cli.Flag{
+								&cli.BoolFlag{
+									Name:  "post-quantum",
+									Usage: "Generate a hybrid post-quantum identity (age1pq1...)",
+								},
+							},
 							Action: func(c *cli.Context) error {
 								ctx := ctxutil.WithGlobalFlags(c)
+								if c.Bool("post-quantum") {
+									ctx = WithPostQuantum(ctx, true)
+								}
 								a, err := New(ctx)
 								if err != nil {
 									return exit.Error(exit.Unknown, err, "failed to create age backend")
@@ -158,6 +168,12 @@ func (l loader) Commands() []*cli.Command {
 										if x.Recipient().String() == victim {
 											debug.Log("will remove X25519Identity %s", x.Recipient())
 
+											continue
+										}
+									case *age.HybridIdentity:
+										if x.Recipient().String() == victim {
+											debug.Log("will remove HybridIdentity %s", x.Recipient())
+
 											continue
 										}
 									case *wrappedIdentity:
@@ -6,6 +6,7 @@ type contextKey int
 
 const (
 	ctxKeyOnlyNative contextKey = iota
+	ctxKeyPostQuantum
 )
 
 // WithOnlyNative will return a context with the flag for only native set.
@@ -23,3 +24,20 @@ func IsOnlyNative(ctx context.Context) bool {
 
 	return bv
 }
+
+// WithPostQuantum will return a context with the flag for post-quantum
+// identities set.
+func WithPostQuantum(ctx context.Context, pq bool) context.Context {
+	return context.WithValue(ctx, ctxKeyPostQuantum, pq)
+}
+
+// IsPostQuantum will return the value of the post-quantum flag or the
+// default (false).
+func IsPostQuantum(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyPostQuantum).(bool)
+	if !ok {
+		return false
+	}
+
+	return bv
+}
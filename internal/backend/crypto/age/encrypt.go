@@ -29,7 +29,7 @@ func (a *Age) Encrypt(ctx context.Context, plaintext []byte, recipients []string
 	// dedupe also order recipients so that native ones are first
 	recp = dedupe(append(recp, idRecps...))
 
-	return a.encrypt(plaintext, recp...)
+	return a.encrypt(plaintext, allowMixedRecipients(recp)...)
 }
 
 // dedupe the recipients, only works for native age recipients.
@@ -0,0 +1,96 @@
+package age
+
+import (
+	"context"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Hybrid post-quantum recipients combine ML-KEM-768 and X25519, so a file
+// key stays protected as long as either of them is not broken. They are
+// provided by age itself.
+const (
+	hybridRecipientPrefix = "age1pq"
+	hybridIdentityPrefix  = "AGE-SECRET-KEY-PQ-"
+	hybridStanzaType      = "mlkem768x25519"
+)
+
+// classicalHybridRecipient wraps a hybrid recipient so it can be mixed with
+// classical recipients. age refuses such mixes since the file key is only as
+// safe as its weakest stanza. We still want them while a store is being
+// moved to post-quantum recipients, fsck reports the affected secrets.
+type classicalHybridRecipient struct {
+	r *age.HybridRecipient
+}
+
+// Wrap implements age.Recipient, but not age.RecipientWithLabels.
+func (c classicalHybridRecipient) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	return c.r.Wrap(fileKey)
+}
+
+// String returns the encoding of the hybrid recipient.
+func (c classicalHybridRecipient) String() string {
+	return c.r.String()
+}
+
+// allowMixedRecipients wraps the hybrid recipients if there are any classical
+// ones, so age doesn't reject the combination.
+func allowMixedRecipients(recp []age.Recipient) []age.Recipient {
+	var mixed bool
+	for _, r := range recp {
+		if _, ok := r.(*age.HybridRecipient); !ok {
+			mixed = true
+
+			break
+		}
+	}
+	if !mixed {
+		return recp
+	}
+
+	out := make([]age.Recipient, 0, len(recp))
+	for _, r := range recp {
+		if hr, ok := r.(*age.HybridRecipient); ok {
+			debug.Log("mixing hybrid recipient %s with classical recipients", hr)
+			r = classicalHybridRecipient{r: hr}
+		}
+		out = append(out, r)
+	}
+
+	return out
+}
+
+// IsPostQuantumRecipient returns true for hybrid post-quantum recipients.
+func (a *Age) IsPostQuantumRecipient(id string) bool {
+	return strings.HasPrefix(id, hybridRecipientPrefix+"1")
+}
+
+// IsPostQuantum returns true if the file key of the ciphertext is only
+// wrapped for hybrid post-quantum recipients.
+func (a *Age) IsPostQuantum(ctx context.Context, ciphertext []byte) bool {
+	stanzas, _, _, _, err := parseHeader(ciphertext)
+	if err != nil {
+		debug.Log("failed to parse age header: %s", err)
+
+		return false
+	}
+
+	return isPostQuantum(stanzas)
+}
+
+// isPostQuantum returns true if all stanzas use hybrid recipients.
+func isPostQuantum(stanzas []*age.Stanza) bool {
+	if len(stanzas) < 1 {
+		return false
+	}
+
+	for _, s := range stanzas {
+		if s.Type != hybridStanzaType {
+			return false
+		}
+	}
+
+	return true
+}
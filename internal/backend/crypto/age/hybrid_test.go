@@ -0,0 +1,134 @@
+package age
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptTo(t *testing.T, plaintext []byte, recipients ...age.Recipient) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w, err := age.Encrypt(buf, recipients...)
+	require.NoError(t, err)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func decryptWith(t *testing.T, ciphertext []byte, ids ...age.Identity) ([]byte, error) {
+	t.Helper()
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func TestHybridRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a := &Age{}
+
+	alice, err := age.GenerateHybridIdentity()
+	require.NoError(t, err)
+	bob, err := age.GenerateHybridIdentity()
+	require.NoError(t, err)
+	carol, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	plaintext := []byte("secret")
+
+	t.Run("hybrid only", func(t *testing.T) {
+		t.Parallel()
+
+		ciphertext := encryptTo(t, plaintext, alice.Recipient(), bob.Recipient())
+		assert.True(t, a.IsPostQuantum(ctx, ciphertext))
+
+		for _, id := range []age.Identity{alice, bob} {
+			got, err := decryptWith(t, ciphertext, id)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, got)
+		}
+
+		_, err := decryptWith(t, ciphertext, carol)
+		require.Error(t, err)
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		t.Parallel()
+
+		// age refuses to mix them unless we hide the post-quantum label.
+		_, err := age.Encrypt(io.Discard, alice.Recipient(), carol.Recipient())
+		require.Error(t, err)
+
+		ciphertext := encryptTo(t, plaintext, allowMixedRecipients([]age.Recipient{alice.Recipient(), carol.Recipient()})...)
+		assert.False(t, a.IsPostQuantum(ctx, ciphertext))
+
+		for _, id := range []age.Identity{alice, carol} {
+			got, err := decryptWith(t, ciphertext, id)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, got)
+		}
+
+		_, err = decryptWith(t, ciphertext, bob)
+		require.Error(t, err)
+	})
+
+	t.Run("classical only", func(t *testing.T) {
+		t.Parallel()
+
+		ciphertext := encryptTo(t, plaintext, carol.Recipient())
+		assert.False(t, a.IsPostQuantum(ctx, ciphertext))
+		assert.False(t, a.IsPostQuantum(ctx, []byte("not an age file")))
+	})
+}
+
+func TestHybridEncoding(t *testing.T) {
+	t.Parallel()
+
+	a := &Age{}
+
+	id, err := age.GenerateHybridIdentity()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(id.String(), "AGE-SECRET-KEY-PQ-1"))
+	assert.True(t, strings.HasPrefix(id.Recipient().String(), "age1pq1"))
+	assert.True(t, a.IsPostQuantumRecipient(id.Recipient().String()))
+	assert.False(t, a.IsPostQuantumRecipient("age1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq"))
+
+	// identities round trip through our keyring format.
+	pid, err := parseIdentity(id.String())
+	require.NoError(t, err)
+	assert.Equal(t, id.Recipient().String(), IdentityToRecipient(pid).(*age.HybridRecipient).String())
+
+	pid, err = parseIdentity(id.String() + "|" + id.Recipient().String())
+	require.NoError(t, err)
+	assert.Equal(t, id.String(), pid.(*age.HybridIdentity).String())
+
+	rs, err := (&Age{}).parseRecipients(context.Background(), []string{id.Recipient().String()})
+	require.NoError(t, err)
+	require.Len(t, rs, 1)
+	assert.IsType(t, &age.HybridRecipient{}, rs[0])
+
+	// classical recipients stay as they are, even when mixed.
+	carol, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	mixed := allowMixedRecipients([]age.Recipient{id.Recipient(), carol.Recipient()})
+	assert.IsType(t, classicalHybridRecipient{}, mixed[0])
+	assert.Equal(t, id.Recipient().String(), mixed[0].(fmt.Stringer).String())
+	assert.Equal(t, carol.Recipient(), mixed[1])
+}
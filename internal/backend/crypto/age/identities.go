@@ -16,6 +16,7 @@ import (
 	"filippo.io/age"
 	"filippo.io/age/agessh"
 	"filippo.io/age/plugin"
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/pkg/appdir"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -134,6 +135,10 @@ func parseIdentity(s string) (age.Identity, error) {
 			encoding: s,
 			rec:      rec,
 		}, nil
+	case strings.HasPrefix(s, hybridIdentityPrefix):
+		sp := strings.Split(s, "|")
+
+		return age.ParseHybridIdentity(sp[0])
 	case strings.HasPrefix(s, "AGE-SECRET-KEY-1"):
 		sp := strings.Split(s, "|")
 
@@ -212,6 +217,10 @@ func IdentityToRecipient(id age.Identity) age.Recipient {
 	case *age.X25519Identity:
 		debug.Log("parsed age identity as X25519Identity")
 
+		return id.Recipient()
+	case *age.HybridIdentity:
+		debug.Log("parsed age identity as HybridIdentity")
+
 		return id.Recipient()
 	case *wrappedIdentity:
 		debug.Log("parsed age identity as wrappedIdentity")
@@ -252,6 +261,16 @@ func (a *Age) GenerateIdentity(ctx context.Context, _ string, _ string, pw strin
 		})
 	}
 
+	if IsPostQuantum(ctx) || config.Bool(ctx, "age.post-quantum") {
+		debug.Log("age GenerateIdentity using a hybrid post-quantum identity")
+		id, err := age.GenerateHybridIdentity()
+		if err != nil {
+			return err
+		}
+
+		return a.addIdentity(ctx, id)
+	}
+
 	id, err := age.GenerateX25519Identity()
 	if err != nil {
 		return err
@@ -461,6 +480,10 @@ func idMap(ids []age.Identity) map[string]age.Identity {
 		case *age.X25519Identity:
 			m[i.Recipient().String()] = id
 
+			continue
+		case *age.HybridIdentity:
+			m[i.Recipient().String()] = id
+
 			continue
 		case *wrappedIdentity:
 			m[i.String()] = id
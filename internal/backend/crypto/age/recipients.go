@@ -50,6 +50,15 @@ func (a *Age) parseRecipients(ctx context.Context, recipients []string) ([]age.R
 	ret := make([]age.Recipient, 0, len(recipients))
 	for _, r := range recipients {
 		switch {
+		case strings.HasPrefix(r, hybridRecipientPrefix+"1"):
+			id, err := age.ParseHybridRecipient(r)
+			if err != nil {
+				debug.Log("Failed to parse recipient %q as hybrid: %s", r, err)
+
+				continue
+			}
+			ret = append(ret, id)
+
 		case strings.HasPrefix(r, "age1"):
 			id, err := age.ParseX25519Recipient(r)
 			if err != nil {
@@ -0,0 +1,239 @@
+package age
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"golang.org/x/crypto/hkdf"
+)
+
+// The age header is parsed and written here because the age package does
+// not export its format. See https://age-encryption.org/v1.
+const (
+	headerIntro  = "age-encryption.org/v1\n"
+	stanzaPrefix = "->"
+	footerPrefix = "---"
+	// bytesPerLine is the number of decoded bytes in a full stanza body line.
+	bytesPerLine = 48
+)
+
+var b64 = base64.RawStdEncoding.Strict()
+
+// Rewrap re-encrypts the file key of the given ciphertext for a new set of
+// recipients. The payload is not decrypted and stays the same, so anyone who
+// could decrypt the file before can still decrypt it if they have kept the
+// old file key.
+func (a *Age) Rewrap(ctx context.Context, ciphertext []byte, recipients []string) ([]byte, error) {
+	if !ctxutil.HasPasswordCallback(ctx) {
+		debug.Log("no password callback found, redirecting to askPass")
+		ctx = ctxutil.WithPasswordCallback(ctx, func(prompt string, _ bool) ([]byte, error) {
+			pw, err := a.askPass.Passphrase(prompt, fmt.Sprintf("to load the keyring at %s", a.identity), false)
+
+			return []byte(pw), err
+		})
+		ctx = ctxutil.WithPasswordPurgeCallback(ctx, a.askPass.Remove)
+	}
+
+	stanzas, hdr, mac, payload, err := parseHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := a.getAllIds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fileKey, err := unwrapFileKey(stanzas, hdr, mac, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	idRecps, err := a.IdentityRecipients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch identity recipients for encryption: %w", err)
+	}
+	recp, err := a.parseRecipients(ctx, recipients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recipients file for encryption: %w", err)
+	}
+	recp = allowMixedRecipients(dedupe(append(recp, idRecps...)))
+
+	newStanzas, err := wrapFileKey(fileKey, recp)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	if err := marshalHeader(out, newStanzas, fileKey); err != nil {
+		return nil, err
+	}
+	out.Write(payload)
+
+	debug.Log("Rewrapped file key for %d recipients (%d stanzas before, %d after)", len(recp), len(stanzas), len(newStanzas))
+
+	return out.Bytes(), nil
+}
+
+// unwrapFileKey tries all identities and verifies the header MAC with the
+// file key that was found.
+func unwrapFileKey(stanzas []*age.Stanza, hdr, mac []byte, ids []age.Identity) ([]byte, error) {
+	for _, id := range ids {
+		fileKey, err := id.Unwrap(stanzas)
+		if errors.Is(err, age.ErrIncorrectIdentity) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		want, err := headerMAC(fileKey, hdr)
+		if err != nil {
+			return nil, err
+		}
+		if !hmac.Equal(want, mac) {
+			return nil, fmt.Errorf("bad header MAC")
+		}
+
+		return fileKey, nil
+	}
+
+	return nil, &age.NoIdentityMatchError{}
+}
+
+func wrapFileKey(fileKey []byte, recp []age.Recipient) ([]*age.Stanza, error) {
+	var stanzas []*age.Stanza
+	var labels []string
+	for i, r := range recp {
+		var s []*age.Stanza
+		var l []string
+		var err error
+		if rl, ok := r.(age.RecipientWithLabels); ok {
+			s, l, err = rl.WrapWithLabels(fileKey)
+		} else {
+			s, err = r.Wrap(fileKey)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap key for recipient #%d: %w", i, err)
+		}
+
+		slices.Sort(l)
+		if i == 0 {
+			labels = l
+		} else if !slices.Equal(labels, l) {
+			return nil, fmt.Errorf("incompatible recipients")
+		}
+		stanzas = append(stanzas, s...)
+	}
+
+	return stanzas, nil
+}
+
+// parseHeader splits an age file into its recipient stanzas, the header
+// covered by the MAC, the MAC itself and the payload.
+func parseHeader(buf []byte) ([]*age.Stanza, []byte, []byte, []byte, error) {
+	if !bytes.HasPrefix(buf, []byte(headerIntro)) {
+		return nil, nil, nil, nil, fmt.Errorf("not an age file")
+	}
+
+	var stanzas []*age.Stanza
+	var cur *age.Stanza
+	pos := len(headerIntro)
+	for {
+		end := bytes.IndexByte(buf[pos:], '\n')
+		if end < 0 {
+			return nil, nil, nil, nil, fmt.Errorf("unexpected end of header")
+		}
+		line := string(buf[pos : pos+end])
+
+		switch {
+		case cur != nil:
+			b, err := b64.DecodeString(line)
+			if err != nil || len(b) > bytesPerLine {
+				return nil, nil, nil, nil, fmt.Errorf("malformed stanza body %q", line)
+			}
+			cur.Body = append(cur.Body, b...)
+			// a stanza body always ends with a short line.
+			if len(b) < bytesPerLine {
+				stanzas = append(stanzas, cur)
+				cur = nil
+			}
+		case strings.HasPrefix(line, stanzaPrefix+" "):
+			args := strings.Split(line, " ")[1:]
+			if len(args) < 1 {
+				return nil, nil, nil, nil, fmt.Errorf("malformed stanza %q", line)
+			}
+			cur = &age.Stanza{Type: args[0], Args: args[1:]}
+		case strings.HasPrefix(line, footerPrefix+" "):
+			mac, err := b64.DecodeString(strings.TrimPrefix(line, footerPrefix+" "))
+			if err != nil || len(mac) != sha256.Size {
+				return nil, nil, nil, nil, fmt.Errorf("malformed header MAC %q", line)
+			}
+			hdr := buf[:pos+len(footerPrefix)]
+
+			return stanzas, hdr, mac, buf[pos+end+1:], nil
+		default:
+			return nil, nil, nil, nil, fmt.Errorf("malformed header line %q", line)
+		}
+
+		pos += end + 1
+	}
+}
+
+// marshalHeader writes a complete header for the given stanzas.
+func marshalHeader(w io.Writer, stanzas []*age.Stanza, fileKey []byte) error {
+	hdr := &bytes.Buffer{}
+	hdr.WriteString(headerIntro)
+	for _, s := range stanzas {
+		hdr.WriteString(stanzaPrefix)
+		for _, a := range append([]string{s.Type}, s.Args...) {
+			hdr.WriteString(" " + a)
+		}
+		hdr.WriteByte('\n')
+
+		// full lines are followed by a short, possibly empty, one.
+		body := s.Body
+		for len(body) >= bytesPerLine {
+			hdr.WriteString(b64.EncodeToString(body[:bytesPerLine]) + "\n")
+			body = body[bytesPerLine:]
+		}
+		hdr.WriteString(b64.EncodeToString(body) + "\n")
+	}
+	hdr.WriteString(footerPrefix)
+
+	mac, err := headerMAC(fileKey, hdr.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(hdr.Bytes()); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, " %s\n", b64.EncodeToString(mac))
+
+	return err
+}
+
+func headerMAC(fileKey, hdr []byte) ([]byte, error) {
+	h := hkdf.New(sha256.New, fileKey, nil, []byte("header"))
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(h, key); err != nil {
+		return nil, err
+	}
+
+	hh := hmac.New(sha256.New, key)
+	hh.Write(hdr)
+
+	return hh.Sum(nil), nil
+}
@@ -0,0 +1,97 @@
+package age
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrap(t *testing.T) {
+	t.Parallel()
+
+	alice, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	bob, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	// a large plaintext so the payload spans several chunks.
+	plaintext := bytes.Repeat([]byte("secret"), 20000)
+
+	buf := &bytes.Buffer{}
+	w, err := age.Encrypt(buf, alice.Recipient())
+	require.NoError(t, err)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	ciphertext := buf.Bytes()
+
+	stanzas, hdr, mac, payload, err := parseHeader(ciphertext)
+	require.NoError(t, err)
+	require.Len(t, stanzas, 1)
+
+	fileKey, err := unwrapFileKey(stanzas, hdr, mac, []age.Identity{bob, alice})
+	require.NoError(t, err)
+
+	t.Run("header roundtrip", func(t *testing.T) {
+		t.Parallel()
+
+		out := &bytes.Buffer{}
+		require.NoError(t, marshalHeader(out, stanzas, fileKey))
+		assert.Equal(t, ciphertext[:len(ciphertext)-len(payload)], out.Bytes())
+	})
+
+	t.Run("unknown identity", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := unwrapFileKey(stanzas, hdr, mac, []age.Identity{bob})
+		require.Error(t, err)
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		t.Parallel()
+
+		bad := append([]byte{}, mac...)
+		bad[0] ^= 0xff
+		_, err := unwrapFileKey(stanzas, hdr, bad, []age.Identity{alice})
+		require.Error(t, err)
+	})
+
+	newStanzas, err := wrapFileKey(fileKey, []age.Recipient{bob.Recipient()})
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, marshalHeader(out, newStanzas, fileKey))
+	out.Write(payload)
+	rewrapped := out.Bytes()
+
+	// the payload is left untouched.
+	assert.True(t, bytes.HasSuffix(rewrapped, payload))
+
+	r, err := age.Decrypt(bytes.NewReader(rewrapped), bob)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	_, err = age.Decrypt(bytes.NewReader(rewrapped), alice)
+	require.Error(t, err)
+}
+
+func TestParseHeaderInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{
+		"",
+		"not an age file",
+		"age-encryption.org/v1\n-> X25519 foo\n",
+		"age-encryption.org/v1\n--- invalid\n",
+		"age-encryption.org/v1\nfoo\n--- AAAA\n",
+	} {
+		_, _, _, _, err := parseHeader([]byte(in))
+		require.Error(t, err, in)
+	}
+}
@@ -77,7 +77,7 @@ func New(ctx context.Context, cfg Config) (*GPG, error) {
 
 	bin, err := gpgconf.Binary(ctx, cfg.Binary)
 	if err != nil {
-		return nil, fmt.Errorf("failed to detect binary: %w", err)
+		return nil, fmt.Errorf("failed to detect binary: %w. Set openpgp.prefer to use the native openpgp backend instead", err)
 	}
 
 	g.binary = bin
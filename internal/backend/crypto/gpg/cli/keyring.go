@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg/colons"
 	"github.com/gopasspw/gopass/internal/out"
@@ -56,6 +57,25 @@ func (g *GPG) Fingerprint(ctx context.Context, id string) string {
 	return k.Fingerprint
 }
 
+// KeyStatus returns the revocation and expiry status of the given public key.
+// Unlike FindRecipients this includes keys that are not useable anymore.
+func (g *GPG) KeyStatus(ctx context.Context, id string) (backend.KeyStatus, error) {
+	kl, err := g.listKeys(ctx, "public", id)
+	if err != nil {
+		return backend.KeyStatus{}, err
+	}
+
+	k, err := kl.FindKey(id)
+	if err != nil {
+		return backend.KeyStatus{}, err
+	}
+
+	return backend.KeyStatus{
+		Revoked: k.IsRevoked(),
+		Expires: k.ExpirationDate,
+	}, nil
+}
+
 // FormatKey formats the details of a key id
 // Examples:
 // - NameFromKey: {{ .Name }}
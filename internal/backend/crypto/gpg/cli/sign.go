@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Sign creates an armored detached signature of data with the given key.
+func (g *GPG) Sign(ctx context.Context, id string, data []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	args := append(g.args, "--armor", "--local-user", id, "--detach-sign")
+	cmd := exec.CommandContext(ctx, g.binary, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = io.MultiWriter(os.Stderr, debug.LogWriter)
+
+	debug.V(1).Log("%s %+v", cmd.Path, cmd.Args)
+	sig, err := cmd.Output()
+	if err != nil {
+		debug.Log("GPG sign failed: %s %+v: %+v", cmd.Path, cmd.Args, err)
+
+		return nil, fmt.Errorf("failed to sign with %s: %w", id, err)
+	}
+
+	return sig, nil
+}
+
+// Verify checks a detached signature of data and returns the fingerprint of
+// the primary key that made it. Signatures made by expired or revoked keys
+// are rejected.
+func (g *GPG) Verify(ctx context.Context, data, sig []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	// gpg only reads either the signature or the data from stdin.
+	fh, err := os.CreateTemp("", "gopass-sig-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(fh.Name())
+	}()
+
+	if _, err := fh.Write(sig); err != nil {
+		_ = fh.Close()
+
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	if err := fh.Close(); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	args := append(g.args, "--status-fd=1", "--verify", fh.Name(), "-")
+	cmd := exec.CommandContext(ctx, g.binary, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = debug.LogWriter
+
+	debug.V(1).Log("%s %+v", cmd.Path, cmd.Args)
+	status, err := cmd.Output()
+	if err != nil {
+		debug.Log("GPG verify failed: %s %+v: %+v", cmd.Path, cmd.Args, err)
+
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return parseVerifyStatus(status)
+}
+
+// parseVerifyStatus returns the primary key fingerprint from the VALIDSIG
+// line of the gpg status output. It requires a GOODSIG line as well, which
+// gpg omits for expired or revoked keys.
+func parseVerifyStatus(status []byte) (string, error) {
+	var good bool
+	var fp string
+
+	s := bufio.NewScanner(bytes.NewReader(status))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+
+		switch fields[1] {
+		case "GOODSIG":
+			good = true
+		case "VALIDSIG":
+			fp = fields[2]
+			if len(fields) > 11 {
+				fp = fields[11]
+			}
+		}
+	}
+
+	if !good || fp == "" {
+		return "", fmt.Errorf("no valid signature found")
+	}
+
+	return fp, nil
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVerifyStatus(t *testing.T) {
+	t.Parallel()
+
+	// signed by a subkey, the last field is the primary key.
+	fp, err := parseVerifyStatus([]byte(`[GNUPG:] NEWSIG
+[GNUPG:] KEY_CONSIDERED 5F6B1B0E2A4E0C1D4A8F8D9B3C2E1F0A9B8C7D6E 0
+[GNUPG:] SIG_ID abcdef 2024-01-01 1704067200
+[GNUPG:] GOODSIG 3C2E1F0A9B8C7D6E Alice <alice@example.org>
+[GNUPG:] VALIDSIG 1111111111111111111111111111111111111111 2024-01-01 1704067200 0 4 0 22 10 00 5F6B1B0E2A4E0C1D4A8F8D9B3C2E1F0A9B8C7D6E
+[GNUPG:] TRUST_ULTIMATE 0 pgp
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "5F6B1B0E2A4E0C1D4A8F8D9B3C2E1F0A9B8C7D6E", fp)
+
+	// expired keys only report EXPKEYSIG.
+	_, err = parseVerifyStatus([]byte(`[GNUPG:] NEWSIG
+[GNUPG:] EXPKEYSIG 3C2E1F0A9B8C7D6E Alice <alice@example.org>
+[GNUPG:] VALIDSIG 5F6B1B0E2A4E0C1D4A8F8D9B3C2E1F0A9B8C7D6E 2024-01-01 1704067200 0 4 0 22 10 00 5F6B1B0E2A4E0C1D4A8F8D9B3C2E1F0A9B8C7D6E
+`))
+	require.Error(t, err)
+
+	_, err = parseVerifyStatus([]byte(`[GNUPG:] BADSIG 3C2E1F0A9B8C7D6E Alice <alice@example.org>`))
+	require.Error(t, err)
+}
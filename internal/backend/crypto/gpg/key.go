@@ -35,7 +35,7 @@ func (k Key) IsUseable(alwaysTrust bool) bool {
 		return false
 	}
 
-	if !k.Caps.Encrypt {
+	if !k.Caps.Encrypt || k.IsRevoked() {
 		return false
 	}
 
@@ -59,6 +59,11 @@ func (k Key) IsUseable(alwaysTrust bool) bool {
 	return false
 }
 
+// IsRevoked returns true if the key has been revoked.
+func (k Key) IsRevoked() bool {
+	return k.Validity == "r"
+}
+
 // String implement fmt.Stringer. This method produces output that is close to, but
 // not exactly the same, as the output form GPG itself.
 func (k Key) String() string {
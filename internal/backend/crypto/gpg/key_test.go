@@ -125,6 +125,11 @@ func TestUseability(t *testing.T) {
 			ExpirationDate: time.Now().Add(time.Hour),
 			Caps:           Capabilities{Deactivated: true},
 		},
+		{
+			ExpirationDate: time.Now().Add(time.Hour),
+			Caps:           Capabilities{Encrypt: true},
+			Validity:       "r",
+		},
 		{
 			ExpirationDate: time.Now().Add(time.Hour),
 			Caps:           Capabilities{Encrypt: false},
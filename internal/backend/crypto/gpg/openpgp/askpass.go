@@ -0,0 +1,72 @@
+package openpgp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/cache"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/pinentry/cli"
+	"github.com/twpayne/go-pinentry/v4"
+)
+
+type askPass struct {
+	cache *cache.InMemTTL[string, string]
+}
+
+func newAskPass() *askPass {
+	return &askPass{
+		cache: cache.NewInMemTTL[string, string](time.Hour, 24*time.Hour),
+	}
+}
+
+// Passphrase returns the passphrase for the given key, either from the cache
+// or by asking the user.
+func (a *askPass) Passphrase(key string, reason string) (string, error) {
+	if value, found := a.cache.Get(key); found {
+		debug.V(1).Log("Read value for %s from cache", key)
+
+		return value, nil
+	}
+	debug.Log("Value for %s not found in cache", key)
+
+	pw, err := a.getPassphrase(reason)
+	if err != nil {
+		return "", fmt.Errorf("pinentry error: %w", err)
+	}
+
+	a.cache.Set(key, pw)
+
+	return pw, nil
+}
+
+func (a *askPass) getPassphrase(reason string) (string, error) {
+	p, err := pinentry.NewClient(
+		pinentry.WithBinaryNameFromGnuPGAgentConf(),
+		pinentry.WithDesc(strings.TrimSuffix(reason, ":")+"."),
+		pinentry.WithGPGTTY(),
+		pinentry.WithPrompt("Passphrase:"),
+		pinentry.WithTitle("gopass"),
+	)
+	if err != nil {
+		debug.Log("Pinentry not found: %q", err)
+
+		return cli.New().GetPIN()
+	}
+	defer func() {
+		_ = p.Close()
+	}()
+
+	result, err := p.GetPIN()
+	if err != nil {
+		return "", fmt.Errorf("pinentry error: %w", err)
+	}
+
+	return result.PIN, nil
+}
+
+// Remove drops the cached passphrase of key.
+func (a *askPass) Remove(key string) {
+	a.cache.Remove(key)
+}
@@ -0,0 +1,128 @@
+package openpgp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// maxAttempts is the number of wrong passphrases we accept before giving up.
+const maxAttempts = 3
+
+// Decrypt will try to decrypt the given file. Encrypted private keys are
+// unlocked with a passphrase, which is cached until Lock is called.
+func (o *OpenPGP) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	ctx = o.withAskPass(ctx)
+
+	sec, err := o.secretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var attempts int
+	prompt := func(keys []openpgp.Key, _ bool) ([]byte, error) {
+		if len(keys) < 1 {
+			return nil, fmt.Errorf("no secret key found")
+		}
+
+		fp := fingerprint(keys[0].Entity)
+		if attempts > 0 {
+			ctxutil.GetPasswordPurgeCallback(ctx)(fp)
+		}
+		if attempts >= maxAttempts {
+			return nil, fmt.Errorf("failed to unlock key %s: wrong passphrase", fp)
+		}
+		attempts++
+
+		pw, err := ctxutil.GetPasswordCallback(ctx)(fp, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := keys[0].Entity.DecryptPrivateKeys(pw); err != nil {
+			debug.Log("failed to unlock key %s: %s", fp, err)
+		}
+
+		return nil, nil
+	}
+
+	md, err := openpgp.ReadMessage(bytes.NewReader(ciphertext), sec, prompt, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plaintext: %w", err)
+	}
+	debug.Log("Decrypted %d bytes of ciphertext to %d bytes of plaintext", len(ciphertext), len(plaintext))
+
+	return plaintext, nil
+}
+
+// withAskPass uses askPass to get passphrases unless the context already
+// has a password callback.
+func (o *OpenPGP) withAskPass(ctx context.Context) context.Context {
+	if ctxutil.HasPasswordCallback(ctx) {
+		return ctx
+	}
+
+	debug.Log("no password callback found, using askPass")
+	ctx = ctxutil.WithPasswordCallback(ctx, func(prompt string, _ bool) ([]byte, error) {
+		pw, err := o.askPass.Passphrase(prompt, fmt.Sprintf("Enter the passphrase of the OpenPGP key %s", prompt))
+
+		return []byte(pw), err
+	})
+
+	return ctxutil.WithPasswordPurgeCallback(ctx, o.askPass.Remove)
+}
+
+// RecipientIDs returns the fingerprints of the recipients of the given
+// message. Recipients that are not in any keyring are returned as key ids.
+func (o *OpenPGP) RecipientIDs(ctx context.Context, ciphertext []byte) ([]string, error) {
+	el, err := o.allKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	recp := make([]string, 0, 5)
+	packets := packet.NewReader(bytes.NewReader(ciphertext))
+	for {
+		p, err := packets.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return recp, nil
+			}
+
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+
+		ek, ok := p.(*packet.EncryptedKey)
+		if !ok {
+			// the key packets are always at the start of the message.
+			return recp, nil
+		}
+
+		if keys := el.KeysById(ek.KeyId); len(keys) > 0 {
+			recp = append(recp, fingerprint(keys[0].Entity))
+
+			continue
+		}
+
+		id := fmt.Sprintf("0x%016X", ek.KeyId)
+		if len(ek.KeyFingerprint) > 0 {
+			id = strings.ToUpper(hex.EncodeToString(ek.KeyFingerprint))
+		}
+		debug.Log("unknown recipient %s", id)
+		recp = append(recp, id)
+	}
+}
@@ -0,0 +1,62 @@
+package openpgp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Encrypt will encrypt the given content for the recipients. The output is
+// a binary OpenPGP message that gpg can decrypt.
+func (o *OpenPGP) Encrypt(ctx context.Context, plaintext []byte, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("recipients list is empty!")
+	}
+
+	sec, err := o.secretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	el, err := o.allKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	to := make(openpgp.EntityList, 0, len(recipients))
+	for _, r := range recipients {
+		found := find(el, r)
+		if len(found) < 1 || !toKey(found[0], sec).IsUseable(gpg.IsAlwaysTrust(ctx)) {
+			errmsg := fmt.Sprintf("Not using invalid key %q for encryption. Check its expiration date, its encryption capabilities and trust.", r)
+			debug.Log(errmsg)
+			out.Printf(ctx, errmsg)
+
+			continue
+		}
+		debug.Log("adding recipient %s", r)
+		to = append(to, found[0])
+	}
+	if len(to) < 1 {
+		return nil, errors.New("no valid and trusted recipients were found!")
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := openpgp.Encrypt(buf, to, nil, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("failed to write plaintext: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish encryption: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package openpgp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// GenerateIdentity creates a new key pair and adds it to the secret keyring.
+// The private key is protected with the passphrase, if one is given.
+func (o *OpenPGP) GenerateIdentity(ctx context.Context, name, email, passphrase string) error {
+	e, err := openpgp.NewEntity(name, "", email, nil)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	if passphrase != "" {
+		if err := e.EncryptPrivateKeys([]byte(passphrase), nil); err != nil {
+			return fmt.Errorf("failed to encrypt private key: %w", err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := e.SerializePrivateWithoutSigning(buf, nil); err != nil {
+		return fmt.Errorf("failed to serialize key: %w", err)
+	}
+
+	// the secret keyring is only ever appended to so we never write
+	// private keys that were unlocked in memory.
+	if err := os.MkdirAll(filepath.Dir(o.secring), 0o700); err != nil {
+		return err
+	}
+	fh, err := os.OpenFile(o.secring, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close() //nolint:errcheck
+
+	if _, err := fh.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write secret keyring %s: %w", o.secring, err)
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	debug.Log("generated key %s for %s <%s>", fingerprint(e), name, email)
+
+	o.secKeys = nil
+
+	return nil
+}
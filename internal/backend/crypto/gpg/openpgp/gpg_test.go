@@ -0,0 +1,160 @@
+package openpgp
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gpgHome runs gpg with a temporary home directory.
+type gpgHome struct {
+	t   *testing.T
+	dir string
+}
+
+func newGPGHome(t *testing.T) *gpgHome {
+	t.Helper()
+
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not found")
+	}
+
+	// gpg-agent needs a short path for its socket.
+	dir, err := os.MkdirTemp("", "gpg")
+	require.NoError(t, err)
+	require.NoError(t, os.Chmod(dir, 0o700))
+
+	g := &gpgHome{t: t, dir: dir}
+	t.Cleanup(func() {
+		cmd := exec.Command("gpgconf", "--kill", "gpg-agent")
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+dir)
+		_ = cmd.Run()
+		_ = os.RemoveAll(dir)
+	})
+
+	return g
+}
+
+func (g *gpgHome) run(stdin []byte, args ...string) []byte {
+	g.t.Helper()
+
+	args = append([]string{"--batch", "--no-tty", "--pinentry-mode", "loopback", "--passphrase", ""}, args...)
+	cmd := exec.Command("gpg", args...)
+	cmd.Env = append(os.Environ(), "GNUPGHOME="+g.dir)
+	cmd.Stdin = bytes.NewReader(stdin)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	buf, err := cmd.Output()
+	require.NoError(g.t, err, "gpg %s: %s", strings.Join(args, " "), stderr.String())
+
+	return buf
+}
+
+func (g *gpgHome) fingerprint(id string) string {
+	g.t.Helper()
+
+	for _, line := range strings.Split(string(g.run(nil, "--with-colons", "--list-keys", id)), "\n") {
+		if fields := strings.Split(line, ":"); len(fields) > 9 && fields[0] == "fpr" {
+			return fields[9]
+		}
+	}
+	g.t.Fatalf("no fingerprint for %s", id)
+
+	return ""
+}
+
+func TestGPGInterop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	g := newGPGHome(t)
+
+	g.run(nil, "--quick-gen-key", "Alice <alice@example.org>", "default", "default", "never")
+	g.run(nil, "--quick-gen-key", "Bob <bob@example.org>", "default", "default", "never")
+	alice := g.fingerprint("alice@example.org")
+	bob := g.fingerprint("bob@example.org")
+
+	// alice uses the keys exported from gpg, the public keys as ASCII armor
+	// and her secret key in binary format.
+	o := newTestOpenPGP(t)
+	require.NoError(t, os.WriteFile(o.pubring, g.run(nil, "--armor", "--export"), 0o600))
+	require.NoError(t, os.WriteFile(o.secring, g.run(nil, "--export-secret-keys", alice), 0o600))
+
+	t.Run("decrypt gpg ciphertext", func(t *testing.T) {
+		ciphertext := g.run([]byte("from gpg"), "--encrypt", "--trust-model", "always", "--recipient", alice)
+
+		rids, err := o.RecipientIDs(ctx, ciphertext)
+		require.NoError(t, err)
+		assert.Len(t, rids, 1)
+
+		plain, err := o.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, "from gpg", string(plain))
+	})
+
+	t.Run("gpg decrypts", func(t *testing.T) {
+		ciphertext, err := o.Encrypt(ctx, []byte("from openpgp"), []string{alice})
+		require.NoError(t, err)
+		assert.Equal(t, "from openpgp", string(g.run(ciphertext, "--decrypt")))
+	})
+
+	t.Run("signatures", func(t *testing.T) {
+		sig := g.run([]byte("groups"), "--armor", "--local-user", bob, "--detach-sign")
+		fp, err := o.Verify(ctx, []byte("groups"), sig)
+		require.NoError(t, err)
+		assert.Equal(t, bob, fp)
+
+		sig, err = o.Sign(ctx, alice, []byte("groups"))
+		require.NoError(t, err)
+		fn := filepath.Join(t.TempDir(), "groups.sig")
+		require.NoError(t, os.WriteFile(fn, sig, 0o600))
+		g.run([]byte("groups"), "--verify", fn, "-")
+	})
+
+	t.Run("validity", func(t *testing.T) {
+		assert.Equal(t, alice, o.Fingerprint(ctx, "alice@example.org"))
+		assert.Equal(t, bob, o.Fingerprint(ctx, "bob@example.org"))
+
+		// bob's key is not certified by alice.
+		recps, err := o.FindRecipients(ctx, alice, bob)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0x" + alice[24:]}, recps)
+		_, err = o.Encrypt(ctx, []byte("for bob"), []string{bob})
+		require.Error(t, err)
+
+		// alice certifies bob's key in gpg.
+		g.run(nil, "--default-key", alice, "--quick-sign-key", bob)
+		require.NoError(t, os.WriteFile(o.pubring, g.run(nil, "--export"), 0o600))
+		o.pubKeys = nil
+
+		recps, err = o.FindRecipients(ctx, alice, bob)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"0x" + alice[24:], "0x" + bob[24:]}, recps)
+		_, err = o.Encrypt(ctx, []byte("for bob"), []string{bob})
+		require.NoError(t, err)
+	})
+}
+
+func TestLoadGPGKeyring(t *testing.T) {
+	t.Parallel()
+
+	g := newGPGHome(t)
+	g.run(nil, "--quick-gen-key", "Carol <carol@example.org>", "default", "default", "never")
+
+	fn := filepath.Join(t.TempDir(), "pubring.gpg")
+	require.NoError(t, os.WriteFile(fn, g.run(nil, "--export"), 0o600))
+
+	el, err := loadKeyring(fn)
+	require.NoError(t, err)
+	require.Len(t, el, 1)
+	assert.Equal(t, g.fingerprint("carol@example.org"), fingerprint(el[0]))
+	assert.Equal(t, "-", toKey(el[0], nil).Validity)
+}
@@ -0,0 +1,517 @@
+package openpgp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// loadKeyring reads a binary or ASCII armored keyring. A missing file is
+// treated as an empty keyring.
+func loadKeyring(fn string) (openpgp.EntityList, error) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return openpgp.EntityList{}, nil
+		}
+
+		return nil, err
+	}
+
+	return readKeyring(buf)
+}
+
+func readKeyring(buf []byte) (openpgp.EntityList, error) {
+	if bytes.Contains(buf, []byte("-----BEGIN PGP")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(buf))
+	}
+
+	return openpgp.ReadKeyRing(bytes.NewReader(buf))
+}
+
+// saveKeyring writes the public keys in binary format.
+func saveKeyring(fn string, el openpgp.EntityList) error {
+	if err := os.MkdirAll(filepath.Dir(fn), 0o700); err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	for _, e := range el {
+		if err := e.Serialize(buf); err != nil {
+			return fmt.Errorf("failed to serialize key %s: %w", fingerprint(e), err)
+		}
+	}
+
+	return os.WriteFile(fn, buf.Bytes(), 0o600)
+}
+
+func (o *OpenPGP) publicKeys() (openpgp.EntityList, error) {
+	if o.pubKeys != nil {
+		return o.pubKeys, nil
+	}
+
+	el, err := loadKeyring(o.pubring)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public keyring %s: %w", o.pubring, err)
+	}
+	debug.Log("loaded %d public keys from %s", len(el), o.pubring)
+	o.pubKeys = el
+
+	return el, nil
+}
+
+func (o *OpenPGP) secretKeys() (openpgp.EntityList, error) {
+	if o.secKeys != nil {
+		return o.secKeys, nil
+	}
+
+	el, err := loadKeyring(o.secring)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret keyring %s: %w", o.secring, err)
+	}
+	debug.Log("loaded %d secret keys from %s", len(el), o.secring)
+	o.secKeys = el
+
+	return el, nil
+}
+
+// allKeys returns all secret keys and all public keys that have no secret
+// counterpart.
+func (o *OpenPGP) allKeys() (openpgp.EntityList, error) {
+	sec, err := o.secretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := o.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(sec))
+	el := make(openpgp.EntityList, 0, len(sec)+len(pub))
+	for _, e := range sec {
+		seen[fingerprint(e)] = true
+		el = append(el, e)
+	}
+	for _, e := range pub {
+		if seen[fingerprint(e)] {
+			continue
+		}
+		el = append(el, e)
+	}
+
+	return el, nil
+}
+
+func fingerprint(e *openpgp.Entity) string {
+	return strings.ToUpper(hex.EncodeToString(e.PrimaryKey.Fingerprint))
+}
+
+// matches returns true if the needle matches the entity. Hex needles are
+// compared to the end of the fingerprints of the key and its subkeys, others
+// are matched against the names and emails of its identities.
+func matches(e *openpgp.Entity, needle string) bool {
+	needle = strings.TrimSpace(needle)
+	if needle == "" {
+		return false
+	}
+
+	if fp := strings.ToUpper(strings.TrimPrefix(needle, "0x")); isHexID(fp) {
+		if strings.HasSuffix(fingerprint(e), fp) {
+			return true
+		}
+		for _, sk := range e.Subkeys {
+			if strings.HasSuffix(strings.ToUpper(hex.EncodeToString(sk.PublicKey.Fingerprint)), fp) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	needle = strings.ToLower(strings.Trim(needle, "<>"))
+	for _, id := range e.Identities {
+		if strings.Contains(strings.ToLower(id.Name), needle) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isHexID(s string) bool {
+	switch len(s) {
+	case 8, 16, 40, 64:
+	default:
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+
+	return err == nil
+}
+
+func find(el openpgp.EntityList, needles ...string) openpgp.EntityList {
+	if len(needles) < 1 {
+		return el
+	}
+
+	found := make(openpgp.EntityList, 0, len(needles))
+	for _, e := range el {
+		for _, needle := range needles {
+			if matches(e, needle) {
+				found = append(found, e)
+
+				break
+			}
+		}
+	}
+
+	return found
+}
+
+// toKey converts an entity to a gpg.Key so that the usual formatting and
+// validity checks can be reused. sec are our secret keys, they determine the
+// validity of the key.
+func toKey(e *openpgp.Entity, sec openpgp.EntityList) gpg.Key {
+	now := time.Now()
+
+	k := gpg.Key{
+		KeyType:      "pub",
+		Validity:     validity(e, sec, now),
+		CreationDate: e.PrimaryKey.CreationTime,
+		Fingerprint:  fingerprint(e),
+		Identities:   make(map[string]gpg.Identity, len(e.Identities)),
+		SubKeys:      make(map[string]struct{}, len(e.Subkeys)),
+	}
+	if contains(sec, e) {
+		k.KeyType = "sec"
+	}
+	if bl, err := e.PrimaryKey.BitLength(); err == nil {
+		k.KeyLength = int(bl)
+	}
+
+	if sig, _ := e.PrimarySelfSignature(); sig != nil && sig.KeyLifetimeSecs != nil && *sig.KeyLifetimeSecs > 0 {
+		k.ExpirationDate = e.PrimaryKey.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second)
+	}
+
+	for name, id := range e.Identities {
+		gid := gpg.Identity{
+			Name: name,
+		}
+		if id.UserId != nil {
+			gid.Name = id.UserId.Name
+			gid.Comment = id.UserId.Comment
+			gid.Email = id.UserId.Email
+		}
+		if id.SelfSignature != nil {
+			gid.CreationDate = id.SelfSignature.CreationTime
+		}
+		k.Identities[name] = gid
+	}
+
+	for _, sk := range e.Subkeys {
+		k.SubKeys[strings.ToUpper(hex.EncodeToString(sk.PublicKey.Fingerprint))] = struct{}{}
+	}
+
+	_, k.Caps.Encrypt = e.EncryptionKey(now)
+	_, k.Caps.Sign = e.SigningKey(now)
+	if e.Revoked(now) {
+		k.Validity = "r"
+	}
+
+	return k
+}
+
+func toKeyList(el, sec openpgp.EntityList) gpg.KeyList {
+	kl := make(gpg.KeyList, 0, len(el))
+	for _, e := range el {
+		kl = append(kl, toKey(e, sec))
+	}
+
+	return kl
+}
+
+// validity returns the gpg validity of the key. There is no web of trust.
+// Our own keys are ultimately trusted, like in gpg. Other keys are fully
+// valid if one of their identities is certified by one of our keys, e.g. with
+// `gpg --quick-sign-key`. Local signatures are not supported. All other keys
+// have an unknown validity and are only used with the always trust model.
+func validity(e *openpgp.Entity, sec openpgp.EntityList, now time.Time) string {
+	if contains(sec, e) {
+		return "u"
+	}
+
+	for _, id := range e.Identities {
+		for _, sig := range id.Signatures {
+			if certifiedBy(e, id.Name, sig, sec, now) {
+				return "f"
+			}
+		}
+	}
+
+	return "-"
+}
+
+// certifiedBy returns true if sig is a valid certification of the identity
+// by one of the signers.
+func certifiedBy(e *openpgp.Entity, id string, sig *packet.Signature, signers openpgp.EntityList, now time.Time) bool {
+	switch sig.SigType {
+	case packet.SigTypeGenericCert, packet.SigTypePersonaCert, packet.SigTypeCasualCert, packet.SigTypePositiveCert:
+	default:
+		return false
+	}
+	if sig.IssuerKeyId == nil || *sig.IssuerKeyId == e.PrimaryKey.KeyId || sig.SigExpired(now) {
+		return false
+	}
+
+	for _, s := range signers {
+		if s.PrimaryKey.KeyId != *sig.IssuerKeyId || s.Revoked(now) {
+			continue
+		}
+		if err := s.PrimaryKey.VerifyUserIdSignature(id, e.PrimaryKey, sig); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func recipients(ctx context.Context, kl gpg.KeyList) []string {
+	if gpg.IsAlwaysTrust(ctx) {
+		return kl.Recipients()
+	}
+
+	return kl.UseableKeys(gpg.IsAlwaysTrust(ctx)).Recipients()
+}
+
+// ListRecipients returns the fingerprints of all usable public keys.
+func (o *OpenPGP) ListRecipients(ctx context.Context) ([]string, error) {
+	return o.FindRecipients(ctx)
+}
+
+// FindRecipients searches the keyrings for the given public keys.
+func (o *OpenPGP) FindRecipients(ctx context.Context, needles ...string) ([]string, error) {
+	sec, err := o.secretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	el, err := o.allKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	kl := make(gpg.KeyList, 0, len(el))
+	for _, e := range find(el, needles...) {
+		kl = append(kl, toKey(e, sec))
+	}
+
+	recp := recipients(ctx, kl)
+	debug.Log("found useable keys for %q: %q (all: %q)", needles, recp, kl.Recipients())
+
+	return recp, nil
+}
+
+// ListIdentities returns the fingerprints of all usable secret keys.
+func (o *OpenPGP) ListIdentities(ctx context.Context) ([]string, error) {
+	return o.FindIdentities(ctx)
+}
+
+// FindIdentities searches the secret keyring for the given keys.
+func (o *OpenPGP) FindIdentities(ctx context.Context, needles ...string) ([]string, error) {
+	el, err := o.secretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return recipients(ctx, toKeyList(find(el, needles...), el)), nil
+}
+
+func contains(el openpgp.EntityList, e *openpgp.Entity) bool {
+	fp := fingerprint(e)
+	for _, c := range el {
+		if fingerprint(c) == fp {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *OpenPGP) findKey(id string) (gpg.Key, bool) {
+	sec, err := o.secretKeys()
+	if err == nil {
+		if el := find(sec, id); len(el) > 0 {
+			return toKey(el[0], sec), true
+		}
+	}
+
+	if pub, err := o.publicKeys(); err == nil {
+		if el := find(pub, id); len(el) > 0 {
+			return toKey(el[0], sec), true
+		}
+	}
+
+	return gpg.Key{
+		Fingerprint: id,
+	}, false
+}
+
+// Fingerprint returns the fingerprint.
+func (o *OpenPGP) Fingerprint(ctx context.Context, id string) string {
+	k, found := o.findKey(id)
+	if !found {
+		return ""
+	}
+
+	return k.Fingerprint
+}
+
+// KeyStatus returns the revocation and expiry status of the given key.
+func (o *OpenPGP) KeyStatus(ctx context.Context, id string) (backend.KeyStatus, error) {
+	k, found := o.findKey(id)
+	if !found {
+		return backend.KeyStatus{}, gpg.ErrKeyNotFound
+	}
+
+	return backend.KeyStatus{
+		Revoked: k.IsRevoked(),
+		Expires: k.ExpirationDate,
+	}, nil
+}
+
+// FormatKey formats the details of a key id
+// Examples:
+// - NameFromKey: {{ .Name }}
+// - EmailFromKey: {{ .Email }}.
+func (o *OpenPGP) FormatKey(ctx context.Context, id, tpl string) string {
+	k, found := o.findKey(id)
+	if tpl == "" {
+		if !found {
+			return ""
+		}
+
+		return k.OneLine()
+	}
+
+	tmpl, err := template.New(tpl).Parse(tpl)
+	if err != nil {
+		return ""
+	}
+
+	var gid gpg.Identity
+	if found {
+		gid = k.Identity()
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, gid); err != nil {
+		debug.Log("Failed to render template %q: %s", tpl, err)
+
+		return ""
+	}
+
+	return buf.String()
+}
+
+// ReadNamesFromKey unmarshals and returns the names associated with the given public key.
+func (o *OpenPGP) ReadNamesFromKey(ctx context.Context, buf []byte) ([]string, error) {
+	el, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key ring: %w", err)
+	}
+
+	if len(el) != 1 {
+		return nil, fmt.Errorf("public Key must contain exactly one Entity")
+	}
+
+	names := make([]string, 0, len(el[0].Identities))
+	for _, v := range el[0].Identities {
+		names = append(names, v.Name)
+	}
+
+	return names, nil
+}
+
+// ImportPublicKey adds the given keys to the public keyring. Keys that are
+// already present are replaced.
+func (o *OpenPGP) ImportPublicKey(ctx context.Context, buf []byte) error {
+	if len(buf) < 1 {
+		return fmt.Errorf("empty input")
+	}
+
+	in, err := readKeyring(buf)
+	if err != nil {
+		return fmt.Errorf("failed to read keys: %w", err)
+	}
+
+	pub, err := o.publicKeys()
+	if err != nil {
+		return err
+	}
+
+	el := make(openpgp.EntityList, 0, len(pub)+len(in))
+	for _, e := range pub {
+		if !contains(in, e) {
+			el = append(el, e)
+		}
+	}
+	el = append(el, in...)
+
+	if err := saveKeyring(o.pubring, el); err != nil {
+		return fmt.Errorf("failed to write public keyring %s: %w", o.pubring, err)
+	}
+	debug.Log("imported %d keys into %s", len(in), o.pubring)
+
+	o.pubKeys = el
+
+	return nil
+}
+
+// ExportPublicKey returns the ASCII armored public key.
+func (o *OpenPGP) ExportPublicKey(ctx context.Context, id string) ([]byte, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is empty")
+	}
+
+	el, err := o.allKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	found := find(el, id)
+	if len(found) < 1 {
+		return nil, fmt.Errorf("key not found")
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := armor.Encode(buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := found[0].Serialize(w); err != nil {
+		return nil, fmt.Errorf("failed to serialize key: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package openpgp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+func init() {
+	backend.CryptoRegistry.Register(backend.OpenPGP, Name, &loader{})
+}
+
+type loader struct{}
+
+// New implements backend.CryptoLoader.
+func (l loader) New(ctx context.Context) (backend.Crypto, error) {
+	debug.Log("Using Crypto Backend: %s", Name)
+
+	return New(ctx), nil
+}
+
+// Handles returns nil for gpg stores if openpgp.prefer is set. Otherwise
+// these stores are left to gpgcli, even if there is no gpg binary. openpgp
+// has its own keyrings so switching silently would look like all keys are
+// gone.
+func (l loader) Handles(ctx context.Context, s backend.Storage) error {
+	if !s.Exists(ctx, IDFile) {
+		return fmt.Errorf("not supported")
+	}
+
+	if config.Bool(ctx, "openpgp.prefer") {
+		return nil
+	}
+
+	return fmt.Errorf("not preferred")
+}
+
+func (l loader) Priority() int {
+	return 0
+}
+
+func (l loader) String() string {
+	return Name
+}
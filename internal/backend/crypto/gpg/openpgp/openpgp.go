@@ -0,0 +1,95 @@
+// Package openpgp implements a pure Go OpenPGP crypto backend. It reads and
+// writes the same files as the gpg cli backend, but does not need a gpg
+// binary. Keys are kept in keyring files instead of the gpg keyring.
+package openpgp
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/blang/semver/v4"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+)
+
+const (
+	// Ext is the file extension used by this backend.
+	Ext = "gpg"
+	// IDFile is the name of the recipients file used by this backend.
+	IDFile = ".gpg-id"
+	// Name is the name of this backend.
+	Name = "openpgp"
+)
+
+// OpenPGP is a pure Go OpenPGP backend.
+type OpenPGP struct {
+	pubring string
+	secring string
+	askPass *askPass
+
+	// keyrings are loaded on first use.
+	pubKeys openpgp.EntityList
+	secKeys openpgp.EntityList
+}
+
+// New creates a new OpenPGP backend. The keyring locations can be changed
+// with openpgp.pubring and openpgp.secring, e.g. to use keys exported from gpg.
+func New(ctx context.Context) *OpenPGP {
+	dir := filepath.Join(appdir.UserConfig(), "openpgp")
+
+	o := &OpenPGP{
+		pubring: filepath.Join(dir, "pubring.gpg"),
+		secring: filepath.Join(dir, "secring.gpg"),
+		askPass: newAskPass(),
+	}
+	if sv := config.String(ctx, "openpgp.pubring"); sv != "" {
+		o.pubring = fsutil.ExpandHomedir(sv)
+	}
+	if sv := config.String(ctx, "openpgp.secring"); sv != "" {
+		o.secring = fsutil.ExpandHomedir(sv)
+	}
+
+	debug.Log("openpgp initialized (pubring: %s, secring: %s)", o.pubring, o.secring)
+
+	return o
+}
+
+// Initialized always returns nil.
+func (o *OpenPGP) Initialized(ctx context.Context) error {
+	return nil
+}
+
+// Name returns openpgp.
+func (o *OpenPGP) Name() string {
+	return Name
+}
+
+// Version returns the version of the OpenPGP library being used.
+func (o *OpenPGP) Version(ctx context.Context) semver.Version {
+	return debug.ModuleVersion("github.com/ProtonMail/go-crypto")
+}
+
+// Ext returns gpg.
+func (o *OpenPGP) Ext() string {
+	return Ext
+}
+
+// IDFile returns .gpg-id.
+func (o *OpenPGP) IDFile() string {
+	return IDFile
+}
+
+// Concurrency returns 1. Decrypting a private key modifies the keyring and
+// may prompt for a passphrase, so this must not happen in parallel.
+func (o *OpenPGP) Concurrency() int {
+	return 1
+}
+
+// Lock forgets all cached passphrases and decrypted private keys.
+func (o *OpenPGP) Lock() {
+	o.askPass.cache.Purge()
+	o.secKeys = nil
+}
@@ -0,0 +1,161 @@
+package openpgp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOpenPGP(t *testing.T) *OpenPGP {
+	t.Helper()
+
+	td := t.TempDir()
+
+	return &OpenPGP{
+		pubring: filepath.Join(td, "pubring.gpg"),
+		secring: filepath.Join(td, "secring.gpg"),
+		askPass: newAskPass(),
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := newTestOpenPGP(t)
+
+	require.NoError(t, o.GenerateIdentity(ctx, "Alice", "alice@example.org", "secret"))
+
+	ids, err := o.ListIdentities(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Len(t, ids[0], 18)
+
+	recps, err := o.FindRecipients(ctx, "alice@example.org")
+	require.NoError(t, err)
+	assert.Equal(t, ids, recps)
+	fp := o.Fingerprint(ctx, ids[0])
+	assert.Len(t, fp, 40)
+	assert.Equal(t, "Alice", o.FormatKey(ctx, ids[0], "{{ .Name }}"))
+
+	buf, err := o.Encrypt(ctx, []byte("foobar"), ids)
+	require.NoError(t, err)
+
+	rids, err := o.RecipientIDs(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{fp}, rids)
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		ctx := ctxutil.WithPasswordCallback(ctx, func(string, bool) ([]byte, error) {
+			return []byte("wrong"), nil
+		})
+		_, err := o.Decrypt(ctx, buf)
+		require.Error(t, err)
+	})
+
+	ctx = ctxutil.WithPasswordCallback(ctx, func(string, bool) ([]byte, error) {
+		return []byte("secret"), nil
+	})
+	plain, err := o.Decrypt(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "foobar", string(plain))
+
+	_, err = o.Encrypt(ctx, []byte("foobar"), []string{"bob@example.org"})
+	require.Error(t, err)
+}
+
+func TestImportExport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	alice := newTestOpenPGP(t)
+	bob := newTestOpenPGP(t)
+
+	require.NoError(t, bob.GenerateIdentity(ctx, "Bob", "bob@example.org", ""))
+
+	pk, err := bob.ExportPublicKey(ctx, "bob@example.org")
+	require.NoError(t, err)
+	assert.Contains(t, string(pk), "BEGIN PGP PUBLIC KEY BLOCK")
+
+	names, err := alice.ReadNamesFromKey(ctx, pk)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bob <bob@example.org>"}, names)
+
+	require.NoError(t, alice.ImportPublicKey(ctx, pk))
+	// importing the same key again replaces it
+	require.NoError(t, alice.ImportPublicKey(ctx, pk))
+
+	// reload from disk
+	alice.pubKeys = nil
+	recps, err := alice.ListRecipients(ctx)
+	require.NoError(t, err)
+	// bob's key is not certified by alice.
+	assert.Empty(t, recps)
+
+	ctx = gpg.WithAlwaysTrust(ctx, true)
+	recps, err = alice.ListRecipients(ctx)
+	require.NoError(t, err)
+	require.Len(t, recps, 1)
+
+	ids, err := alice.ListIdentities(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	buf, err := alice.Encrypt(ctx, []byte("for bob"), recps)
+	require.NoError(t, err)
+
+	plain, err := bob.Decrypt(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "for bob", string(plain))
+
+	_, err = alice.Decrypt(ctx, buf)
+	require.Error(t, err)
+}
+
+func TestIsHexID(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]bool{
+		"DEADBEEF":         true,
+		"DEADBEEFDEADBEEF": true,
+		"DEADBEEFDEADBEEFDEADBEEFDEADBEEFDEADBEEF": true,
+		"DEADBEE":           false,
+		"NOTAHEXID":         false,
+		"alice@example.org": false,
+	} {
+		assert.Equal(t, want, isHexID(in), in)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+
+	ctx := ctxutil.WithPasswordCallback(context.Background(), func(string, bool) ([]byte, error) {
+		return []byte("secret"), nil
+	})
+	o := newTestOpenPGP(t)
+
+	require.NoError(t, o.GenerateIdentity(ctx, "Alice", "alice@example.org", "secret"))
+
+	ids, err := o.ListIdentities(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	sig, err := o.Sign(ctx, ids[0], []byte("foobar"))
+	require.NoError(t, err)
+
+	fp, err := o.Verify(ctx, []byte("foobar"), sig)
+	require.NoError(t, err)
+	assert.Equal(t, o.Fingerprint(ctx, ids[0]), fp)
+
+	_, err = o.Verify(ctx, []byte("barfoo"), sig)
+	require.Error(t, err)
+
+	_, err = o.Sign(ctx, "bob@example.org", []byte("foobar"))
+	require.Error(t, err)
+}
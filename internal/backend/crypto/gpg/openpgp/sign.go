@@ -0,0 +1,85 @@
+package openpgp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Sign creates an armored detached signature of data with the given key.
+// Encrypted private keys are unlocked like in Decrypt.
+func (o *OpenPGP) Sign(ctx context.Context, id string, data []byte) ([]byte, error) {
+	ctx = o.withAskPass(ctx)
+
+	sec, err := o.secretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	found := find(sec, id)
+	if len(found) < 1 {
+		return nil, fmt.Errorf("no secret key found for %s", id)
+	}
+
+	e := found[0]
+	if err := unlock(ctx, e); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := openpgp.ArmoredDetachSign(buf, e, bytes.NewReader(data), nil); err != nil {
+		return nil, fmt.Errorf("failed to sign with %s: %w", id, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Verify checks a detached signature of data against the keyrings and
+// returns the fingerprint of the signing key. Signatures made by expired or
+// revoked keys are rejected.
+func (o *OpenPGP) Verify(ctx context.Context, data, sig []byte) (string, error) {
+	el, err := o.allKeys()
+	if err != nil {
+		return "", err
+	}
+
+	e, err := openpgp.CheckArmoredDetachedSignature(el, bytes.NewReader(data), bytes.NewReader(sig), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return fingerprint(e), nil
+}
+
+// unlock decrypts the private keys of e with the passphrase from the context.
+func unlock(ctx context.Context, e *openpgp.Entity) error {
+	if e.PrivateKey == nil || !e.PrivateKey.Encrypted {
+		return nil
+	}
+
+	fp := fingerprint(e)
+	for attempts := 0; attempts < maxAttempts; attempts++ {
+		if attempts > 0 {
+			ctxutil.GetPasswordPurgeCallback(ctx)(fp)
+		}
+
+		pw, err := ctxutil.GetPasswordCallback(ctx)(fp, false)
+		if err != nil {
+			return err
+		}
+
+		if err := e.DecryptPrivateKeys(pw); err != nil {
+			debug.Log("failed to unlock key %s: %s", fp, err)
+
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("failed to unlock key %s: wrong passphrase", fp)
+}
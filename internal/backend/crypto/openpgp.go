@@ -0,0 +1,3 @@
+package crypto
+
+import _ "github.com/gopasspw/gopass/internal/backend/crypto/gpg/openpgp" // register native openpgp backend
@@ -3,6 +3,7 @@ package plain
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"runtime"
 	"strings"
@@ -165,3 +166,18 @@ func (m *Mocker) ReadNamesFromKey(ctx context.Context, buf []byte) ([]string, er
 func (m *Mocker) Concurrency() int {
 	return runtime.NumCPU()
 }
+
+// Sign returns a fake signature that contains the id and a hash of the data.
+func (m *Mocker) Sign(ctx context.Context, id string, data []byte) ([]byte, error) {
+	return fmt.Appendf(nil, "%s:%x", id, sha256.Sum256(data)), nil
+}
+
+// Verify checks a signature created by Sign and returns its id.
+func (m *Mocker) Verify(ctx context.Context, data, sig []byte) (string, error) {
+	id, sum, found := strings.Cut(string(sig), ":")
+	if !found || sum != fmt.Sprintf("%x", sha256.Sum256(data)) {
+		return "", fmt.Errorf("invalid signature")
+	}
+
+	return id, nil
+}
@@ -25,6 +25,7 @@ type rcs interface {
 
 	Revisions(ctx context.Context, name string) ([]Revision, error)
 	GetRevision(ctx context.Context, name, revision string) ([]byte, error)
+	Deletions(ctx context.Context) ([]Deletion, error)
 
 	Status(ctx context.Context) ([]byte, error)
 	Compact(ctx context.Context) error
@@ -40,6 +41,19 @@ type Revision struct {
 	Body        string
 }
 
+// Deletion is a file that was deleted in a revision.
+type Deletion struct {
+	Revision
+
+	Name string
+}
+
+// RemoteStatus is the result of pushing to a single remote.
+type RemoteStatus struct {
+	Remote string
+	Err    error
+}
+
 // Revisions implements the sort interface.
 type Revisions []Revision
 
@@ -21,6 +21,8 @@ const (
 	GitFS
 	// FossilFS is a filesystem-backed storage with Fossil.
 	FossilFS
+	// GoGit is a filesystem-backed storage with an in-process Git implementation.
+	GoGit
 )
 
 func (s StorageBackend) String() string {
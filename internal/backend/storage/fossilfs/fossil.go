@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -43,27 +44,42 @@ func New(path string) (*Fossil, error) {
 }
 
 // Clone opens a new fossil checkout.
-func Clone(ctx context.Context, repo, path string) (*Fossil, error) {
+func Clone(ctx context.Context, repo, path, userName, userEmail string) (*Fossil, error) {
 	f := &Fossil{
 		fs: fs.New(path),
 	}
-	// we use open instead of clone, since that automatically clones, if necessary
-	args := []string{
-		"open", repo,
-		"--workdir", path,
+
+	// fossil only clones URIs, a plain path would be opened directly. We want
+	// our own copy that syncs with it, like a git clone does.
+	if !isURI(repo) && fsutil.IsFile(repo) {
+		abs, err := filepath.Abs(repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", repo, err)
+		}
+		repo = "file://" + filepath.ToSlash(abs)
 	}
-	// the --repodir option only makes sense if the REPOSITORY argument is a URI that begins with http:, https:, ssh:, or file:
-	if strings.HasPrefix(repo, "http:") || strings.HasPrefix(repo, "https:") || strings.HasPrefix(repo, "ssh:") || strings.HasPrefix(repo, "file:") {
-		args = append(args, "--repodir", filepath.Dir(path))
+
+	// clone into a repository file next to the checkout. fossil open could
+	// clone, too, but it names the file after the remote, which clashes if
+	// the remote is the repository of another store in the same directory.
+	parent := filepath.Dir(path)
+	if err := os.MkdirAll(parent, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", parent, err)
+	}
+
+	pctx := withPathOverride(ctx, parent)
+	local := repoPath(path)
+	if err := f.Cmd(pctx, "Clone", "clone", repo, local); err != nil {
+		return nil, err
 	}
 
-	if err := f.Cmd(withPathOverride(ctx, filepath.Dir(path)), "Clone", args...); err != nil {
+	if err := f.Cmd(pctx, "Open", "open", local, "--workdir", path); err != nil {
 		return nil, err
 	}
 
 	// initialize the local fossil config.
-	if err := f.InitConfig(ctx, "", ""); err != nil {
-		return f, fmt.Errorf("failed to configure git: %w", err)
+	if err := f.InitConfig(ctx, userName, userEmail); err != nil {
+		return f, fmt.Errorf("failed to configure fossil: %w", err)
 	}
 
 	out.Printf(ctx, "fossil configured at %s", f.fs.Path())
@@ -72,19 +88,19 @@ func Clone(ctx context.Context, repo, path string) (*Fossil, error) {
 }
 
 // Init initializes this store's fossil repo.
-func Init(ctx context.Context, path, _, _ string) (*Fossil, error) {
+func Init(ctx context.Context, path, userName, userEmail string) (*Fossil, error) {
 	f := &Fossil{
 		fs: fs.New(path),
 	}
 	// the fossil repo may be empty (i.e. no branches, cloned from a fresh remote)
 	// or already initialized. Only run fossil init if the folder is completely empty.
 	if !f.IsInitialized() {
-		repo := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".fossil")
+		repo := repoPath(path)
 		if err := f.Cmd(ctx, "Init", "init", repo); err != nil {
 			return nil, fmt.Errorf("failed to initialize fossil in %s: %w", repo, err)
 		}
 
-		if err := f.Cmd(ctx, "Open", "open", repo); err != nil {
+		if err := f.Cmd(ctx, "Open", "open", "--force", repo); err != nil {
 			return nil, fmt.Errorf("failed to open fossil in %s: %w", repo, err)
 		}
 
@@ -97,7 +113,7 @@ func Init(ctx context.Context, path, _, _ string) (*Fossil, error) {
 	}
 
 	// initialize the local fossil config.
-	if err := f.InitConfig(ctx, "", ""); err != nil {
+	if err := f.InitConfig(ctx, userName, userEmail); err != nil {
 		return f, fmt.Errorf("failed to configure fossil: %w", err)
 	}
 
@@ -122,6 +138,29 @@ func Init(ctx context.Context, path, _, _ string) (*Fossil, error) {
 	return f, nil
 }
 
+// repoPath returns the location of the repository file for a new checkout at
+// path. It lives next to the checkout. Leftovers of earlier attempts, e.g. a
+// failed conversion, are not reused.
+func repoPath(path string) string {
+	base := filepath.Join(filepath.Dir(path), "."+filepath.Base(path))
+	repo := base + ".fossil"
+	for i := 1; fsutil.IsFile(repo); i++ {
+		repo = fmt.Sprintf("%s-%d.fossil", base, i)
+	}
+
+	return repo
+}
+
+func isURI(repo string) bool {
+	for _, scheme := range []string{"http:", "https:", "ssh:", "file:"} {
+		if strings.HasPrefix(repo, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (f *Fossil) captureCmd(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
 	bufOut := &bytes.Buffer{}
 	bufErr := &bytes.Buffer{}
@@ -198,14 +237,42 @@ func (f *Fossil) Add(ctx context.Context, files ...string) error {
 		return store.ErrGitNotInit
 	}
 
-	for i := range files {
-		files[i] = strings.TrimPrefix(files[i], f.fs.Path()+"/")
+	// fossil add refuses missing files, so deletions have to be recorded
+	// with fossil rm. This mirrors git add --all.
+	var add, rm []string
+	for _, fn := range files {
+		fn = strings.TrimPrefix(fn, f.fs.Path()+"/")
+		full := fn
+		if !filepath.IsAbs(fn) {
+			full = filepath.Join(f.fs.Path(), fn)
+		}
+		if fsutil.IsFile(full) || fsutil.IsDir(full) {
+			add = append(add, fn)
+
+			continue
+		}
+		rm = append(rm, fn)
+	}
+
+	if len(add) > 0 {
+		args := []string{"add", "--force", "--dotfiles"}
+		args = append(args, add...)
+
+		if err := f.Cmd(ctx, "fossilAdd", args...); err != nil {
+			return err
+		}
 	}
 
-	args := []string{"add", "--force", "--dotfiles"}
-	args = append(args, files...)
+	if len(rm) > 0 {
+		args := []string{"rm", "--soft"}
+		args = append(args, rm...)
 
-	return f.Cmd(ctx, "fossilAdd", args...)
+		if err := f.Cmd(ctx, "fossilRm", args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // TryAdd adds the listed files to the fossil index.
@@ -255,16 +322,30 @@ func (f *Fossil) Commit(ctx context.Context, msg string) error {
 		return store.ErrGitNothingToCommit
 	}
 
-	return f.Cmd(
-		ctx,
-		"fossilCommit",
+	// with autosync fossil refuses to commit on top of an outdated checkout,
+	// so bring in the changes of others first.
+	if !ctxutil.IsNoNetwork(ctx) && f.hasRemote(ctx) {
+		if err := f.Cmd(ctx, "fossilUpdate", "update"); err != nil {
+			return err
+		}
+	}
+
+	args := []string{
 		"commit",
 		"--date-override",
 		ctxutil.GetCommitTimestamp(ctx).UTC().Format("2006-01-02T15:04:05.000"),
+		// converted stores keep the original timestamps, which are older
+		// than the check-in they are based on.
+		"--allow-older",
 		"--no-warnings",
 		"-m",
 		msg,
-	)
+	}
+	if ctxutil.IsNoNetwork(ctx) {
+		args = append(args, "--nosync")
+	}
+
+	return f.Cmd(ctx, "fossilCommit", args...)
 }
 
 // TryCommit calls commit and returns nil if there was nothing to commit or if the Fossil repo was not initialized.
@@ -303,14 +384,23 @@ func (f *Fossil) PushPull(ctx context.Context, op, remote, branch string) error
 		out.Warningf(ctx, "Found untracked files: %+v", uf)
 	}
 
+	// an explicit remote is passed by name, otherwise fossil uses the
+	// default remote.
+	var args []string
+	if remote != "" {
+		args = append(args, remote)
+	} else if !f.hasRemote(ctx) {
+		return store.ErrGitNoRemote
+	}
+
 	// https://www.fossil-scm.org/home/help?cmd=sync
 	switch op {
 	case "pull":
-		if err := f.Cmd(ctx, "fossilPull", op); err != nil {
+		if err := f.Cmd(ctx, "fossilPull", append([]string{"pull"}, args...)...); err != nil {
 			return err
 		}
 	default:
-		if err := f.Cmd(ctx, "fossilSync", "sync"); err != nil {
+		if err := f.Cmd(ctx, "fossilSync", append([]string{"sync"}, args...)...); err != nil {
 			return err
 		}
 	}
@@ -361,9 +451,30 @@ func (f *Fossil) Pull(ctx context.Context, remote, branch string) error {
 	return f.PushPull(ctx, "pull", remote, branch)
 }
 
-// AddRemote adds a new remote.
+// hasRemote returns true if a default remote is configured.
+func (f *Fossil) hasRemote(ctx context.Context) bool {
+	stdout, _, err := f.captureCmd(ctx, "fossilRemote", "remote")
+	if err != nil {
+		return false
+	}
+
+	url := strings.TrimSpace(string(stdout))
+
+	return url != "" && url != "off"
+}
+
+// AddRemote adds a new remote. fossil only syncs with its default remote, so
+// origin or the first remote added becomes the default.
 func (f *Fossil) AddRemote(ctx context.Context, remote, url string) error {
-	return f.Cmd(ctx, "fossilAddRemote", "remote", "add", remote, url)
+	if err := f.Cmd(ctx, "fossilAddRemote", "remote", "add", remote, url); err != nil {
+		return err
+	}
+
+	if remote != "origin" && f.hasRemote(ctx) {
+		return nil
+	}
+
+	return f.Cmd(ctx, "fossilSetRemote", "remote", url)
 }
 
 // RemoveRemote removes a remote.
@@ -386,14 +497,33 @@ func (f *Fossil) Revisions(ctx context.Context, name string) ([]backend.Revision
 		return nil, err
 	}
 
-	revs := make([]backend.Revision, 0, strings.Count(string(stdout), "\n"))
-	for _, line := range strings.Split(string(stdout), "\n") {
+	return parseRevisions(string(stdout)), nil
+}
+
+// parseRevisions parses the output of fossil finfo -W 0. It looks like this:
+//
+//	2024-01-02 [0123456789] Save secret to foo. (user: alice, artifact: [abcdef0123], branch: trunk)
+//
+// Comments with several lines, e.g. of converted stores, continue on the
+// following lines.
+func parseRevisions(in string) []backend.Revision {
+	var entries []string
+	for _, line := range strings.Split(in, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
-			debug.Log("empty line")
+		if line == "" || strings.HasPrefix(line, "History for") {
+			continue
+		}
+
+		if isRevisionStart(line) || len(entries) < 1 {
+			entries = append(entries, line)
 
 			continue
 		}
+		entries[len(entries)-1] += " " + line
+	}
+
+	revs := make([]backend.Revision, 0, len(entries))
+	for _, line := range entries {
 
 		debug.Log("Parsing line: %s", line)
 		body := line //nolint:copyloopvar // retain full line for the body
@@ -410,20 +540,17 @@ func (f *Fossil) Revisions(ctx context.Context, name string) ([]backend.Revision
 			continue
 		}
 		rev = strings.Trim(rev, "[]")
-		subject, line, found := strings.Cut(line, "(")
-		if !found {
-			debug.Log("Failed to parse subject")
 
-			continue
-		}
-
-		author, _, found := strings.Cut(line, ",")
-		if !found {
-			debug.Log("Failed to parse author")
+		// the subject may contain parentheses itself.
+		idx := strings.LastIndex(line, "(user: ")
+		if idx < 0 {
+			debug.Log("Failed to parse subject")
 
 			continue
 		}
-		author = strings.TrimPrefix(author, "user: ")
+		subject := strings.TrimSpace(line[:idx])
+		author, _, _ := strings.Cut(strings.TrimPrefix(line[idx:], "(user: "), ",")
+		author = strings.TrimSuffix(author, ")")
 
 		ts, err := time.Parse("2006-01-02", date)
 		if err != nil {
@@ -432,17 +559,28 @@ func (f *Fossil) Revisions(ctx context.Context, name string) ([]backend.Revision
 			continue
 		}
 
-		r := backend.Revision{
+		revs = append(revs, backend.Revision{
 			Hash:       rev,
 			Date:       ts,
 			Body:       body,
 			Subject:    subject,
 			AuthorName: author,
-		}
-		revs = append(revs, r)
+		})
+	}
+
+	return revs
+}
+
+// isRevisionStart returns true if line starts a new entry of fossil finfo.
+func isRevisionStart(line string) bool {
+	date, rest, found := strings.Cut(line, " ")
+	if !found || !strings.HasPrefix(rest, "[") {
+		return false
 	}
 
-	return revs, nil
+	_, err := time.Parse("2006-01-02", date)
+
+	return err == nil
 }
 
 // GetRevision will return the content of any revision of the named entity.
@@ -465,6 +603,86 @@ func (f *Fossil) GetRevision(ctx context.Context, name, revision string) ([]byte
 	return stdout, nil
 }
 
+// Deletions lists all files that were deleted in the history of the
+// repository, newest first. Files deleted more than once are only listed with
+// their latest deletion.
+func (f *Fossil) Deletions(ctx context.Context) ([]backend.Deletion, error) {
+	args := []string{
+		"timeline",
+		"-t",
+		"ci",
+		"-v",
+		"-W",
+		"0",
+		"-n",
+		"0",
+	}
+	stdout, stderr, err := f.captureCmd(ctx, "Deletions", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+
+		return nil, err
+	}
+
+	return parseDeletions(string(stdout)), nil
+}
+
+// parseDeletions parses the output of fossil timeline -v. It looks like this:
+//
+//	=== 2024-01-02 ===
+//	12:34:56 [0123456789] Remove foo from store. (user: alice tags: trunk)
+//	   DELETED foo.gpg
+func parseDeletions(in string) []backend.Deletion {
+	seen := map[string]bool{}
+	dels := []backend.Deletion{}
+
+	var day string
+	var rev backend.Revision
+	for _, line := range strings.Split(in, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "===") {
+			day = strings.TrimSpace(strings.Trim(line, "="))
+
+			continue
+		}
+
+		if fn, found := strings.CutPrefix(line, "DELETED "); found {
+			fn = strings.TrimSpace(fn)
+			if fn == "" || seen[fn] {
+				continue
+			}
+			seen[fn] = true
+
+			dels = append(dels, backend.Deletion{Revision: rev, Name: fn})
+
+			continue
+		}
+
+		clock, line, found := strings.Cut(line, " [")
+		if !found {
+			continue
+		}
+		hash, line, found := strings.Cut(line, "] ")
+		if !found {
+			continue
+		}
+		subject, line, _ := strings.Cut(line, " (user: ")
+		author, _, _ := strings.Cut(line, " ")
+
+		rev = backend.Revision{
+			Hash:       hash,
+			Subject:    subject,
+			AuthorName: strings.TrimSuffix(author, ")"),
+		}
+		if ts, err := time.Parse("2006-01-02 15:04:05", day+" "+clock); err == nil {
+			rev.Date = ts
+		}
+	}
+
+	return dels
+}
+
 // Status return the fossil status output.
 func (f *Fossil) Status(ctx context.Context) ([]byte, error) {
 	stdout, stderr, err := f.captureCmd(ctx, "FossilStatus", "status")
@@ -0,0 +1,184 @@
+package fossilfs
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRevisions(t *testing.T) {
+	t.Parallel()
+
+	in := `History for foo.gpg
+2024-01-03 [0123456789] Save secret to foo (with notes). (user: dead.beef@example.org, artifact: [abcdef0123], branch: trunk)
+2024-01-02 [9876543210] Add current content of password store (user: alice, artifact: [3210fedcba], branch: trunk)
+2024-01-01 [1111111111] Save secret to foo
+           Committed as: 0123456789abcdef
+           Date: 2024-01-01T12:00:00Z (user: bob, artifact: [2222222222], branch: trunk)
+garbage
+`
+	revs := parseRevisions(in)
+	require.Len(t, revs, 3)
+
+	assert.Equal(t, "0123456789", revs[0].Hash)
+	assert.Equal(t, "Save secret to foo (with notes).", revs[0].Subject)
+	assert.Equal(t, "dead.beef@example.org", revs[0].AuthorName)
+	assert.Equal(t, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), revs[0].Date)
+
+	assert.Equal(t, "9876543210", revs[1].Hash)
+	assert.Equal(t, "alice", revs[1].AuthorName)
+
+	assert.Equal(t, "1111111111", revs[2].Hash)
+	assert.Equal(t, "bob", revs[2].AuthorName)
+	assert.Contains(t, revs[2].Subject, "Committed as: 0123456789abcdef")
+}
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+
+	s := parseStatus(`repository:   /tmp/.store.fossil
+EDITED     foo.gpg
+ADDED      bar.gpg
+DELETED    baz.gpg
+MISSING    zab.gpg
+EXTRA      new.gpg
+UNCHANGED  .gpg-id
+`)
+
+	assert.Equal(t, []string{"new.gpg"}, s.Untracked().Elements())
+	assert.Equal(t, []string{"bar.gpg", "baz.gpg", "foo.gpg"}, s.Staged().Elements())
+}
+
+func TestParseSetting(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		line  string
+		key   string
+		value string
+	}{
+		{line: "autosync             (local)  1", key: "autosync", value: "1"},
+		{line: "ssh-command          (global) ssh -e none -T", key: "ssh-command", value: "ssh -e none -T"},
+		{line: "binary-glob", key: "binary-glob", value: ""},
+		{line: "", key: "", value: ""},
+	} {
+		k, v := parseSetting(tc.line)
+		assert.Equal(t, tc.key, k, tc.line)
+		assert.Equal(t, tc.value, v, tc.line)
+	}
+}
+
+func TestUserLogin(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "dead.beef@example.org", userLogin("Dead Beef", "dead.beef@example.org"))
+	assert.Equal(t, "Dead-Beef", userLogin("Dead  Beef", ""))
+	assert.Empty(t, userLogin("", ""))
+
+	assert.True(t, hasUser("admin        Dead Beef\ndead.beef@example.org  Dead Beef <dead.beef@example.org>\n", "dead.beef@example.org"))
+	assert.False(t, hasUser("admin\n", "dead.beef@example.org"))
+}
+
+func TestFossil(t *testing.T) {
+	if _, err := exec.LookPath("fossil"); err != nil {
+		t.Skip("fossil not found")
+	}
+
+	td := t.TempDir()
+	t.Setenv("HOME", td)
+	t.Setenv("FOSSIL_HOME", td)
+	t.Setenv("USER", "gopass")
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	dir := filepath.Join(td, "store")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte("0xDEADBEEF"), 0o644))
+
+	f, err := Init(ctx, dir, "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+	require.True(t, f.IsInitialized())
+
+	repo := filepath.Join(td, ".store.fossil")
+	require.FileExists(t, repo)
+
+	commit := func(t *testing.T, f *Fossil, content string) {
+		t.Helper()
+
+		require.NoError(t, f.Set(ctx, "foo", []byte(content)))
+		require.NoError(t, f.Add(ctx, "foo"))
+		require.NoError(t, f.Commit(ctx, "update foo"))
+	}
+
+	commit(t, f, "first")
+	commit(t, f, "second")
+
+	t.Run("settings", func(t *testing.T) {
+		sv, err := f.ConfigGet(ctx, "autosync")
+		require.NoError(t, err)
+		assert.Equal(t, "1", sv)
+
+		kv, err := f.ConfigList(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "*.age,*.gpg", kv["binary-glob"])
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		revs, err := f.Revisions(ctx, "foo")
+		require.NoError(t, err)
+		require.Len(t, revs, 2)
+		assert.Equal(t, "dead.beef@example.org", revs[0].AuthorName)
+
+		content, err := f.GetRevision(ctx, "foo", revs[1].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(content))
+	})
+
+	t.Run("no remote", func(t *testing.T) {
+		require.NoError(t, f.TryPush(ctx, "", ""))
+	})
+
+	f2, err := Clone(ctx, repo, filepath.Join(td, "clone"), "Alice", "")
+	require.NoError(t, err)
+
+	t.Run("sync", func(t *testing.T) {
+		content, err := f2.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "second", string(content))
+
+		commit(t, f, "third")
+		require.NoError(t, f2.Pull(ctx, "", ""))
+
+		content, err = f2.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "third", string(content))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, f2.Delete(ctx, "foo"))
+		require.NoError(t, f2.Add(ctx, "foo"))
+		require.NoError(t, f2.Commit(ctx, "remove foo"))
+
+		dels, err := f2.Deletions(ctx)
+		require.NoError(t, err)
+		require.Len(t, dels, 1)
+		assert.Equal(t, "foo", dels[0].Name)
+		assert.Equal(t, "Alice", dels[0].AuthorName)
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/termio"
 )
 
 const (
@@ -28,11 +29,11 @@ func (l loader) Open(ctx context.Context, path string) (backend.Storage, error)
 }
 
 func (l loader) Clone(ctx context.Context, repo, path string) (backend.Storage, error) {
-	return Clone(ctx, repo, path)
+	return Clone(ctx, repo, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
 }
 
 func (l loader) Init(ctx context.Context, path string) (backend.Storage, error) {
-	return Init(ctx, path, "", "")
+	return Init(ctx, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
 }
 
 func (l loader) Handles(ctx context.Context, path string) error {
@@ -2,9 +2,9 @@ package fossilfs
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/gopasspw/gopass/internal/store"
@@ -22,11 +22,27 @@ func (f *Fossil) fixConfig(ctx context.Context) error {
 		return fmt.Errorf("failed to set fossil config binary-glob: %w", err)
 	}
 
+	// setup for persistent SSH connections
+	if sc := fossilSSHCommand(); sc != "" {
+		ov, err := f.ConfigGet(ctx, "ssh-command")
+		// only set ssh-command if it's not already set. Avoid overwriting user settings.
+		if err == nil && ov == "" {
+			if err := f.ConfigSet(ctx, "ssh-command", sc); err != nil {
+				return fmt.Errorf("failed to set fossil config ssh-command: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
 
-// InitConfig initializes the fossil config.
-func (f *Fossil) InitConfig(ctx context.Context, _, _ string) error {
+// InitConfig initializes the fossil config. It makes sure the user exists in
+// the repository and commits are recorded with their name and email.
+func (f *Fossil) InitConfig(ctx context.Context, userName, userEmail string) error {
+	if err := f.initUser(ctx, userName, userEmail); err != nil {
+		return fmt.Errorf("failed to set up fossil user: %w", err)
+	}
+
 	// ensure a sane fossil config.
 	if err := f.fixConfig(ctx); err != nil {
 		return fmt.Errorf("failed to fix fossil config: %w", err)
@@ -35,32 +51,86 @@ func (f *Fossil) InitConfig(ctx context.Context, _, _ string) error {
 	return nil
 }
 
+// initUser creates a fossil user for the given name and email, unless it
+// already exists, and makes it the default user for this checkout.
+func (f *Fossil) initUser(ctx context.Context, userName, userEmail string) error {
+	login := userLogin(userName, userEmail)
+	if login == "" {
+		debug.Log("no name or email, keeping the default fossil user")
+
+		return nil
+	}
+
+	stdout, stderr, err := f.captureCmd(ctx, "fossilUserList", "user", "list")
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+
+	if !hasUser(string(stdout), login) {
+		// the password is only used for remote access through the fossil
+		// web server. Users can change it with fossil user password.
+		pw := make([]byte, 16)
+		if _, err := rand.Read(pw); err != nil {
+			return err
+		}
+
+		contact := strings.TrimSpace(fmt.Sprintf("%s <%s>", userName, userEmail))
+		if userEmail == "" {
+			contact = userName
+		}
+		if err := f.Cmd(ctx, "fossilUserNew", "user", "new", login, contact, hex.EncodeToString(pw)); err != nil {
+			return err
+		}
+	}
+
+	return f.Cmd(ctx, "fossilUserDefault", "user", "default", login)
+}
+
+// userLogin returns the fossil login for a user. fossil logins can not
+// contain spaces, so we prefer the email.
+func userLogin(userName, userEmail string) string {
+	if userEmail != "" {
+		return userEmail
+	}
+
+	return strings.Join(strings.Fields(userName), "-")
+}
+
+// hasUser checks if login is listed in the output of fossil user list.
+func hasUser(list, login string) bool {
+	for _, line := range strings.Split(list, "\n") {
+		if p := strings.Fields(line); len(p) > 0 && p[0] == login {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ConfigSet sets a local config value.
 func (f *Fossil) ConfigSet(ctx context.Context, key, value string) error {
 	return f.Cmd(ctx, "fossilConfigSet", "settings", "--exact", key, value)
 }
 
-// ConfigGet returns a given config value.
+// ConfigGet returns a given config value. Unset values are returned as an
+// empty string.
 func (f *Fossil) ConfigGet(ctx context.Context, key string) (string, error) {
 	if !f.IsInitialized() {
 		return "", store.ErrGitNotInit
 	}
 
-	buf := &strings.Builder{}
-
-	cmd := exec.CommandContext(ctx, "fossil", "settings", "--exact", key)
-	cmd.Dir = f.fs.Path()
-	cmd.Stdout = buf
-	cmd.Stderr = os.Stderr
-
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-	if err := cmd.Run(); err != nil {
-		return "", err
+	stdout, stderr, err := f.captureCmd(ctx, "fossilConfigGet", "settings", "--exact", key)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(stderr)))
 	}
 
-	sv := strings.Fields(strings.TrimSpace(buf.String()))
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if k, v := parseSetting(line); k == key {
+			return v, nil
+		}
+	}
 
-	return sv[len(sv)-1], nil
+	return "", nil
 }
 
 // ConfigList returns all fossil config settings.
@@ -69,28 +139,40 @@ func (f *Fossil) ConfigList(ctx context.Context) (map[string]string, error) {
 		return nil, store.ErrGitNotInit
 	}
 
-	buf := &strings.Builder{}
-
-	cmd := exec.CommandContext(ctx, "fossil", "settings")
-	cmd.Dir = f.fs.Path()
-	cmd.Stdout = buf
-	cmd.Stderr = os.Stderr
-
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-	if err := cmd.Run(); err != nil {
-		return nil, err
+	stdout, stderr, err := f.captureCmd(ctx, "fossilConfigList", "settings")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(stderr)))
 	}
 
-	lines := strings.Split(buf.String(), "\n")
+	lines := strings.Split(string(stdout), "\n")
 	kv := make(map[string]string, len(lines))
 	for _, line := range lines {
-		p := strings.Fields(strings.TrimSpace(line))
+		k, v := parseSetting(line)
 		// only record settings with a value
-		if len(p) < 2 {
+		if k == "" || v == "" {
 			continue
 		}
-		kv[p[0]] = p[len(p)-1]
+		kv[k] = v
 	}
 
 	return kv, nil
 }
+
+// parseSetting parses one line of fossil settings. It looks like this:
+//
+//	autosync             (local)  1
+//	ssh-command          (global) ssh -e none -T
+//	binary-glob
+//
+// Settings without a value only list the key.
+func parseSetting(line string) (string, string) {
+	key, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		if _, v, found := strings.Cut(rest, ")"); found {
+			rest = strings.TrimSpace(v)
+		}
+	}
+
+	return key, rest
+}
@@ -0,0 +1,16 @@
+//go:build darwin
+// +build darwin
+
+package fossilfs
+
+// fossilSSHCommand returns a SSH command instructing fossil to use SSH
+// with persistent connections through a custom socket. It extends the fossil
+// default of "ssh -e none -T".
+// See https://fossil-scm.org/home/help?cmd=ssh-command and
+// https://linux.die.net/man/5/ssh_config
+//
+// On MacOS os.TempDir() usually returns a path that is too long for a unix
+// domain socket, so we're using a hardcoded /tmp instead.
+func fossilSSHCommand() string {
+	return "ssh -e none -T -oControlMaster=auto -oControlPersist=600 -oControlPath=/tmp/.ssh-%C"
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !darwin
+// +build !windows,!darwin
+
+package fossilfs
+
+import "os"
+
+// fossilSSHCommand returns a SSH command instructing fossil to use SSH
+// with persistent connections through a custom socket. It extends the fossil
+// default of "ssh -e none -T".
+// See https://fossil-scm.org/home/help?cmd=ssh-command and
+// https://linux.die.net/man/5/ssh_config
+//
+// %C is a hash of %l%h%p%r and should avoid "path too long for unix domain socket"
+// errors. If you still encounter this error set TMPDIR to a short path, e.g. /tmp.
+func fossilSSHCommand() string {
+	return "ssh -e none -T -oControlMaster=auto -oControlPersist=600 -oControlPath=" + os.TempDir() + "/.ssh-%C"
+}
@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package fossilfs
+
+// fossilSSHCommand returns an empty string on Windows. fossil picks plink or
+// ssh on its own there.
+func fossilSSHCommand() string {
+	return ""
+}
@@ -11,6 +11,8 @@ type fossilStatus struct {
 	Extra     set.Set[string]
 	Added     set.Set[string]
 	Edited    set.Set[string]
+	Deleted   set.Set[string]
+	Missing   set.Set[string]
 	Unchanged set.Set[string]
 }
 
@@ -20,36 +22,52 @@ func (f *Fossil) getStatus(ctx context.Context) (fossilStatus, error) {
 		return fossilStatus{}, err
 	}
 
+	return parseStatus(string(stdout)), nil
+}
+
+// parseStatus parses the output of fossil status --extra --all. Each file is
+// listed on a line of its own, prefixed with its state:
+//
+//	EDITED     foo.gpg
+//	EXTRA      bar.gpg
+func parseStatus(in string) fossilStatus {
 	s := fossilStatus{
 		Extra:     set.New[string](),
 		Added:     set.New[string](),
 		Edited:    set.New[string](),
+		Deleted:   set.New[string](),
+		Missing:   set.New[string](),
 		Unchanged: set.New[string](),
 	}
-	for _, line := range strings.Split(string(stdout), "\n") {
+	for _, line := range strings.Split(in, "\n") {
 		op, file, found := strings.Cut(line, " ")
 		if !found {
 			continue
 		}
+		file = strings.TrimSpace(file)
 		switch op {
 		case "ADDED":
-			s.Added.Add(strings.TrimSpace(file))
+			s.Added.Add(file)
 		case "UNCHANGED":
-			s.Unchanged.Add(strings.TrimSpace(file))
+			s.Unchanged.Add(file)
 		case "EXTRA":
-			s.Added.Add(strings.TrimSpace(file))
+			s.Extra.Add(file)
 		case "EDITED":
-			s.Edited.Add(strings.TrimSpace(file))
+			s.Edited.Add(file)
+		case "DELETED":
+			s.Deleted.Add(file)
+		case "MISSING":
+			s.Missing.Add(file)
 		}
 	}
 
-	return s, nil
+	return s
 }
 
 func (fs *fossilStatus) Untracked() set.Set[string] {
-	return fs.Extra.Union(fs.Added).Union(fs.Edited)
+	return fs.Extra
 }
 
 func (fs *fossilStatus) Staged() set.Set[string] {
-	return fs.Edited.Union(fs.Added)
+	return fs.Edited.Union(fs.Added).Union(fs.Deleted)
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/fsutil"
 )
@@ -28,6 +29,13 @@ func (l loader) New(ctx context.Context, path string) (backend.Storage, error) {
 	be := New(path)
 	debug.Log("Using Storage Backend: %s", be.String())
 
+	if config.Bool(ctx, "fs.snapshots") && !be.snapshotsEnabled() {
+		debug.Log("Enabling snapshots in %s", path)
+		if err := be.EnableSnapshots(); err != nil {
+			return nil, fmt.Errorf("failed to enable snapshots: %w", err)
+		}
+	}
+
 	return be, nil
 }
 
@@ -68,8 +68,13 @@ func (s *Store) RemoveRemote(ctx context.Context, remote string) error {
 	return backend.ErrNotSupported
 }
 
-// Revisions is not implemented.
-func (s *Store) Revisions(context.Context, string) ([]backend.Revision, error) {
+// Revisions lists the snapshots of the named entity. Without snapshots only
+// the latest revision is available.
+func (s *Store) Revisions(ctx context.Context, name string) ([]backend.Revision, error) {
+	if s.snapshotsEnabled() {
+		return s.snapshotRevisions(name)
+	}
+
 	return []backend.Revision{
 		{
 			Hash: "latest",
@@ -78,21 +83,40 @@ func (s *Store) Revisions(context.Context, string) ([]backend.Revision, error) {
 	}, backend.ErrNotSupported
 }
 
-// GetRevision only supports getting the latest revision.
+// GetRevision returns a snapshot of the named entity. Without snapshots only
+// the latest revision is available.
 func (s *Store) GetRevision(ctx context.Context, name string, revision string) ([]byte, error) {
 	if revision == "HEAD" || revision == "latest" {
 		return s.Get(ctx, name)
 	}
 
+	if s.snapshotsEnabled() {
+		return s.snapshotContent(name, revision)
+	}
+
 	return []byte(""), backend.ErrNotSupported
 }
 
+// Deletions lists all deleted entities that have a snapshot.
+func (s *Store) Deletions(context.Context) ([]backend.Deletion, error) {
+	if s.snapshotsEnabled() {
+		return s.snapshotDeletions()
+	}
+
+	return nil, backend.ErrNotSupported
+}
+
 // Status is not implemented.
 func (s *Store) Status(context.Context) ([]byte, error) {
 	return []byte(""), backend.ErrNotSupported
 }
 
-// Compact is not implemented.
-func (s *Store) Compact(context.Context) error {
-	return nil
+// Compact removes old snapshots according to fs.snapshots-keep and
+// fs.snapshots-max-age.
+func (s *Store) Compact(ctx context.Context) error {
+	if !s.snapshotsEnabled() {
+		return nil
+	}
+
+	return s.snapshotCompact(ctx)
 }
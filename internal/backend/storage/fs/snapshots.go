@@ -0,0 +1,441 @@
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+// SnapshotDir is the directory inside the store that holds the snapshots.
+// Snapshots are only kept if it exists.
+//
+// Every version of a file is stored once in objects/, named after the sha256
+// of its content. meta/ mirrors the layout of the store and contains one
+// log per file. Each line of the log records one revision.
+const SnapshotDir = ".snapshots"
+
+// snapshot is a single revision of a file.
+type snapshot struct {
+	Hash    string    `json:"hash"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author,omitempty"`
+	Email   string    `json:"email,omitempty"`
+	Deleted bool      `json:"deleted,omitempty"`
+}
+
+func (sn snapshot) revision() backend.Revision {
+	return backend.Revision{
+		Hash:        sn.Hash,
+		AuthorName:  sn.Author,
+		AuthorEmail: sn.Email,
+		Date:        sn.Date,
+		Subject:     sn.Subject,
+	}
+}
+
+// snapshotsEnabled returns true if this store keeps snapshots.
+func (s *Store) snapshotsEnabled() bool {
+	return fsutil.IsDir(filepath.Join(s.path, SnapshotDir))
+}
+
+// EnableSnapshots turns on the snapshot mode for this store.
+func (s *Store) EnableSnapshots() error {
+	return os.MkdirAll(filepath.Join(s.path, SnapshotDir, "objects"), 0o700)
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.path, SnapshotDir, "objects", hash[:2], hash)
+}
+
+func (s *Store) metaPath(name string) string {
+	return filepath.Join(s.path, SnapshotDir, "meta", filepath.Clean(name)+".json")
+}
+
+// snapshotSet records the new content of name. If the file existed before
+// snapshots were enabled its previous content is recorded first.
+func (s *Store) snapshotSet(ctx context.Context, name string, old []byte, modTime time.Time, content []byte) error {
+	log, err := s.snapshots(name)
+	if err != nil {
+		return err
+	}
+
+	if len(log) < 1 && old != nil {
+		sn := snapshot{
+			Subject: "Snapshot of existing content",
+			Date:    modTime,
+		}
+		if err := s.snapshotAppend(name, sn, old); err != nil {
+			return err
+		}
+	}
+
+	return s.snapshotAppend(name, s.newSnapshot(ctx, fmt.Sprintf("Save %s", name)), content)
+}
+
+// snapshotDelete records the deletion of name. The deleted content is kept
+// so it can be restored.
+func (s *Store) snapshotDelete(ctx context.Context, name string, old []byte) error {
+	sn := s.newSnapshot(ctx, fmt.Sprintf("Remove %s", name))
+	sn.Deleted = true
+
+	return s.snapshotAppend(name, sn, old)
+}
+
+// snapshotPrune records the deletion of every file below path.
+func (s *Store) snapshotPrune(ctx context.Context, path string) error {
+	snapDir := filepath.Join(s.path, SnapshotDir)
+
+	return filepath.Walk(path, func(fn string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if fn == snapDir {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.path, fn)
+		if err != nil {
+			return err
+		}
+
+		old, err := os.ReadFile(fn)
+		if err != nil {
+			return err
+		}
+
+		return s.snapshotDelete(ctx, filepath.ToSlash(rel), old)
+	})
+}
+
+// snapshotMove records a moved file as a new revision at its destination
+// and, if the source was removed, as a deletion of the source.
+func (s *Store) snapshotMove(ctx context.Context, from, to string, del bool) error {
+	if !s.snapshotsEnabled() {
+		return nil
+	}
+
+	from = filepath.ToSlash(filepath.Clean(from))
+	to = filepath.ToSlash(filepath.Clean(to))
+
+	content, err := os.ReadFile(filepath.Join(s.path, filepath.FromSlash(to)))
+	if err != nil {
+		return err
+	}
+
+	if err := s.snapshotAppend(to, s.newSnapshot(ctx, fmt.Sprintf("Copy %s to %s", from, to)), content); err != nil {
+		return err
+	}
+
+	if !del {
+		return nil
+	}
+
+	return s.snapshotDelete(ctx, from, content)
+}
+
+func (s *Store) newSnapshot(ctx context.Context, subject string) snapshot {
+	if msg := ctxutil.GetCommitMessage(ctx); msg != "" {
+		subject = msg
+	}
+
+	s.identOnce.Do(func() {
+		s.author = termio.DetectName(ctx, nil)
+		s.email = termio.DetectEmail(ctx, nil)
+	})
+
+	return snapshot{
+		Date:    ctxutil.GetCommitTimestamp(ctx),
+		Subject: subject,
+		Author:  s.author,
+		Email:   s.email,
+	}
+}
+
+// snapshotAppend stores content and adds sn to the log of name.
+func (s *Store) snapshotAppend(name string, sn snapshot, content []byte) error {
+	sum := sha256.Sum256(content)
+	sn.Hash = hex.EncodeToString(sum[:])
+
+	fn := s.objectPath(sn.Hash)
+	if !fsutil.IsFile(fn) {
+		if err := os.MkdirAll(filepath.Dir(fn), 0o700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fn, content, 0o600); err != nil {
+			return fmt.Errorf("failed to write snapshot of %s: %w", name, err)
+		}
+	}
+
+	buf, err := json.Marshal(sn)
+	if err != nil {
+		return err
+	}
+
+	mfn := s.metaPath(name)
+	if err := os.MkdirAll(filepath.Dir(mfn), 0o700); err != nil {
+		return err
+	}
+
+	fh, err := os.OpenFile(mfn, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close() //nolint:errcheck
+
+	if _, err := fh.Write(append(buf, '\n')); err != nil {
+		return fmt.Errorf("failed to write snapshot log of %s: %w", name, err)
+	}
+
+	return fh.Close()
+}
+
+// snapshots returns the log of name, oldest first.
+func (s *Store) snapshots(name string) ([]snapshot, error) {
+	buf, err := os.ReadFile(s.metaPath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var log []snapshot
+	sc := bufio.NewScanner(bytes.NewReader(buf))
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+
+		var sn snapshot
+		if err := json.Unmarshal(sc.Bytes(), &sn); err != nil {
+			return nil, fmt.Errorf("invalid snapshot log for %s: %w", name, err)
+		}
+		log = append(log, sn)
+	}
+
+	return log, sc.Err()
+}
+
+func (s *Store) writeSnapshots(name string, log []snapshot) error {
+	mfn := s.metaPath(name)
+	if len(log) < 1 {
+		return os.Remove(mfn)
+	}
+
+	buf := &bytes.Buffer{}
+	for _, sn := range log {
+		line, err := json.Marshal(sn)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return os.WriteFile(mfn, buf.Bytes(), 0o600)
+}
+
+// snapshotNames returns the names of all files with a snapshot log.
+func (s *Store) snapshotNames() ([]string, error) {
+	dir := filepath.Join(s.path, SnapshotDir, "meta")
+	if !fsutil.IsDir(dir) {
+		return nil, nil
+	}
+
+	var names []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, strings.TrimSuffix(path, ".json"))
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+
+		return nil
+	})
+
+	return names, err
+}
+
+// snapshotRevisions lists the snapshots of name, newest first. Files without
+// a snapshot log only have their current version.
+func (s *Store) snapshotRevisions(name string) ([]backend.Revision, error) {
+	log, err := s.snapshots(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(log) < 1 {
+		fi, err := os.Stat(filepath.Join(s.path, filepath.Clean(name)))
+		if err != nil {
+			return nil, err
+		}
+
+		return []backend.Revision{
+			{
+				Hash: "latest",
+				Date: fi.ModTime(),
+			},
+		}, nil
+	}
+
+	revs := make([]backend.Revision, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		revs = append(revs, log[i].revision())
+	}
+
+	return revs, nil
+}
+
+// snapshotContent returns the content of name at the given revision.
+func (s *Store) snapshotContent(name, revision string) ([]byte, error) {
+	log, err := s.snapshots(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sn := range log {
+		if sn.Hash == revision {
+			return os.ReadFile(s.objectPath(sn.Hash))
+		}
+	}
+
+	return nil, fmt.Errorf("revision %s of %s not found", revision, name)
+}
+
+// snapshotDeletions lists all files whose last snapshot is a deletion,
+// newest first.
+func (s *Store) snapshotDeletions() ([]backend.Deletion, error) {
+	names, err := s.snapshotNames()
+	if err != nil {
+		return nil, err
+	}
+
+	dels := []backend.Deletion{}
+	for _, name := range names {
+		log, err := s.snapshots(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(log) < 1 || !log[len(log)-1].Deleted || fsutil.IsFile(filepath.Join(s.path, filepath.Clean(name))) {
+			continue
+		}
+
+		dels = append(dels, backend.Deletion{
+			Revision: log[len(log)-1].revision(),
+			Name:     name,
+		})
+	}
+
+	sort.SliceStable(dels, func(i, j int) bool {
+		return dels[i].Date.After(dels[j].Date)
+	})
+
+	return dels, nil
+}
+
+// snapshotCompact applies the retention policy and removes all snapshots
+// that are no longer referenced. The latest snapshot of an existing file is
+// always kept.
+func (s *Store) snapshotCompact(ctx context.Context) error {
+	keep := config.Int(ctx, "fs.snapshots-keep")
+	maxAge := time.Duration(config.Int(ctx, "fs.snapshots-max-age")) * 24 * time.Hour
+
+	names, err := s.snapshotNames()
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		log, err := s.snapshots(name)
+		if err != nil {
+			return err
+		}
+
+		exists := fsutil.IsFile(filepath.Join(s.path, filepath.Clean(name)))
+		pruned := pruneSnapshots(log, keep, maxAge, exists)
+		if len(pruned) != len(log) {
+			debug.Log("removing %d snapshots of %s", len(log)-len(pruned), name)
+			if err := s.writeSnapshots(name, pruned); err != nil {
+				return err
+			}
+		}
+
+		for _, sn := range pruned {
+			used[sn.Hash] = true
+		}
+	}
+
+	objects := filepath.Join(s.path, SnapshotDir, "objects")
+
+	return filepath.Walk(objects, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || used[info.Name()] {
+			return nil
+		}
+
+		debug.Log("removing unused snapshot %s", info.Name())
+
+		return os.Remove(path)
+	})
+}
+
+// pruneSnapshots keeps at most keep snapshots, none older than maxAge. Zero
+// values disable the respective limit.
+func pruneSnapshots(log []snapshot, keep int, maxAge time.Duration, exists bool) []snapshot {
+	cutoff := time.Time{}
+	if maxAge > 0 {
+		cutoff = time.Now().Add(-maxAge)
+	}
+
+	pruned := make([]snapshot, 0, len(log))
+	for i, sn := range log {
+		latest := i == len(log)-1
+		if latest && exists {
+			pruned = append(pruned, sn)
+
+			continue
+		}
+		if keep > 0 && len(log)-i > keep {
+			continue
+		}
+		if sn.Date.Before(cutoff) {
+			continue
+		}
+		pruned = append(pruned, sn)
+	}
+
+	return pruned
+}
@@ -0,0 +1,140 @@
+package fs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshots(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	s := New(t.TempDir())
+
+	// existing content is recorded on the first write.
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("first")))
+	require.NoError(t, s.EnableSnapshots())
+	require.NoError(t, s.Set(ctxutil.WithCommitMessage(ctx, "changed foo"), "foo.gpg", []byte("second")))
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("third")))
+
+	list, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo.gpg"}, list)
+
+	revs, err := s.Revisions(ctx, "foo.gpg")
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+	assert.Equal(t, "Save foo.gpg", revs[0].Subject)
+	assert.Equal(t, "changed foo", revs[1].Subject)
+	assert.Equal(t, "Snapshot of existing content", revs[2].Subject)
+
+	for i, want := range []string{"third", "second", "first"} {
+		content, err := s.GetRevision(ctx, "foo.gpg", revs[i].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content))
+	}
+
+	_, err = s.GetRevision(ctx, "foo.gpg", "0000")
+	require.Error(t, err)
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, s.Set(ctx, "bar/baz.gpg", []byte("baz")))
+		require.NoError(t, s.Delete(ctx, "bar/baz.gpg"))
+
+		dels, err := s.Deletions(ctx)
+		require.NoError(t, err)
+		require.Len(t, dels, 1)
+		assert.Equal(t, "bar/baz.gpg", dels[0].Name)
+
+		content, err := s.GetRevision(ctx, "bar/baz.gpg", dels[0].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "baz", string(content))
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		require.NoError(t, s.Set(ctx, "team/a.gpg", []byte("a")))
+		require.NoError(t, s.Set(ctx, "team/sub/b.gpg", []byte("b")))
+		require.NoError(t, s.Prune(ctx, "team"))
+		assert.False(t, s.IsDir(ctx, "team"))
+
+		dels, err := s.Deletions(ctx)
+		require.NoError(t, err)
+		require.Len(t, dels, 3)
+
+		for name, want := range map[string]string{"team/a.gpg": "a", "team/sub/b.gpg": "b"} {
+			revs, err := s.Revisions(ctx, name)
+			require.NoError(t, err)
+			require.Len(t, revs, 2)
+			content, err := s.GetRevision(ctx, name, revs[0].Hash)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(content))
+		}
+	})
+
+	t.Run("move", func(t *testing.T) {
+		require.NoError(t, s.Move(ctx, "foo.gpg", "zab.gpg", true))
+
+		revs, err := s.Revisions(ctx, "zab.gpg")
+		require.NoError(t, err)
+		require.Len(t, revs, 1)
+		assert.Equal(t, "Copy foo.gpg to zab.gpg", revs[0].Subject)
+
+		dels, err := s.Deletions(ctx)
+		require.NoError(t, err)
+		assert.Len(t, dels, 4)
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		cfg := config.NewInMemory()
+		require.NoError(t, cfg.Set("", "fs.snapshots-keep", "1"))
+		ctx := cfg.WithConfig(ctx)
+
+		old, err := s.Revisions(ctx, "foo.gpg")
+		require.NoError(t, err)
+		require.Len(t, old, 4)
+
+		require.NoError(t, s.Compact(ctx))
+
+		revs, err := s.Revisions(ctx, "zab.gpg")
+		require.NoError(t, err)
+		assert.Len(t, revs, 1)
+
+		// only the deletion is kept.
+		revs, err = s.Revisions(ctx, "foo.gpg")
+		require.NoError(t, err)
+		require.Len(t, revs, 1)
+		content, err := s.GetRevision(ctx, "foo.gpg", revs[0].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "third", string(content))
+
+		// the older revisions are gone.
+		for _, rev := range old[2:] {
+			_, err := s.GetRevision(ctx, "foo.gpg", rev.Hash)
+			require.Error(t, err)
+			assert.NoFileExists(t, s.objectPath(rev.Hash))
+		}
+	})
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	log := []snapshot{
+		{Hash: "a", Date: now.Add(-72 * time.Hour)},
+		{Hash: "b", Date: now.Add(-48 * time.Hour)},
+		{Hash: "c", Date: now.Add(-24 * time.Hour)},
+	}
+
+	assert.Len(t, pruneSnapshots(log, 0, 0, true), 3)
+	assert.Len(t, pruneSnapshots(log, 2, 0, true), 2)
+	assert.Len(t, pruneSnapshots(log, 0, 36*time.Hour, true), 1)
+	// the latest snapshot of an existing file is always kept.
+	assert.Len(t, pruneSnapshots(log, 0, time.Hour, true), 1)
+	assert.Empty(t, pruneSnapshots(log, 0, time.Hour, false))
+}
@@ -11,6 +11,8 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/gopasspw/gopass/internal/store"
@@ -21,6 +23,11 @@ import (
 // Store is a fs based store.
 type Store struct {
 	path string
+
+	// the author of snapshots is detected once.
+	identOnce sync.Once
+	author    string
+	email     string
 }
 
 // New creates a new store.
@@ -71,7 +78,20 @@ func (s *Store) Set(ctx context.Context, name string, value []byte) error {
 		return store.ErrMeaninglessWrite
 	}
 
-	return os.WriteFile(filename, value, 0o644)
+	var modTime time.Time
+	if fi, err := os.Stat(filename); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	if err := os.WriteFile(filename, value, 0o644); err != nil {
+		return err
+	}
+
+	if !s.snapshotsEnabled() {
+		return nil
+	}
+
+	return s.snapshotSet(ctx, filepath.ToSlash(filepath.Clean(name)), oldvalue, modTime, value)
 }
 
 // Move moves the named entity to the new location.
@@ -97,10 +117,18 @@ func (s *Store) Move(ctx context.Context, from, to string, del bool) error {
 			return fmt.Errorf("failed to copy %q to %q: %w", from, to, err)
 		}
 
+		if err := s.snapshotMove(ctx, from, to, del); err != nil {
+			return err
+		}
+
 		return s.removeEmptyParentDirectories(fromFn)
 	}
 
-	return fsutil.CopyFile(fromFn, toFn)
+	if err := fsutil.CopyFile(fromFn, toFn); err != nil {
+		return err
+	}
+
+	return s.snapshotMove(ctx, from, to, del)
 }
 
 // Delete removes the named entity.
@@ -111,10 +139,25 @@ func (s *Store) Delete(ctx context.Context, name string) error {
 	path := filepath.Join(s.path, filepath.Clean(name))
 	debug.V(3).Log("Deleting %s from %s", name, path)
 
+	var oldvalue []byte
+	if s.snapshotsEnabled() {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		oldvalue = buf
+	}
+
 	if err := os.Remove(path); err != nil {
 		return err
 	}
 
+	if oldvalue != nil {
+		if err := s.snapshotDelete(ctx, filepath.ToSlash(filepath.Clean(name)), oldvalue); err != nil {
+			return err
+		}
+	}
+
 	return s.removeEmptyParentDirectories(path)
 }
 
@@ -217,6 +260,12 @@ func (s *Store) Prune(ctx context.Context, prefix string) error {
 	path := filepath.Join(s.path, filepath.Clean(prefix))
 	debug.Log("Purning %s from %s", prefix, path)
 
+	if s.snapshotsEnabled() {
+		if err := s.snapshotPrune(ctx, path); err != nil {
+			return err
+		}
+	}
+
 	if err := os.RemoveAll(path); err != nil {
 		return err
 	}
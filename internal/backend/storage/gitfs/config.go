@@ -3,10 +3,9 @@ package gitfs
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/merge"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
 )
@@ -38,6 +37,14 @@ func (g *Git) fixConfig(ctx context.Context) error {
 		out.Errorf(ctx, "Error while initializing git: %s", err)
 	}
 
+	// setup the merge driver for encrypted secrets, see .gitattributes.
+	if err := g.ConfigSet(ctx, "merge.gopass.name", merge.DriverName); err != nil {
+		out.Errorf(ctx, "Error while initializing git: %s", err)
+	}
+	if err := g.ConfigSet(ctx, "merge.gopass.driver", merge.DriverCommand); err != nil {
+		out.Errorf(ctx, "Error while initializing git: %s", err)
+	}
+
 	// setup for persistent SSH connections.
 	if sc := gitSSHCommand(); sc != "" {
 		ov, err := g.ConfigGet(ctx, "core.sshCommand")
@@ -75,14 +82,8 @@ func (g *Git) InitConfig(ctx context.Context, userName, userEmail string) error
 		return fmt.Errorf("failed to fix git config: %w", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(g.fs.Path(), ".gitattributes"), []byte("*.gpg diff=gpg\n"), fileMode); err != nil {
-		return fmt.Errorf("failed to initialize git: %w", err)
-	}
-	if err := g.Add(ctx, g.fs.Path()+"/.gitattributes"); err != nil {
-		out.Warningf(ctx, "Failed to add .gitattributes to git")
-	}
-	if err := g.Commit(ctx, "Configure git repository for gpg file diff."); err != nil {
-		out.Warningf(ctx, "Failed to commit .gitattributes to git")
+	if err := merge.WriteAttributes(ctx, g, g.fs.Path()); err != nil {
+		out.Warningf(ctx, "Failed to configure .gitattributes: %s", err)
 	}
 
 	return nil
@@ -3,10 +3,12 @@ package gitfs
 import (
 	"bytes"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/merge"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/stretchr/testify/assert"
@@ -37,8 +39,60 @@ func TestGitConfig(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Foo Bar", un)
 
+	md, err := git.ConfigGet(ctx, "merge.gopass.driver")
+	require.NoError(t, err)
+	assert.Equal(t, "gopass git merge-driver %O %A %B %P", md)
+
+	ga, err := os.ReadFile(filepath.Join(gitdir, ".gitattributes"))
+	require.NoError(t, err)
+	assert.Contains(t, string(ga), "*.age merge=gopass")
+
 	require.NoError(t, git.ConfigSet(ctx, "user.name", "foo"))
 	un, err = git.ConfigGet(ctx, "user.name")
 	require.NoError(t, err)
 	assert.Equal(t, "foo", un)
 }
+
+func TestFsckAddsMergeDriver(t *testing.T) {
+	gitdir := filepath.Join(t.TempDir(), "git")
+	require.NoError(t, os.Mkdir(gitdir, 0o755))
+
+	ctx := config.NewContextInMemory()
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	// a store that was set up by an older version.
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Dead Beef"},
+		{"config", "user.email", "dead.beef@example.org"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", gitdir}, args...)...)
+		require.NoError(t, cmd.Run())
+	}
+	fn := filepath.Join(gitdir, ".gitattributes")
+	require.NoError(t, os.WriteFile(fn, []byte("*.gpg diff=gpg\n*.txt text\n"), 0o644))
+
+	st, err := loader{}.Open(ctx, gitdir)
+	require.NoError(t, err)
+
+	md, err := st.(*Git).ConfigGet(ctx, "merge.gopass.driver")
+	require.NoError(t, err)
+	assert.Empty(t, md, "open must not change the repository")
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, st.Fsck(ctx))
+
+		md, err := st.(*Git).ConfigGet(ctx, "merge.gopass.driver")
+		require.NoError(t, err)
+		assert.Equal(t, merge.DriverCommand, md)
+
+		ga, err := os.ReadFile(fn)
+		require.NoError(t, err)
+		assert.Equal(t, "*.gpg diff=gpg merge=gopass\n*.txt text\n*.age merge=gopass\n", string(ga))
+	}
+}
@@ -0,0 +1,53 @@
+package gitfs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+)
+
+// Conflicts returns the files with unresolved merge conflicts. The versions
+// of each side are available as the revisions ":1" (base), ":2" (ours) and
+// ":3" (theirs) until the merge is committed.
+func (g *Git) Conflicts(ctx context.Context) ([]string, error) {
+	stdout, stderr, err := g.captureCmd(ctx, "Conflicts", "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+
+		return nil, err
+	}
+
+	var files []string
+	for _, fn := range strings.Split(string(stdout), "\n") {
+		if fn = strings.TrimSpace(fn); fn != "" {
+			files = append(files, fn)
+		}
+	}
+
+	return files, nil
+}
+
+// isMerging returns true if a merge was stopped and is not committed, yet.
+func (g *Git) isMerging() bool {
+	return fsutil.IsFile(filepath.Join(g.fs.Path(), ".git", "MERGE_HEAD"))
+}
+
+// checkConflicts wraps the error of a failed pull or merge with
+// store.ErrGitConflict if it stopped with merge conflicts.
+func (g *Git) checkConflicts(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	files, cerr := g.Conflicts(ctx)
+	if cerr != nil || len(files) < 1 {
+		return err
+	}
+
+	return fmt.Errorf("%w in %s: %w", store.ErrGitConflict, strings.Join(files, ", "), err)
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -77,6 +78,17 @@ func Clone(ctx context.Context, repo, path, userName, userEmail string) (*Git, e
 
 	g.cfg.LoadAll(filepath.Join(path, ".git"))
 
+	// verify the clone before we write anything to it.
+	if backend.IsRequireSignedCommits(ctx) {
+		if err := g.RequireSignedCommits(ctx); err != nil {
+			if err := os.RemoveAll(path); err != nil {
+				debug.Log("Failed to remove %s: %s", path, err)
+			}
+
+			return nil, err
+		}
+	}
+
 	// initialize the local git config.
 	if err := g.InitConfig(ctx, userName, userEmail); err != nil {
 		return g, fmt.Errorf("failed to configure git: %w", err)
@@ -281,11 +293,19 @@ func (g *Git) Commit(ctx context.Context, msg string) error {
 		return store.ErrGitNotInit
 	}
 
-	if !g.HasStagedChanges(ctx) {
+	// a merge must be concluded even if the result equals our side.
+	if !g.HasStagedChanges(ctx) && !g.isMerging() {
 		return store.ErrGitNothingToCommit
 	}
 
-	return g.Cmd(ctx, "gitCommit", "commit", fmt.Sprintf("--date=%d +00:00", ctxutil.GetCommitTimestamp(ctx).UTC().Unix()), "-m", msg)
+	args := []string{"commit", fmt.Sprintf("--date=%d +00:00", ctxutil.GetCommitTimestamp(ctx).UTC().Unix()), "-m", msg}
+	// sign our commits if the store requires it or if there is a signing key,
+	// either for the store or in the global git config.
+	if g.requireSigned() || g.cfg.Get("user.signingkey") != "" {
+		args = append(args, "-S")
+	}
+
+	return g.Cmd(ctx, "gitCommit", args...)
 }
 
 // TryCommit calls commit and returns nil if there was nothing to commit or if the git repo was not initialized.
@@ -342,48 +362,81 @@ func (g *Git) defaultBranch(ctx context.Context) string {
 // PushPull pushes the repo to it's origin.
 // optional arguments: remote and branch.
 func (g *Git) PushPull(ctx context.Context, op, remote, branch string) error {
+	_, err := g.pushPull(ctx, op, remote, branch)
+
+	return err
+}
+
+// PushRemotes pulls from the primary remote and pushes to all configured
+// remotes. It returns the result for each remote.
+func (g *Git) PushRemotes(ctx context.Context) ([]backend.RemoteStatus, error) {
+	return g.pushPull(ctx, "push", "", "")
+}
+
+func (g *Git) pushPull(ctx context.Context, op, remote, branch string) ([]backend.RemoteStatus, error) {
 	if ctxutil.IsNoNetwork(ctx) {
 		debug.Log("Skipping network ops. NoNetwork=true")
 
-		return nil
+		return nil, nil
 	}
 	if !g.IsInitialized() {
 		debug.Log("Git in %s is not initialized. Can not push/pull", g.Path())
 
-		return store.ErrGitNotInit
+		return nil, store.ErrGitNotInit
 	}
 
 	if branch == "" {
 		branch = g.defaultBranch(ctx)
 	}
 
+	remotes := []string{remote}
 	if remote == "" {
-		remote = g.defaultRemote(ctx, branch)
+		remotes = g.remotes(ctx, branch)
 	}
 
-	urlKey := "remote." + remote + ".url"
-	if v, err := g.ConfigGet(ctx, urlKey); err != nil || v == "" {
-		debug.Log("No value for %q found in config. Keys: %+v", urlKey, g.cfg.Keys())
-
-		return store.ErrGitNoRemote
+	remotes = g.filterRemotes(ctx, remotes)
+	if len(remotes) < 1 {
+		return nil, store.ErrGitNoRemote
 	}
 
-	if err := g.Cmd(ctx, "gitPush", "pull", remote, branch); err != nil {
-		if op == "pull" {
-			return err
+	// fetch from the first remote that is reachable. Only a failed fetch
+	// falls back to the next remote, errors while merging are returned.
+	err := g.fetch(ctx, remotes, branch)
+	if err == nil {
+		err = g.merge(ctx)
+	}
+	if err != nil {
+		if op == "pull" || errors.Is(err, store.ErrGitUnverifiedCommits) || errors.Is(err, store.ErrGitConflict) {
+			return nil, err
 		}
 		out.Warningf(ctx, "Failed to pull before git push: %s", err)
 	}
 
 	if op == "pull" {
-		return nil
+		return nil, nil
 	}
 
 	if uf := g.ListUntrackedFiles(ctx); len(uf) > 0 {
 		out.Warningf(ctx, "Found untracked files: %+v", uf)
 	}
 
-	return g.Cmd(ctx, "gitPush", "push", remote, branch)
+	if len(remotes) == 1 {
+		err := g.Cmd(ctx, "gitPush", "push", remotes[0], branch)
+
+		return []backend.RemoteStatus{{Remote: remotes[0], Err: err}}, err
+	}
+
+	status := make([]backend.RemoteStatus, 0, len(remotes))
+	var errs []error
+	for _, r := range remotes {
+		err := g.Cmd(ctx, "gitPush", "push", r, branch)
+		status = append(status, backend.RemoteStatus{Remote: r, Err: err})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to push to %s: %w", r, err))
+		}
+	}
+
+	return status, errors.Join(errs...)
 }
 
 // TryPush calls Push and returns nil if the git repo was not initialized.
@@ -431,11 +484,16 @@ func (g *Git) Pull(ctx context.Context, remote, branch string) error {
 
 // AddRemote adds a new remote.
 func (g *Git) AddRemote(ctx context.Context, remote, url string) error {
+	// git rewrites the config, make sure we don't overwrite it with a stale copy.
+	defer g.cfg.Reload()
+
 	return g.Cmd(ctx, "gitAddRemote", "remote", "add", remote, url)
 }
 
 // RemoveRemote removes a remote.
 func (g *Git) RemoveRemote(ctx context.Context, remote string) error {
+	defer g.cfg.Reload()
+
 	return g.Cmd(ctx, "gitRemoveRemote", "remote", "remove", remote)
 }
 
@@ -464,41 +522,83 @@ func (g *Git) Revisions(ctx context.Context, name string) ([]backend.Revision, e
 			continue
 		}
 
-		p := strings.Split(rev, "\x1f")
-		if len(p) < 1 {
-			continue
-		}
+		revs = append(revs, parseRevision(rev))
+	}
 
-		r := backend.Revision{}
-		r.Hash = p[0]
-		if len(p) > 1 {
-			r.AuthorName = p[1]
-		}
+	debug.Log("Revisions for %s: %+v", name, revs)
 
-		if len(p) > 2 {
-			r.AuthorEmail = p[2]
-		}
+	return revs, nil
+}
 
-		if len(p) > 3 {
-			if iv, err := strconv.ParseInt(p[3], 10, 64); err == nil {
-				r.Date = time.Unix(iv, 0)
-			}
-		}
+// parseRevision parses a single revision in the format used by Revisions.
+func parseRevision(rev string) backend.Revision {
+	p := strings.Split(rev, "\x1f")
 
-		if len(p) > 4 {
-			r.Subject = p[4]
-		}
+	r := backend.Revision{}
+	r.Hash = p[0]
+	if len(p) > 1 {
+		r.AuthorName = p[1]
+	}
 
-		if len(p) > 5 {
-			r.Body = p[5]
+	if len(p) > 2 {
+		r.AuthorEmail = p[2]
+	}
+
+	if len(p) > 3 {
+		if iv, err := strconv.ParseInt(p[3], 10, 64); err == nil {
+			r.Date = time.Unix(iv, 0)
 		}
+	}
 
-		revs = append(revs, r)
+	if len(p) > 4 {
+		r.Subject = p[4]
 	}
 
-	debug.Log("Revisions for %s: %+v", name, revs)
+	if len(p) > 5 {
+		r.Body = p[5]
+	}
 
-	return revs, nil
+	return r
+}
+
+// Deletions lists all files that were deleted in the history of the
+// repository, newest first. Files deleted more than once are only listed with
+// their latest deletion.
+func (g *Git) Deletions(ctx context.Context) ([]backend.Deletion, error) {
+	args := []string{
+		"log",
+		"--diff-filter=D",
+		"--name-only",
+		`--format=%x1e%H%x1f%an%x1f%ae%x1f%at%x1f%s`,
+	}
+	stdout, stderr, err := g.captureCmd(ctx, "Deletions", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	dels := []backend.Deletion{}
+	for _, rec := range strings.Split(string(stdout), "\x1e") {
+		header, files, _ := strings.Cut(strings.TrimSpace(rec), "\n")
+		if header == "" {
+			continue
+		}
+
+		rev := parseRevision(header)
+		for _, fn := range strings.Split(files, "\n") {
+			fn = strings.TrimSpace(fn)
+			if fn == "" || seen[fn] {
+				continue
+			}
+			seen[fn] = true
+
+			dels = append(dels, backend.Deletion{Revision: rev, Name: fn})
+		}
+	}
+
+	return dels, nil
 }
 
 // GetRevision will return the content of any revision of the named entity
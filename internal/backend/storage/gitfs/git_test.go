@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/blang/semver/v4"
+	"github.com/gopasspw/gopass/internal/backend"
+	_ "github.com/gopasspw/gopass/internal/backend/storage/gogit"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -80,6 +82,64 @@ func TestGit(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "foobar", string(content))
 	})
+
+	t.Run("list deletions", func(t *testing.T) {
+		git, err := New(gitdir2)
+		require.NoError(t, err)
+
+		dels, err := git.Deletions(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, dels)
+
+		require.NoError(t, os.Remove(filepath.Join(gitdir2, "some-other-file")))
+		require.NoError(t, git.Add(ctx, "some-other-file"))
+		require.NoError(t, git.Commit(ctx, "removed some-other-file"))
+
+		dels, err = git.Deletions(ctx)
+		require.NoError(t, err)
+		require.Len(t, dels, 1)
+		assert.Equal(t, "some-other-file", dels[0].Name)
+		assert.Equal(t, "removed some-other-file", dels[0].Subject)
+
+		revs, err := git.Revisions(ctx, "some-other-file")
+		require.NoError(t, err)
+		require.Len(t, revs, 2)
+		assert.Equal(t, dels[0].Hash, revs[0].Hash)
+
+		content, err := git.GetRevision(ctx, "some-other-file", revs[1].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", string(content))
+	})
+}
+
+func TestInitWithoutGit(t *testing.T) {
+	td := t.TempDir()
+	gitdir := filepath.Join(td, "git")
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+	}()
+
+	t.Setenv("GIT_AUTHOR_NAME", "gopass")
+	t.Setenv("GIT_AUTHOR_EMAIL", "gopass@example.org")
+	t.Setenv("PATH", t.TempDir())
+
+	st, err := loader{}.Init(ctx, gitdir)
+	require.NoError(t, err)
+	assert.Equal(t, "gogit", st.Name())
+	assert.Contains(t, buf.String(), "git not found")
+
+	// the repository is marked as gogit, so detection doesn't warn again.
+	buf.Reset()
+	st, err = backend.DetectStorage(ctx, gitdir)
+	require.NoError(t, err)
+	assert.Equal(t, "gogit", st.Name())
+	assert.Empty(t, buf.String())
 }
 
 func TestParseVersion(t *testing.T) {
@@ -3,10 +3,14 @@ package gitfs
 import (
 	"context"
 	"fmt"
+	"os/exec"
 	"path/filepath"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/gitconfig"
 	"github.com/gopasspw/gopass/pkg/termio"
 )
 
@@ -31,20 +35,58 @@ func (l loader) Open(ctx context.Context, path string) (backend.Storage, error)
 
 // Clone implements backend.RCSLoader.
 func (l loader) Clone(ctx context.Context, repo, path string) (backend.Storage, error) {
+	if be := fallback(ctx, path); be != nil {
+		return be.Clone(ctx, repo, path)
+	}
+
 	return Clone(ctx, repo, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
 }
 
 // Init implements backend.RCSLoader.
 func (l loader) Init(ctx context.Context, path string) (backend.Storage, error) {
+	if be := fallback(ctx, path); be != nil {
+		return be.Init(ctx, path)
+	}
+
 	return Init(ctx, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
 }
 
+// fallback returns the gogit backend if there is no git binary. gogit marks
+// the repositories it sets up, so we only need to warn here and not every
+// time the store is opened.
+func fallback(ctx context.Context, path string) backend.StorageLoader {
+	if _, err := exec.LookPath("git"); err == nil {
+		return nil
+	}
+
+	be, err := backend.StorageRegistry.Get(backend.GoGit)
+	if err != nil {
+		debug.Log("git not found and no fallback available: %s", err)
+
+		return nil
+	}
+
+	out.Warningf(ctx, "git not found, using %s instead of %s for %s. Install git to use %s.", be, name, path, name)
+
+	return be
+}
+
 func (l loader) Handles(ctx context.Context, path string) error {
 	path = fsutil.ExpandHomedir(path)
-	if !fsutil.IsDir(filepath.Join(path, ".git")) {
+	gitDir := filepath.Join(path, ".git")
+	if !fsutil.IsDir(gitDir) {
 		return fmt.Errorf("no .git at %s", path)
 	}
 
+	// repositories set up by gogit should keep using it.
+	if be := gitconfig.New().LoadAll(gitDir).GetLocal("gopass.storage"); be != "" && be != name {
+		return fmt.Errorf("%s is managed by %s", path, be)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git not found: %w", err)
+	}
+
 	return nil
 }
 
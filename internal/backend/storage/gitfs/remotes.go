@@ -0,0 +1,40 @@
+package gitfs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// remotes returns the remotes to pull from and push to. They are listed in
+// git.remotes in the config of the mount, e.g. "origin backup". The first one
+// is the primary remote, all others are mirrors. Without a configured list
+// this is the remote of the current branch.
+func (g *Git) remotes(ctx context.Context, branch string) []string {
+	v := config.String(ctx, "git.remotes")
+	if v == "" {
+		return []string{g.defaultRemote(ctx, branch)}
+	}
+
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// filterRemotes removes remotes without an URL.
+func (g *Git) filterRemotes(ctx context.Context, remotes []string) []string {
+	filtered := make([]string, 0, len(remotes))
+	for _, remote := range remotes {
+		urlKey := "remote." + remote + ".url"
+		if v, err := g.ConfigGet(ctx, urlKey); err != nil || v == "" {
+			debug.Log("No value for %q found in config. Keys: %+v", urlKey, g.cfg.Keys())
+
+			continue
+		}
+		filtered = append(filtered, remote)
+	}
+
+	return filtered
+}
@@ -0,0 +1,107 @@
+package gitfs
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorRemotes(t *testing.T) {
+	td := t.TempDir()
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	primary := filepath.Join(td, "primary.git")
+	backup := filepath.Join(td, "backup.git")
+	for _, dir := range []string{primary, backup} {
+		cmd := exec.Command("git", "init", "--bare", "-b", "master", dir)
+		require.NoError(t, cmd.Run())
+	}
+
+	gitdir := filepath.Join(td, "git")
+	require.NoError(t, os.Mkdir(gitdir, 0o755))
+	g, err := Init(ctx, gitdir, "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+
+	commit := func(t *testing.T, content string) {
+		t.Helper()
+
+		require.NoError(t, g.Set(ctx, "foo", []byte(content)))
+		require.NoError(t, g.Add(ctx, "foo"))
+		require.NoError(t, g.Commit(ctx, "update foo"))
+	}
+
+	commit(t, "initial")
+	require.NoError(t, g.AddRemote(ctx, "origin", "file://"+primary))
+	require.NoError(t, g.AddRemote(ctx, "backup", "file://"+backup))
+	cfg, _ := config.FromContext(ctx)
+	require.NoError(t, cfg.Set("", "git.remotes", "origin, backup"))
+	branch := g.defaultBranch(ctx)
+	assert.Equal(t, []string{"origin", "backup"}, g.remotes(ctx, branch))
+
+	t.Run("push to all remotes", func(t *testing.T) {
+		status, err := g.PushRemotes(ctx)
+		require.NoError(t, err)
+		require.Len(t, status, 2)
+		assert.Equal(t, "origin", status[0].Remote)
+		assert.Equal(t, "backup", status[1].Remote)
+
+		for _, dir := range []string{primary, backup} {
+			cmd := exec.Command("git", "--git-dir", dir, "show", branch+":foo")
+			content, err := cmd.Output()
+			require.NoError(t, err)
+			assert.Equal(t, "initial", string(content))
+		}
+	})
+
+	gitdir2 := filepath.Join(td, "git2")
+	g2, err := Clone(ctx, "file://"+primary, gitdir2, "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+	require.NoError(t, g2.AddRemote(ctx, "backup", "file://"+backup))
+
+	// the primary remote becomes unreachable.
+	require.NoError(t, os.Rename(primary, primary+".down"))
+
+	t.Run("report failed remotes", func(t *testing.T) {
+		commit(t, "changed")
+
+		status, err := g.PushRemotes(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to push to origin")
+		require.Len(t, status, 2)
+		require.Error(t, status[0].Err)
+		require.NoError(t, status[1].Err)
+	})
+
+	t.Run("pull falls back to mirror", func(t *testing.T) {
+		buf.Reset()
+		require.NoError(t, g2.Pull(ctx, "", ""))
+		assert.Contains(t, buf.String(), "Failed to fetch from origin, trying backup")
+
+		content, err := g2.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "changed", string(content))
+	})
+
+	t.Run("explicit remote", func(t *testing.T) {
+		require.Error(t, g2.Pull(ctx, "origin", ""))
+		require.NoError(t, g2.Pull(ctx, "backup", ""))
+	})
+}
@@ -0,0 +1,327 @@
+package gitfs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// requireSignedKey is set in the local git config of stores that only
+	// accept signed commits from their remotes.
+	requireSignedKey = "gopass.require-signed"
+	// signersFile lists the keys that may sign commits. Without it the
+	// recipients of the store are used.
+	signersFile = ".gopass-signers"
+	// allowedSignersFile is the ssh allowed signers file generated from the
+	// list of signers. It lives in the .git folder.
+	allowedSignersFile = "gopass-allowed-signers"
+)
+
+// recipientFiles are used to derive the signers if there is no signers file.
+var recipientFiles = []string{".gpg-id", ".age-recipients"}
+
+// signers are the keys that are allowed to sign commits.
+type signers struct {
+	gpg []string
+	ssh []string
+}
+
+// RequireSignedCommits enables signature verification for this store. The
+// signers listed in the first commit are trusted on first use. Every commit
+// of the history must be signed by a signer of its parent. Later pulls only
+// accept commits that are signed by a signer of the previously trusted state.
+func (g *Git) RequireSignedCommits(ctx context.Context) error {
+	if err := g.verifyHistory(ctx, "", "HEAD"); err != nil {
+		return err
+	}
+
+	return g.ConfigSet(ctx, requireSignedKey, "true")
+}
+
+func (g *Git) requireSigned() bool {
+	return g.cfg.GetLocal(requireSignedKey) == "true"
+}
+
+// fetch fetches the branch from the first of the remotes that can be
+// reached.
+func (g *Git) fetch(ctx context.Context, remotes []string, branch string) error {
+	var err error
+	for i, r := range remotes {
+		if err = g.Cmd(ctx, "gitFetch", "fetch", r, branch); err == nil {
+			return nil
+		}
+		if i < len(remotes)-1 {
+			out.Warningf(ctx, "Failed to fetch from %s, trying %s: %s", r, remotes[i+1], err)
+		}
+	}
+
+	return err
+}
+
+// merge merges the fetched changes. If the store requires signed commits
+// they are verified first.
+func (g *Git) merge(ctx context.Context) error {
+	if g.requireSigned() {
+		// the local HEAD is trusted, new commits must be signed by a signer
+		// of their parent.
+		base := "HEAD"
+		if err := g.Cmd(ctx, "gitRevParse", "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+			// there is nothing local to trust, yet.
+			base = ""
+		}
+
+		if err := g.verifyHistory(ctx, base, "FETCH_HEAD"); err != nil {
+			return err
+		}
+	}
+
+	return g.checkConflicts(ctx, g.Cmd(ctx, "gitMerge", "merge", "FETCH_HEAD"))
+}
+
+// verifyHistory checks the signatures of all commits that are reachable from
+// rev, but not from base. Each commit must be signed by a signer of its first
+// parent. The signers of a root commit are trusted on first use.
+func (g *Git) verifyHistory(ctx context.Context, base, rev string) error {
+	revRange := rev
+	if base != "" {
+		revRange = base + ".." + rev
+	}
+
+	stdout, stderr, err := g.captureCmd(ctx, "RevList", "rev-list", "--reverse", "--topo-order", "--parents", revRange)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+
+		return err
+	}
+
+	// the signers only need to be read again for commits that changed them.
+	args := append([]string{"log", "--format=%H", "--full-history", revRange, "--", signersFile}, recipientFiles...)
+	changes, stderr, err := g.captureCmd(ctx, "SignerChanges", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+
+		return err
+	}
+	changed := make(map[string]bool, 8)
+	for _, c := range strings.Fields(string(changes)) {
+		changed[c] = true
+	}
+
+	known := make(map[string]*signers, 64)
+	signersAt := func(commit string) *signers {
+		if sig, found := known[commit]; found {
+			return sig
+		}
+		sig := g.signers(ctx, commit)
+		known[commit] = &sig
+
+		return &sig
+	}
+
+	// group the commits by the signers that must have signed them.
+	var order []*signers
+	groups := make(map[*signers][]string, 8)
+	for _, line := range strings.Split(strings.TrimSpace(string(stdout)), "\n") {
+		f := strings.Fields(line)
+		if len(f) < 1 {
+			continue
+		}
+
+		commit, parent := f[0], f[0]
+		if len(f) > 1 {
+			parent = f[1]
+		}
+		trusted := signersAt(parent)
+		if parent != commit && !changed[commit] {
+			known[commit] = trusted
+		}
+
+		if _, found := groups[trusted]; !found {
+			order = append(order, trusted)
+		}
+		groups[trusted] = append(groups[trusted], commit)
+	}
+
+	var total int
+	var rejected []string
+	for _, sig := range order {
+		debug.Log("Verifying %d commits against %+v", len(groups[sig]), *sig)
+		n, rej, err := g.verifyCommits(ctx, *sig, groups[sig])
+		if err != nil {
+			return err
+		}
+		total += n
+		rejected = append(rejected, rej...)
+	}
+
+	if len(rejected) > 0 {
+		return fmt.Errorf("%w: rejected %d of %d commits:\n%s", store.ErrGitUnverifiedCommits, len(rejected), total, strings.Join(rejected, "\n"))
+	}
+
+	if total > 0 {
+		out.Noticef(ctx, "Verified signatures of %d commits", total)
+	}
+
+	return nil
+}
+
+// verifyCommits checks the signatures of the given commits against the
+// signers. It returns the number of commits and the rejected ones.
+func (g *Git) verifyCommits(ctx context.Context, sig signers, commits []string) (int, []string, error) {
+	fn := filepath.Join(g.fs.Path(), ".git", allowedSignersFile)
+	if err := os.WriteFile(fn, sig.allowedSigners(), fileMode); err != nil {
+		return 0, nil, fmt.Errorf("failed to write allowed signers: %w", err)
+	}
+
+	args := []string{
+		"-c", "gpg.ssh.allowedSignersFile=" + fn,
+		"log",
+		"--no-walk=unsorted",
+		"--format=%H%x1f%G?%x1f%GF%x1f%GP%x1f%an <%ae>%x1f%s%x1e",
+	}
+	args = append(args, commits...)
+	stdout, stderr, err := g.captureCmd(ctx, "VerifyCommits", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+
+		return 0, nil, err
+	}
+
+	var total int
+	var rejected []string
+	for _, line := range strings.Split(string(stdout), "\x1e") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		total++
+
+		p := strings.Split(line, "\x1f")
+		if len(p) < 6 {
+			continue
+		}
+
+		if reason := sig.check(p[1], p[2], p[3]); reason != "" {
+			rejected = append(rejected, fmt.Sprintf("  %.12s by %s (%s): %s", p[0], p[4], p[5], reason))
+		}
+	}
+
+	return total, rejected, nil
+}
+
+// signers reads the signers at the given revision.
+func (g *Git) signers(ctx context.Context, rev string) signers {
+	sig := signers{}
+
+	if buf, err := g.GetRevision(ctx, signersFile, rev); err == nil {
+		sig.parse(buf)
+
+		return sig
+	}
+
+	for _, fn := range recipientFiles {
+		buf, err := g.GetRevision(ctx, fn, rev)
+		if err != nil {
+			continue
+		}
+		sig.parse(buf)
+	}
+
+	return sig
+}
+
+// parse adds the keys in buf to the signers. Each line contains either a
+// GPG key ID or fingerprint or a SSH public key. Other entries, like native
+// age recipients, can not sign commits and are skipped.
+func (s *signers) parse(buf []byte) {
+	for _, line := range strings.Split(string(buf), "\n") {
+		f := strings.Fields(line)
+		if len(f) < 1 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+
+		if isSSHKeyType(f[0]) {
+			if len(f) > 1 {
+				s.ssh = append(s.ssh, f[0]+" "+f[1])
+			}
+
+			continue
+		}
+
+		id := strings.TrimPrefix(strings.ToUpper(f[0]), "0X")
+		if len(id) >= 16 && isHex(id) {
+			s.gpg = append(s.gpg, id)
+		}
+	}
+}
+
+// allowedSigners returns the signers in the format of ssh-keygen -Y.
+func (s signers) allowedSigners() []byte {
+	var sb strings.Builder
+	for _, key := range s.ssh {
+		sb.WriteString("gopass " + key + "\n")
+	}
+
+	return []byte(sb.String())
+}
+
+// check returns an empty string if the signature is good and made by one of
+// the signers or the reason why it was rejected. See %G? in git log.
+func (s signers) check(status, fingerprint, primary string) string {
+	switch status {
+	case "N":
+		return "not signed"
+	case "B":
+		return "bad signature"
+	case "X":
+		return "expired signature"
+	case "Y":
+		return "signed by an expired key"
+	case "R":
+		return "signed by a revoked key"
+	case "E":
+		return "unknown signer"
+	case "G", "U":
+	default:
+		return fmt.Sprintf("unknown signature status %q", status)
+	}
+
+	// ssh signatures are verified against the allowed signers file. Valid
+	// signatures by other keys are reported as U.
+	if strings.HasPrefix(fingerprint, "SHA256:") {
+		if status == "G" {
+			return ""
+		}
+
+		return "unknown signer " + fingerprint
+	}
+
+	for _, id := range s.gpg {
+		if strings.HasSuffix(strings.ToUpper(fingerprint), id) || strings.HasSuffix(strings.ToUpper(primary), id) {
+			return ""
+		}
+	}
+
+	return "unknown signer " + fingerprint
+}
+
+func isSSHKeyType(s string) bool {
+	return strings.HasPrefix(s, "ssh-") || strings.HasPrefix(s, "ecdsa-sha2-") || strings.HasPrefix(s, "sk-")
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", r) {
+			return false
+		}
+	}
+
+	return true
+}
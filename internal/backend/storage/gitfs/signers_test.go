@@ -0,0 +1,222 @@
+package gitfs
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireSignedCommits(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	td := t.TempDir()
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	git := func(dir string, args ...string) {
+		t.Helper()
+
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Dead Beef", "GIT_AUTHOR_EMAIL=dead.beef@example.org",
+			"GIT_COMMITTER_NAME=Dead Beef", "GIT_COMMITTER_EMAIL=dead.beef@example.org",
+		)
+		buf, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(buf))
+	}
+
+	keygen := func(name string) (string, string) {
+		t.Helper()
+
+		fn := filepath.Join(td, name)
+		cmd := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", name, "-f", fn)
+		require.NoError(t, cmd.Run())
+		pub, err := os.ReadFile(fn + ".pub")
+		require.NoError(t, err)
+
+		return fn, string(pub)
+	}
+	trustedKey, trustedPub := keygen("trusted")
+	otherKey, _ := keygen("other")
+
+	// the remote and a working copy of another team member.
+	remote := filepath.Join(td, "remote.git")
+	author := filepath.Join(td, "author")
+	git(td, "init", "--bare", "-b", "master", remote)
+	git(td, "clone", remote, author)
+	git(author, "config", "gpg.format", "ssh")
+	git(author, "config", "user.signingkey", trustedKey)
+
+	commit := func(t *testing.T, fn, content string, sign ...string) {
+		t.Helper()
+
+		require.NoError(t, os.WriteFile(filepath.Join(author, fn), []byte(content), 0o600))
+		git(author, "add", fn)
+		args := []string{"commit", "-m", "update " + fn}
+		args = append(args, sign...)
+		git(author, args...)
+		git(author, "push", "origin", "HEAD:master")
+	}
+
+	commit(t, signersFile, "# team\n"+trustedPub, "-S")
+
+	local := filepath.Join(td, "local")
+	ctx = backend.WithRequireSignedCommits(ctx, true)
+
+	t.Run("unsigned clone", func(t *testing.T) {
+		commit(t, "foo", "unsigned")
+
+		_, err := Clone(ctx, remote, local, "Dead Beef", "dead.beef@example.org")
+		require.ErrorIs(t, err, store.ErrGitUnverifiedCommits)
+		assert.NoDirExists(t, local)
+	})
+
+	t.Run("unsigned history", func(t *testing.T) {
+		commit(t, "foo", "signed", "-S")
+
+		_, err := Clone(ctx, remote, local, "Dead Beef", "dead.beef@example.org")
+		require.ErrorIs(t, err, store.ErrGitUnverifiedCommits)
+		assert.Contains(t, err.Error(), "rejected 1 of 3 commits")
+		assert.NoDirExists(t, local)
+	})
+
+	// drop the unsigned commits.
+	git(author, "reset", "--hard", "HEAD~2")
+	git(author, "push", "--force", "origin", "HEAD:master")
+
+	// a trusted signer adds a new one.
+	newKey, newPub := keygen("new")
+	commit(t, signersFile, "# team\n"+trustedPub+newPub, "-S")
+	git(author, "config", "user.signingkey", newKey)
+	commit(t, "foo", "signed", "-S")
+	git(author, "config", "user.signingkey", trustedKey)
+
+	g, err := Clone(ctx, remote, local, "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+	assert.True(t, g.requireSigned())
+
+	t.Run("pull signed commits", func(t *testing.T) {
+		commit(t, "foo", "signed again", "-S")
+
+		require.NoError(t, g.Pull(ctx, "origin", "master"))
+		content, err := g.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "signed again", string(content))
+	})
+
+	t.Run("refuse unknown signer", func(t *testing.T) {
+		git(author, "config", "user.signingkey", otherKey)
+		commit(t, "foo", "other signer", "-S")
+		git(author, "config", "user.signingkey", trustedKey)
+		commit(t, "foo", "trusted signer", "-S")
+
+		err := g.Pull(ctx, "origin", "master")
+		require.ErrorIs(t, err, store.ErrGitUnverifiedCommits)
+		assert.Contains(t, err.Error(), "rejected 1 of 2 commits")
+		assert.Contains(t, err.Error(), "unknown signer")
+
+		content, err := g.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "signed again", string(content))
+
+		// push must not merge or overwrite the remote either.
+		require.ErrorIs(t, g.Push(ctx, "origin", "master"), store.ErrGitUnverifiedCommits)
+	})
+}
+
+func TestSigners(t *testing.T) {
+	t.Parallel()
+
+	s := signers{}
+	s.parse([]byte(`# comment
+0xDEADBEEFDEADBEEF
+age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDmc comment
+john@example.org
+`))
+	assert.Equal(t, []string{"DEADBEEFDEADBEEF"}, s.gpg)
+	assert.Equal(t, []string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDmc"}, s.ssh)
+	assert.Equal(t, "gopass ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDmc\n", string(s.allowedSigners()))
+
+	for _, tc := range []struct {
+		status string
+		fp     string
+		ok     bool
+	}{
+		{status: "G", fp: "0123456789ABCDEF0123DEADBEEFDEADBEEF", ok: true},
+		{status: "U", fp: "0123456789abcdef0123deadbeefdeadbeef", ok: true},
+		{status: "G", fp: "0123456789ABCDEF0123456789ABCDEF01234567"},
+		{status: "G", fp: "SHA256:abc", ok: true},
+		{status: "U", fp: "SHA256:abc"},
+		{status: "N"},
+		{status: "B", fp: "0123456789ABCDEF0123DEADBEEFDEADBEEF"},
+		{status: "R", fp: "0123456789ABCDEF0123DEADBEEFDEADBEEF"},
+	} {
+		reason := s.check(tc.status, tc.fp, "")
+		if tc.ok {
+			assert.Empty(t, reason, tc)
+		} else {
+			assert.NotEmpty(t, reason, tc)
+		}
+	}
+}
+
+func TestCommitWithGlobalSigningKey(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	td := t.TempDir()
+	t.Setenv("HOME", td)
+	t.Setenv("GOPASS_HOMEDIR", td)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(td, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	key := filepath.Join(td, "key")
+	require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).Run())
+	require.NoError(t, os.WriteFile(filepath.Join(td, ".gitconfig"), []byte("[gpg]\n\tformat = ssh\n[user]\n\tsigningkey = "+key+"\n"), 0o600))
+
+	gitdir := filepath.Join(td, "store")
+	require.NoError(t, os.Mkdir(gitdir, 0o755))
+	g, err := Init(ctx, gitdir, "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+
+	require.NoError(t, g.Set(ctx, "foo", []byte("bar")))
+	require.NoError(t, g.Add(ctx, "foo"))
+	require.NoError(t, g.Commit(ctx, "add foo"))
+
+	cmd := exec.Command("git", "cat-file", "commit", "HEAD")
+	cmd.Dir = gitdir
+	commit, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(commit), "gpgsig")
+}
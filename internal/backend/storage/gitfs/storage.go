@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/gopasspw/gopass/internal/merge"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
@@ -61,6 +62,11 @@ func (g *Git) Fsck(ctx context.Context) error {
 		return fmt.Errorf("failed to fix git config: %w", err)
 	}
 
+	// stores set up by older versions lack the merge driver attributes.
+	if err := merge.WriteAttributes(ctx, g, g.fs.Path()); err != nil {
+		return fmt.Errorf("failed to update .gitattributes: %w", err)
+	}
+
 	// add any untracked files.
 	if err := g.addUntrackedFiles(ctx); err != nil {
 		return fmt.Errorf("failed to add untracked files: %w", err)
@@ -0,0 +1,3 @@
+package storage
+
+import _ "github.com/gopasspw/gopass/internal/backend/storage/gogit" // register gogit backend
@@ -0,0 +1,111 @@
+package gogit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/merge"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+)
+
+const (
+	// storageKey marks a repository as managed by this backend. Otherwise
+	// gitfs is preferred if there is a git binary.
+	storageKey = "gopass.storage"
+	// requireSignedKey is set by gitfs for stores that require signed
+	// commits.
+	requireSignedKey = "gopass.require-signed"
+)
+
+// fixConfig writes the same git config as gitfs. go-git doesn't use most of
+// it, but it keeps the repository usable with the git cli.
+func (g *Git) fixConfig(ctx context.Context) error {
+	if err := g.ConfigSet(ctx, storageKey, name); err != nil {
+		return fmt.Errorf("failed to set git config for %s: %w", storageKey, err)
+	}
+
+	for _, kv := range [][2]string{
+		{"push.default", "matching"},
+		{"pull.rebase", "false"},
+		{"diff.gpg.binary", "true"},
+		{"diff.gpg.textconv", "gpg --no-tty --decrypt"},
+		{"merge.gopass.name", merge.DriverName},
+		{"merge.gopass.driver", merge.DriverCommand},
+	} {
+		if err := g.ConfigSet(ctx, kv[0], kv[1]); err != nil {
+			out.Errorf(ctx, "Error while initializing git: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// InitConfig initialized and preparse the git config.
+func (g *Git) InitConfig(ctx context.Context, userName, userEmail string) error {
+	// set commit identity.
+	if userName != "" {
+		if err := g.ConfigSet(ctx, "user.name", userName); err != nil {
+			return fmt.Errorf("failed to set git config user.name: %w", err)
+		}
+	} else {
+		out.Printf(ctx, "Git Username not set")
+	}
+	if userEmail != "" && strings.Contains(userEmail, "@") {
+		if err := g.ConfigSet(ctx, "user.email", userEmail); err != nil {
+			return fmt.Errorf("failed to set git config user.email: %w", err)
+		}
+	} else {
+		out.Printf(ctx, "Git Email not set")
+	}
+
+	// ensure sane git config.
+	if err := g.fixConfig(ctx); err != nil {
+		return fmt.Errorf("failed to fix git config: %w", err)
+	}
+
+	if err := merge.WriteAttributes(ctx, g, g.fs.Path()); err != nil {
+		out.Warningf(ctx, "Failed to configure .gitattributes: %s", err)
+	}
+
+	return nil
+}
+
+// ConfigSet sets a local config value.
+func (g *Git) ConfigSet(ctx context.Context, key, value string) error {
+	// go-git rewrites the config file, e.g. when adding remotes.
+	g.cfg.Reload()
+
+	return g.cfg.SetLocal(key, value)
+}
+
+// ConfigGet returns a given config value.
+func (g *Git) ConfigGet(ctx context.Context, key string) (string, error) {
+	if !g.IsInitialized() {
+		return "", store.ErrGitNotInit
+	}
+
+	value := g.cfg.Get(key)
+	if value == "" {
+		g.cfg.Reload()
+
+		value = g.cfg.Get(key)
+	}
+
+	return value, nil
+}
+
+// ConfigList returns all git config settings.
+func (g *Git) ConfigList(ctx context.Context) (map[string]string, error) {
+	if !g.IsInitialized() {
+		return nil, store.ErrGitNotInit
+	}
+
+	kv := make(map[string]string, 23)
+	for _, k := range g.cfg.List("") {
+		kv[k] = g.cfg.Get(k)
+	}
+
+	return kv, nil
+}
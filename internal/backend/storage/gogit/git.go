@@ -0,0 +1,724 @@
+// Package gogit implements a git storage backend on top of go-git. It does
+// not need a git binary and uses the same on-disk format as gitfs.
+package gogit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	rdebug "runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-git/go-git/v5"
+	gitcfg "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/gitconfig"
+)
+
+// Git is a go-git based git backend.
+type Git struct {
+	fs   *fs.Store
+	cfg  *gitconfig.Configs
+	repo *git.Repository
+}
+
+// New opens an existing git repository.
+func New(path string) (*Git, error) {
+	path = fsutil.ExpandHomedir(path)
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repo at %s: %w", path, err)
+	}
+
+	return &Git{
+		fs:   fs.New(path),
+		cfg:  gitconfig.New().LoadAll(filepath.Join(path, ".git")),
+		repo: repo,
+	}, nil
+}
+
+// Clone clones an existing git repo and returns a new go-git based backend
+// configured for this clone repo.
+func Clone(ctx context.Context, repo, path, userName, userEmail string) (*Git, error) {
+	path = fsutil.ExpandHomedir(path)
+
+	if backend.IsRequireSignedCommits(ctx) {
+		return nil, fmt.Errorf("%s can not verify commit signatures, please use gitfs", name)
+	}
+
+	r, err := git.PlainCloneContext(ctx, path, false, &git.CloneOptions{
+		URL: repo,
+	})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		// cloning an empty repo is fine, we'll push to it later.
+		debug.Log("Remote %s is empty. Initializing a new repository", repo)
+		r, err = initEmpty(path, repo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", repo, err)
+	}
+
+	g := &Git{
+		fs:   fs.New(path),
+		cfg:  gitconfig.New().LoadAll(filepath.Join(path, ".git")),
+		repo: r,
+	}
+
+	// initialize the local git config.
+	if err := g.InitConfig(ctx, userName, userEmail); err != nil {
+		return g, fmt.Errorf("failed to configure git: %w", err)
+	}
+	out.Printf(ctx, "git configured at %s", g.fs.Path())
+
+	return g, nil
+}
+
+// initEmpty initializes a new repository with origin pointing to an empty
+// remote.
+func initEmpty(path, url string) (*git.Repository, error) {
+	r, err := git.PlainInit(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.CreateRemote(&gitcfg.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+
+	return r, err
+}
+
+// Init initializes this store's git repo.
+func Init(ctx context.Context, path, userName, userEmail string) (*Git, error) {
+	path = fsutil.ExpandHomedir(path)
+
+	r, err := git.PlainOpen(path)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		r, err = git.PlainInit(path, false)
+		if err == nil {
+			out.Printf(ctx, "git initialized at %s", path)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize git: %w", err)
+	}
+
+	g := &Git{
+		fs:   fs.New(path),
+		cfg:  gitconfig.New().LoadAll(filepath.Join(path, ".git")),
+		repo: r,
+	}
+
+	if !ctxutil.IsGitInit(ctx) {
+		return g, nil
+	}
+
+	// initialize the local git config.
+	if err := g.InitConfig(ctx, userName, userEmail); err != nil {
+		return g, fmt.Errorf("failed to configure git: %w", err)
+	}
+	out.Printf(ctx, "git configured at %s", g.fs.Path())
+
+	// add current content of the store.
+	if err := g.Add(ctx, g.fs.Path()); err != nil {
+		return g, fmt.Errorf("failed to add %q to git: %w", g.fs.Path(), err)
+	}
+
+	// commit if there is something to commit.
+	if !g.HasStagedChanges(ctx) {
+		debug.Log("No staged changes")
+
+		return g, nil
+	}
+
+	if err := g.Commit(ctx, "Add current content of password store"); err != nil {
+		return g, fmt.Errorf("failed to commit changes to git: %w", err)
+	}
+
+	return g, nil
+}
+
+// Name returns gogit.
+func (g *Git) Name() string {
+	return name
+}
+
+// Version returns the version of the go-git library.
+func (g *Git) Version(ctx context.Context) semver.Version {
+	v := semver.Version{}
+
+	bi, ok := rdebug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+
+	for _, dep := range bi.Deps {
+		if dep.Path != "github.com/go-git/go-git/v5" {
+			continue
+		}
+
+		sv, err := semver.ParseTolerant(dep.Version)
+		if err != nil {
+			debug.Log("Failed to parse %q as semver: %s", dep.Version, err)
+
+			return v
+		}
+
+		return sv
+	}
+
+	return v
+}
+
+// IsInitialized returns true if this stores has an (probably) initialized .git folder.
+func (g *Git) IsInitialized() bool {
+	return g.repo != nil && fsutil.IsFile(filepath.Join(g.fs.Path(), ".git", "config"))
+}
+
+// Add adds the listed files to the git index. Deleted files are removed from
+// the index.
+func (g *Git) Add(ctx context.Context, files ...string) error {
+	if !g.IsInitialized() {
+		return store.ErrGitNotInit
+	}
+
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range files {
+		fn = strings.TrimPrefix(filepath.ToSlash(fn), filepath.ToSlash(g.fs.Path()))
+		fn = strings.TrimPrefix(fn, "/")
+		if fn == "" {
+			// add the whole store, like git add --all.
+			if err := wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+				return fmt.Errorf("failed to add all files: %w", err)
+			}
+
+			continue
+		}
+
+		if err := wt.AddWithOptions(&git.AddOptions{Path: fn, SkipStatus: true}); err != nil {
+			return fmt.Errorf("failed to add %s: %w", fn, err)
+		}
+	}
+
+	return nil
+}
+
+// TryAdd calls Add and returns nil if the git repo was not initialized.
+func (g *Git) TryAdd(ctx context.Context, files ...string) error {
+	err := g.Add(ctx, files...)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, store.ErrGitNotInit) {
+		debug.Log("Git not initialized. Ignoring.")
+
+		return nil
+	}
+
+	return err
+}
+
+// HasStagedChanges returns true if there are any staged changes which can be committed.
+func (g *Git) HasStagedChanges(ctx context.Context) bool {
+	st, err := g.status()
+	if err != nil {
+		debug.Log("Failed to get status: %s", err)
+
+		return false
+	}
+
+	for _, fst := range st {
+		if fst.Staging != git.Unmodified && fst.Staging != git.Untracked {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListUntrackedFiles lists untracked files.
+func (g *Git) ListUntrackedFiles(ctx context.Context) []string {
+	st, err := g.status()
+	if err != nil {
+		return []string{fmt.Sprintf("ERROR: %s", err)}
+	}
+
+	uf := []string{}
+	for fn, fst := range st {
+		if fst.Worktree == git.Untracked {
+			uf = append(uf, fn)
+		}
+	}
+	sort.Strings(uf)
+
+	return uf
+}
+
+func (g *Git) status() (git.Status, error) {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	return wt.Status()
+}
+
+// Commit creates a new git commit with the given commit message.
+func (g *Git) Commit(ctx context.Context, msg string) error {
+	if !g.IsInitialized() {
+		return store.ErrGitNotInit
+	}
+
+	if !g.HasStagedChanges(ctx) {
+		return store.ErrGitNothingToCommit
+	}
+
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	sig := g.signature(ctx)
+	if _, err := wt.Commit(msg, &git.CommitOptions{
+		Author:    sig,
+		Committer: sig,
+	}); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
+
+// signature returns the commit identity. Like git it prefers the
+// GIT_AUTHOR_* environment variables over the config.
+func (g *Git) signature(ctx context.Context) *object.Signature {
+	userName := os.Getenv("GIT_AUTHOR_NAME")
+	if userName == "" {
+		userName = g.cfg.Get("user.name")
+	}
+
+	userEmail := os.Getenv("GIT_AUTHOR_EMAIL")
+	if userEmail == "" {
+		userEmail = g.cfg.Get("user.email")
+	}
+
+	return &object.Signature{
+		Name:  userName,
+		Email: userEmail,
+		When:  ctxutil.GetCommitTimestamp(ctx),
+	}
+}
+
+// TryCommit calls commit and returns nil if there was nothing to commit or if the git repo was not initialized.
+func (g *Git) TryCommit(ctx context.Context, msg string) error {
+	err := g.Commit(ctx, msg)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, store.ErrGitNothingToCommit) {
+		debug.Log("Nothing to commit. Ignoring.")
+
+		return nil
+	}
+	if errors.Is(err, store.ErrGitNotInit) {
+		debug.Log("Git not initialized. Ignoring.")
+
+		return nil
+	}
+
+	return err
+}
+
+func (g *Git) defaultRemote(ctx context.Context, branch string) string {
+	remote := g.cfg.Get("branch." + branch + ".remote")
+	if remote == "" {
+		return "origin"
+	}
+
+	if _, err := g.repo.Remote(remote); err != nil {
+		return "origin"
+	}
+
+	return remote
+}
+
+func (g *Git) defaultBranch(ctx context.Context) string {
+	head, err := g.repo.Head()
+	if err == nil {
+		return head.Name().Short()
+	}
+
+	// an empty repository has no HEAD commit, yet. Use the branch
+	// HEAD points to.
+	if ref, err := g.repo.Storer.Reference(plumbing.HEAD); err == nil && ref.Type() == plumbing.SymbolicReference {
+		return ref.Target().Short()
+	}
+
+	// see https://github.com/github/renaming.
+	return "main"
+}
+
+// PushPull pushes the repo to it's origin.
+// optional arguments: remote and branch.
+func (g *Git) PushPull(ctx context.Context, op, remote, branch string) error {
+	_, err := g.pushPull(ctx, op, remote, branch)
+
+	return err
+}
+
+// PushRemotes pulls from the primary remote and pushes to all configured
+// remotes. It returns the result for each remote.
+func (g *Git) PushRemotes(ctx context.Context) ([]backend.RemoteStatus, error) {
+	return g.pushPull(ctx, "push", "", "")
+}
+
+func (g *Git) pushPull(ctx context.Context, op, remote, branch string) ([]backend.RemoteStatus, error) {
+	if ctxutil.IsNoNetwork(ctx) {
+		debug.Log("Skipping network ops. NoNetwork=true")
+
+		return nil, nil
+	}
+	if !g.IsInitialized() {
+		debug.Log("Git in %s is not initialized. Can not push/pull", g.Path())
+
+		return nil, store.ErrGitNotInit
+	}
+
+	if branch == "" {
+		branch = g.defaultBranch(ctx)
+	}
+
+	remotes := []string{remote}
+	if remote == "" {
+		remotes = g.remotes(ctx, branch)
+	}
+
+	remotes = g.filterRemotes(remotes)
+	if len(remotes) < 1 {
+		return nil, store.ErrGitNoRemote
+	}
+
+	if err := g.pullFirst(ctx, remotes, branch); err != nil {
+		if op == "pull" {
+			return nil, err
+		}
+		out.Warningf(ctx, "Failed to pull before git push: %s", err)
+	}
+
+	if op == "pull" {
+		return nil, nil
+	}
+
+	if uf := g.ListUntrackedFiles(ctx); len(uf) > 0 {
+		out.Warningf(ctx, "Found untracked files: %+v", uf)
+	}
+
+	status := make([]backend.RemoteStatus, 0, len(remotes))
+	var errs []error
+	for _, r := range remotes {
+		err := g.push(ctx, r, branch)
+		status = append(status, backend.RemoteStatus{Remote: r, Err: err})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return status, errors.Join(errs...)
+}
+
+func (g *Git) push(ctx context.Context, remote, branch string) error {
+	ref := plumbing.NewBranchReferenceName(branch)
+	err := g.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []gitcfg.RefSpec{gitcfg.RefSpec(ref + ":" + ref)},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push to %s: %w", remote, err)
+	}
+
+	return nil
+}
+
+// pullFirst pulls from the first remote that is reachable. Like gitfs it
+// only falls back to the next remote if the fetch failed, diverged branches
+// and unverified commits are returned.
+func (g *Git) pullFirst(ctx context.Context, remotes []string, branch string) error {
+	var err error
+	for i, r := range remotes {
+		err = g.pull(ctx, r, branch)
+		if err == nil || errors.Is(err, git.ErrNonFastForwardUpdate) || errors.Is(err, store.ErrGitUnverifiedCommits) {
+			return err
+		}
+		if i < len(remotes)-1 {
+			out.Warningf(ctx, "Failed to pull from %s, trying %s: %s", r, remotes[i+1], err)
+		}
+	}
+
+	return err
+}
+
+// pull fetches the branch from the remote and fast-forwards the worktree.
+// go-git can not create merge commits, diverged branches need to be merged
+// with the git cli.
+func (g *Git) pull(ctx context.Context, remote, branch string) error {
+	if g.cfg.GetLocal(requireSignedKey) == "true" {
+		return fmt.Errorf("%w: %s can not verify commit signatures, please use gitfs", store.ErrGitUnverifiedCommits, name)
+	}
+
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	err = wt.PullContext(ctx, &git.PullOptions{
+		RemoteName:    remote,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+	})
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, git.NoErrAlreadyUpToDate), errors.Is(err, transport.ErrEmptyRemoteRepository):
+		return nil
+	case errors.Is(err, git.ErrNonFastForwardUpdate):
+		return fmt.Errorf("local and remote branch %s have diverged and %s can only fast-forward. Please merge with 'git pull %s %s': %w", branch, name, remote, branch, err)
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		debug.Log("Branch %s not found on %s: %s", branch, remote, err)
+
+		return nil
+	default:
+		return fmt.Errorf("failed to pull from %s: %w", remote, err)
+	}
+}
+
+// TryPush calls Push and returns nil if the git repo was not initialized.
+func (g *Git) TryPush(ctx context.Context, remote, branch string) error {
+	err := g.Push(ctx, remote, branch)
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, store.ErrGitNotInit):
+		debug.Log("Git not initialized. Ignoring.")
+
+		return nil
+	case errors.Is(err, store.ErrGitNoRemote):
+		debug.Log("Git has no remote. Ignoring.")
+
+		return nil
+	default:
+		return err
+	}
+}
+
+// Push pushes to the git remote.
+func (g *Git) Push(ctx context.Context, remote, branch string) error {
+	if ctxutil.IsNoNetwork(ctx) {
+		debug.Log("Skipping network ops. NoNetwork=true")
+
+		return nil
+	}
+
+	return g.PushPull(ctx, "push", remote, branch)
+}
+
+// Pull pulls from the git remote.
+func (g *Git) Pull(ctx context.Context, remote, branch string) error {
+	if ctxutil.IsNoNetwork(ctx) {
+		debug.Log("Skipping network ops. NoNetwork=true")
+
+		return nil
+	}
+
+	return g.PushPull(ctx, "pull", remote, branch)
+}
+
+// AddRemote adds a new remote.
+func (g *Git) AddRemote(ctx context.Context, remote, url string) error {
+	defer g.cfg.Reload()
+
+	_, err := g.repo.CreateRemote(&gitcfg.RemoteConfig{
+		Name: remote,
+		URLs: []string{url},
+	})
+
+	return err
+}
+
+// RemoveRemote removes a remote.
+func (g *Git) RemoveRemote(ctx context.Context, remote string) error {
+	defer g.cfg.Reload()
+
+	return g.repo.DeleteRemote(remote)
+}
+
+// Revisions will list all available revisions of the named entity.
+func (g *Git) Revisions(ctx context.Context, name string) ([]backend.Revision, error) {
+	iter, err := g.repo.Log(&git.LogOptions{
+		FileName: &name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log for %s: %w", name, err)
+	}
+	defer iter.Close()
+
+	revs := []backend.Revision{}
+	if err := iter.ForEach(func(c *object.Commit) error {
+		revs = append(revs, commitRevision(c))
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get log for %s: %w", name, err)
+	}
+
+	debug.Log("Revisions for %s: %+v", name, revs)
+
+	return revs, nil
+}
+
+// commitRevision converts a commit to a revision.
+func commitRevision(c *object.Commit) backend.Revision {
+	subject, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+
+	return backend.Revision{
+		Hash:        c.Hash.String(),
+		AuthorName:  c.Author.Name,
+		AuthorEmail: c.Author.Email,
+		Date:        c.Author.When,
+		Subject:     strings.TrimSpace(subject),
+		Body:        strings.TrimSpace(body),
+	}
+}
+
+// Deletions lists all files that were deleted in the history of the
+// repository, newest first. Files deleted more than once are only listed with
+// their latest deletion.
+func (g *Git) Deletions(ctx context.Context) ([]backend.Deletion, error) {
+	iter, err := g.repo.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+	defer iter.Close()
+
+	seen := map[string]bool{}
+	dels := []backend.Deletion{}
+	if err := iter.ForEach(func(c *object.Commit) error {
+		// like git log we don't look at merge commits.
+		if c.NumParents() != 1 {
+			return nil
+		}
+
+		changes, err := commitChanges(c)
+		if err != nil {
+			return err
+		}
+
+		rev := commitRevision(c)
+		for _, ch := range changes {
+			action, err := ch.Action()
+			if err != nil || action != merkletrie.Delete {
+				continue
+			}
+
+			fn := ch.From.Name
+			if seen[fn] {
+				continue
+			}
+			seen[fn] = true
+
+			dels = append(dels, backend.Deletion{Revision: rev, Name: fn})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list deletions: %w", err)
+	}
+
+	return dels, nil
+}
+
+// commitChanges returns the changes a commit made to its first parent.
+func commitChanges(c *object.Commit) (object.Changes, error) {
+	parent, err := c.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := parent.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	return object.DiffTree(pt, ct)
+}
+
+// GetRevision will return the content of any revision of the named entity.
+// The revision can be anything git rev-parse understands, e.g. a hash or HEAD~1.
+func (g *Git) GetRevision(ctx context.Context, name, revision string) ([]byte, error) {
+	name = strings.TrimSpace(name)
+	revision = strings.TrimSpace(revision)
+
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+
+	c, err := g.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+
+	f, err := c.File(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s at %s: %w", name, revision, err)
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() //nolint:errcheck
+
+	return io.ReadAll(r)
+}
+
+// Status return the git status output.
+func (g *Git) Status(ctx context.Context) ([]byte, error) {
+	st, err := g.status()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(st.String()), nil
+}
+
+// Compact will repack the object database.
+func (g *Git) Compact(ctx context.Context) error {
+	return g.repo.RepackObjects(&git.RepackConfig{})
+}
@@ -0,0 +1,172 @@
+package gogit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGit(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "")
+	t.Setenv("GIT_AUTHOR_EMAIL", "")
+
+	td := t.TempDir()
+
+	gitdir := filepath.Join(td, "git")
+	require.NoError(t, os.Mkdir(gitdir, 0o755))
+	gitdir2 := filepath.Join(td, "git2")
+	remote := filepath.Join(td, "remote.git")
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithGitInit(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	var g *Git
+	t.Run("init new repo", func(t *testing.T) {
+		g, err = Init(ctx, gitdir, "Dead Beef", "dead.beef@example.org")
+		require.NoError(t, err)
+		require.NotNil(t, g)
+
+		assert.Equal(t, "gogit", g.Name())
+		assert.True(t, g.IsInitialized())
+		assert.Empty(t, g.ListUntrackedFiles(ctx))
+
+		require.NoError(t, g.Set(ctx, "some-file", []byte("foobar")))
+		assert.Equal(t, []string{"some-file"}, g.ListUntrackedFiles(ctx))
+		require.NoError(t, g.Add(ctx, "some-file"))
+		assert.True(t, g.HasStagedChanges(ctx))
+		require.NoError(t, g.Commit(ctx, "added some-file"))
+		assert.False(t, g.HasStagedChanges(ctx))
+		require.ErrorIs(t, g.Commit(ctx, "nothing"), store.ErrGitNothingToCommit)
+
+		require.Error(t, g.Push(ctx, "origin", "master"))
+		require.Error(t, g.Pull(ctx, "origin", "master"))
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		require.NoError(t, g.Set(ctx, "some-file", []byte("barfoo")))
+		require.NoError(t, g.Add(ctx, "some-file"))
+		require.NoError(t, g.Commit(ctx, "changed some-file\n\nwith a body"))
+
+		revs, err := g.Revisions(ctx, "some-file")
+		require.NoError(t, err)
+		require.Len(t, revs, 2)
+		assert.Equal(t, "changed some-file", revs[0].Subject)
+		assert.Equal(t, "with a body", revs[0].Body)
+		assert.Equal(t, "Dead Beef", revs[0].AuthorName)
+		assert.Equal(t, "dead.beef@example.org", revs[0].AuthorEmail)
+
+		content, err := g.GetRevision(ctx, "some-file", revs[1].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", string(content))
+
+		content, err = g.GetRevision(ctx, "some-file", "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, "barfoo", string(content))
+
+		_, err = g.GetRevision(ctx, "other-file", "HEAD")
+		require.Error(t, err)
+	})
+
+	t.Run("deletions", func(t *testing.T) {
+		require.NoError(t, g.Delete(ctx, "some-file"))
+		require.NoError(t, g.Add(ctx, "some-file"))
+		require.NoError(t, g.Commit(ctx, "removed some-file"))
+
+		dels, err := g.Deletions(ctx)
+		require.NoError(t, err)
+		require.Len(t, dels, 1)
+		assert.Equal(t, "some-file", dels[0].Name)
+		assert.Equal(t, "removed some-file", dels[0].Subject)
+
+		revs, err := g.Revisions(ctx, "some-file")
+		require.NoError(t, err)
+		assert.Len(t, revs, 3)
+	})
+
+	t.Run("push to remote", func(t *testing.T) {
+		require.NoError(t, g.AddRemote(ctx, "origin", "file://"+remote))
+		require.NoError(t, g.Set(ctx, "other-file", []byte("content")))
+		require.NoError(t, g.Add(ctx, g.Path()))
+		require.NoError(t, g.Commit(ctx, "added other-file"))
+		require.NoError(t, g.Push(ctx, "", ""))
+	})
+
+	t.Run("clone and pull", func(t *testing.T) {
+		g2, err := Clone(ctx, "file://"+remote, gitdir2, "Dead Beef", "dead.beef@example.org")
+		require.NoError(t, err)
+
+		content, err := g2.Get(ctx, "other-file")
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+
+		require.NoError(t, g.Set(ctx, "other-file", []byte("changed")))
+		require.NoError(t, g.Add(ctx, "other-file"))
+		require.NoError(t, g.Commit(ctx, "changed other-file"))
+		require.NoError(t, g.Push(ctx, "", ""))
+
+		require.NoError(t, g2.Pull(ctx, "", ""))
+		content, err = g2.Get(ctx, "other-file")
+		require.NoError(t, err)
+		assert.Equal(t, "changed", string(content))
+	})
+
+	t.Run("remove remote", func(t *testing.T) {
+		require.NoError(t, g.RemoveRemote(ctx, "origin"))
+		cfg, err := g.ConfigList(ctx)
+		require.NoError(t, err)
+		assert.NotContains(t, cfg, "remote.origin.url")
+		assert.Equal(t, "gogit", cfg[storageKey])
+	})
+
+	t.Run("detect", func(t *testing.T) {
+		require.NoError(t, loader{}.Handles(ctx, gitdir))
+		require.Error(t, loader{}.Handles(ctx, td))
+
+		st, err := backend.DetectStorage(ctx, gitdir)
+		require.NoError(t, err)
+		assert.Equal(t, "gogit", st.Name())
+	})
+}
+
+func TestHandlesWithoutGit(t *testing.T) {
+	gitdir := filepath.Join(t.TempDir(), "git")
+	_, err := git.PlainInit(gitdir, false)
+	require.NoError(t, err)
+
+	ctx := config.NewContextInMemory()
+
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+	}()
+
+	// a plain git repository is only handled if there is no git binary.
+	require.Error(t, loader{}.Handles(ctx, gitdir))
+	assert.Empty(t, buf.String())
+
+	// gitfs warns when it falls back to gogit on init or clone, not here.
+	t.Setenv("PATH", t.TempDir())
+	require.NoError(t, loader{}.Handles(ctx, gitdir))
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,74 @@
+package gogit
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/gitconfig"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+const (
+	name = "gogit"
+)
+
+func init() {
+	backend.StorageRegistry.Register(backend.GoGit, name, &loader{})
+}
+
+type loader struct{}
+
+func (l loader) New(ctx context.Context, path string) (backend.Storage, error) {
+	return New(path)
+}
+
+// Open implements backend.RCSLoader.
+func (l loader) Open(ctx context.Context, path string) (backend.Storage, error) {
+	return New(path)
+}
+
+// Clone implements backend.RCSLoader.
+func (l loader) Clone(ctx context.Context, repo, path string) (backend.Storage, error) {
+	return Clone(ctx, repo, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
+}
+
+// Init implements backend.RCSLoader.
+func (l loader) Init(ctx context.Context, path string) (backend.Storage, error) {
+	return Init(ctx, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
+}
+
+// Handles returns nil for git repositories that were set up by this backend
+// or if there is no git binary that gitfs could use. gitfs warns about the
+// latter when it falls back to gogit on init or clone.
+func (l loader) Handles(ctx context.Context, path string) error {
+	path = fsutil.ExpandHomedir(path)
+	gitDir := filepath.Join(path, ".git")
+	if !fsutil.IsDir(gitDir) {
+		return fmt.Errorf("no .git at %s", path)
+	}
+
+	if gitconfig.New().LoadAll(gitDir).GetLocal(storageKey) == name {
+		return nil
+	}
+
+	if _, err := exec.LookPath("git"); err == nil {
+		return fmt.Errorf("git is available, not using %s for %s", name, path)
+	}
+
+	debug.Log("git not found, using %s instead of gitfs for %s", name, path)
+
+	return nil
+}
+
+func (l loader) Priority() int {
+	return 3
+}
+
+func (l loader) String() string {
+	return name
+}
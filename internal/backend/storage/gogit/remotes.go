@@ -0,0 +1,39 @@
+package gogit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// remotes returns the remotes to pull from and push to. They are listed in
+// git.remotes in the config of the mount, just like for gitfs. The first one
+// is the primary remote, all others are mirrors. Without a configured list
+// this is the remote of the current branch.
+func (g *Git) remotes(ctx context.Context, branch string) []string {
+	v := config.String(ctx, "git.remotes")
+	if v == "" {
+		return []string{g.defaultRemote(ctx, branch)}
+	}
+
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// filterRemotes removes remotes that don't exist.
+func (g *Git) filterRemotes(remotes []string) []string {
+	filtered := make([]string, 0, len(remotes))
+	for _, remote := range remotes {
+		if _, err := g.repo.Remote(remote); err != nil {
+			debug.Log("Remote %q not found: %s", remote, err)
+
+			continue
+		}
+		filtered = append(filtered, remote)
+	}
+
+	return filtered
+}
@@ -40,9 +40,13 @@ var ignoredEnvs = set.Map([]string{
 var ignoredOptions = set.Map([]string{
 	"core.pre-hook",
 	"core.post-hook",
+	"migration.declined",
+	"migration.to",
+	"recipients.groups-hash",
 	"recipients.hash",
 	"user.email",
 	"user.name",
+	"user.signingkey",
 })
 
 func TestConfigOptsInDocs(t *testing.T) {
@@ -0,0 +1,121 @@
+package create
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/tpl"
+)
+
+// active returns true if all conditions of this attribute are met by the
+// values that were entered so far. Attributes without conditions are always
+// active.
+func (a Attribute) active(values map[string]string) bool {
+	for k, want := range a.When {
+		if values[k] != want {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validate checks the given value against the constraints of the attribute.
+func (a Attribute) validate(sv string) error {
+	if a.Min > 0 && len(sv) < a.Min {
+		return fmt.Errorf("%s is too short (needs %d)", a.Name, a.Min)
+	}
+
+	if a.Max > 0 && len(sv) > a.Max {
+		return fmt.Errorf("%s is too long (at most %d)", a.Name, a.Max)
+	}
+
+	if len(a.Choices) > 0 && !slices.Contains(a.Choices, sv) {
+		return fmt.Errorf("%s must be one of %s, got %q", a.Name, strings.Join(a.Choices, ", "), sv)
+	}
+
+	if a.Pattern != "" {
+		// the pattern must match the whole value, not only a part of it.
+		re, err := regexp.Compile("^(?:" + a.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q for %s: %w", a.Pattern, a.Name, err)
+		}
+		if !re.MatchString(sv) {
+			return fmt.Errorf("%s does not match %q", a.Name, a.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// prompt returns the prompt for this attribute. If the attribute has a list of
+// choices they are appended to the prompt.
+func (a Attribute) prompt() string {
+	p := a.Prompt
+	if p == "" {
+		p = strings.ToTitle(a.Name)
+	}
+
+	if len(a.Choices) > 0 {
+		p += " (" + strings.Join(a.Choices, "|") + ")"
+	}
+
+	return p
+}
+
+// defaultValue renders the default value of this attribute. The first choice
+// is used as the default for enums without an explicit default.
+func (a Attribute) defaultValue(ctx context.Context, values map[string]string) (string, error) {
+	if a.Default == "" && len(a.Choices) > 0 {
+		return a.Choices[0], nil
+	}
+
+	return renderValue(ctx, a.Name, a.Default, values)
+}
+
+// renderValue executes a template for default values and computed attributes.
+// The template has access to the values entered so far (e.g. {{ .url }}), the
+// public template functions from internal/tpl and the config function to read
+// gopass config values (e.g. {{ config "create.default-username" }}).
+func renderValue(ctx context.Context, name, tmplStr string, values map[string]string) (string, error) {
+	if !strings.Contains(tmplStr, "{{") {
+		return tmplStr, nil
+	}
+
+	funcs := tpl.PublicFuncMap()
+	funcs["config"] = func(key string) string {
+		return config.String(ctx, key)
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(tmplStr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template for %s: %w", name, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, values); err != nil {
+		return "", fmt.Errorf("failed to execute template for %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// parseSets parses the key=value pairs given with --set.
+func parseSets(in []string) (map[string]string, error) {
+	sets := make(map[string]string, len(in))
+	for _, kv := range in {
+		k, v, found := strings.Cut(kv, "=")
+		if !found || k == "" {
+			return nil, fmt.Errorf("invalid value %q, expected key=value", kv)
+		}
+		sets[k] = v
+	}
+
+	return sets, nil
+}
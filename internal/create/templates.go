@@ -28,6 +28,7 @@ attributes:
   - name: "username"
     type: "string"
     prompt: "Username"
+    default: '{{ config "create.default-username" }}'
     min: 1
   - name: "password"
     type: "password"
@@ -37,13 +37,18 @@ const (
 // Attribute is a credential attribute that is being asked for
 // when populating a template.
 type Attribute struct {
-	Name         string `yaml:"name"`
-	Type         string `yaml:"type"`
-	Prompt       string `yaml:"prompt"`
-	Charset      string `yaml:"charset"`
-	Min          int    `yaml:"min"`
-	Max          int    `yaml:"max"`
-	AlwaysPrompt bool   `yaml:"always_prompt"` // always prompt for the crendentials
+	Name         string            `yaml:"name"`
+	Type         string            `yaml:"type"`
+	Prompt       string            `yaml:"prompt"`
+	Charset      string            `yaml:"charset"` // characters to generate passwords from, or mask:<mask> for the mask generator
+	Min          int               `yaml:"min"`
+	Max          int               `yaml:"max"`
+	AlwaysPrompt bool              `yaml:"always_prompt"` // always prompt for the crendentials
+	Pattern      string            `yaml:"pattern"`       // regular expression the whole value must match
+	Choices      []string          `yaml:"choices"`       // allowed values, mostly for the enum type
+	Default      string            `yaml:"default"`       // default value, can use template functions
+	Template     string            `yaml:"template"`      // template for computed attributes
+	When         map[string]string `yaml:"when"`          // only ask if the named attributes have these values
 }
 
 // Template is an action template for the create wizard.
@@ -151,6 +156,27 @@ func (w *Wizard) parseTemplates(ctx context.Context, s backend.Storage) ([]Templ
 	return parsedTpls, nil
 }
 
+func (t Template) hasAttribute(name string) bool {
+	for _, a := range t.Attributes {
+		if a.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lookup returns the index of the template with the given name or prefix.
+func (w *Wizard) Lookup(name string) (int, bool) {
+	for i, t := range w.Templates {
+		if strings.EqualFold(t.Name, name) || t.Prefix == name {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
 // ActionCallback is the callback for the creation calls to print and copy the credentials.
 type ActionCallback func(context.Context, *cli.Context, string, string, bool) error
 
@@ -179,6 +205,24 @@ func mkActFunc(tpl Template, s *root.Store, cb ActionCallback) func(context.Cont
 		name := c.Args().First()
 		store := c.String("store")
 
+		// values given with --set are used instead of asking the user. If a
+		// template was selected by name we never ask and rely on --set and the
+		// defaults only.
+		sets, err := parseSets(c.StringSlice("set"))
+		if err != nil {
+			return exit.Error(exit.Usage, err, "%s", err)
+		}
+		for k := range sets {
+			if !tpl.hasAttribute(k) {
+				return exit.Error(exit.Usage, nil, "template %q has no attribute %q", tpl.Name, k)
+			}
+		}
+		batch := c.String("template") != ""
+		if batch {
+			ctx = ctxutil.WithInteractive(ctx, false)
+			ctx = ctxutil.WithAlwaysYes(ctx, true)
+		}
+
 		// select store.
 		if store == "" {
 			store = cui.AskForStore(ctx, s)
@@ -195,12 +239,24 @@ func mkActFunc(tpl Template, s *root.Store, cb ActionCallback) func(context.Cont
 
 		out.Print(ctx, tpl.Welcome)
 
+		// ask returns the value given with --set or asks the user for it.
+		ask := func(step int, v Attribute, def string) (string, error) {
+			if sv, found := sets[v.Name]; found {
+				return sv, nil
+			}
+
+			return termio.AskForString(ctx, fmtfn(2, strconv.Itoa(step), v.prompt()), def)
+		}
+
 		// genPW is needed for the callback
 		var genPw bool
 		// password is needed for the callback
 		var password string
 		// hostname is needed in later iterations (e.g. password rule lookup)
 		var hostname string
+		// values holds all values entered so far. They are used for conditions
+		// and computed attributes.
+		values := make(map[string]string, len(tpl.Attributes))
 		// wantForName is a list of attributes that will be used to build the name
 		wantForName := set.Map(tpl.NameFrom)
 		// nameParts are the components the name will be built from
@@ -208,54 +264,75 @@ func mkActFunc(tpl Template, s *root.Store, cb ActionCallback) func(context.Cont
 		// step is only used for printing the progress
 		var step int
 		for _, v := range tpl.Attributes {
-			step++
 			k := v.Name
 
-			// if no prompt is set default to the key
-			if v.Prompt == "" {
-				v.Prompt = strings.ToTitle(k)
+			if !v.active(values) {
+				debug.Log("skipping attribute %s, conditions %+v not met", k, v.When)
+
+				continue
+			}
+			step++
+
+			def, err := v.defaultValue(ctx, values)
+			if err != nil {
+				return err
 			}
 
 			switch v.Type {
-			case "string":
-				sv, err := termio.AskForString(ctx, fmtfn(2, strconv.Itoa(step), v.Prompt), "")
+			case "string", "enum":
+				sv, err := ask(step, v, def)
 				if err != nil {
 					return err
 				}
-				if v.Min > 0 && len(sv) < v.Min {
-					return fmt.Errorf("%s is too short (needs %d)", v.Name, v.Min)
-				}
-				if v.Max > 0 && len(sv) > v.Min {
-					return fmt.Errorf("%s is too long (at most %d)", v.Name, v.Max)
+				if err := v.validate(sv); err != nil {
+					return err
 				}
 				if wantForName[k] {
 					nameParts = append(nameParts, sv)
 				}
+				values[k] = sv
 				_ = sec.Set(k, sv)
-			case "multiline":
-				ed := editor.Path(c)
-
-				content, err := renderTemplate(ctx, k, s)
+			case "computed":
+				sv, err := renderValue(ctx, k, v.Template, values)
 				if err != nil {
-					debug.Log("failed to render template %q: %s", k, err)
+					return err
 				}
-				content, err = editor.Invoke(ctx, ed, content)
-				if err != nil {
-					return exit.Error(exit.Unknown, err, "failed to invoke editor: %s", err)
+				if wantForName[k] {
+					nameParts = append(nameParts, sv)
+				}
+				values[k] = sv
+				_ = sec.Set(k, sv)
+			case "multiline":
+				var content []byte
+				if sv, found := sets[k]; found {
+					content = []byte(sv)
+				} else {
+					content, err = renderTemplate(ctx, k, s)
+					if err != nil {
+						debug.Log("failed to render template %q: %s", k, err)
+					}
+					if !batch {
+						content, err = editor.Invoke(ctx, editor.Path(c), content)
+						if err != nil {
+							return exit.Error(exit.Unknown, err, "failed to invoke editor: %s", err)
+						}
+					}
 				}
 				n, err := sec.Write(content)
 				if err != nil {
 					return fmt.Errorf("failed to write %d bytes to %s: %w", n, k, err)
 				}
 			case "hostname":
-				var def string
-				if k == "username" {
+				if def == "" && k == "username" {
 					def = config.String(ctx, "create.default-username")
 				}
-				sv, err := termio.AskForString(ctx, fmtfn(2, strconv.Itoa(step), v.Prompt), def)
+				sv, err := ask(step, v, def)
 				if err != nil {
 					return err
 				}
+				if err := v.validate(sv); err != nil {
+					return err
+				}
 				hostname = extractHostname(sv)
 				if hostname == "" {
 					return fmt.Errorf("can not parse URL %s", sv)
@@ -266,9 +343,21 @@ func mkActFunc(tpl Template, s *root.Store, cb ActionCallback) func(context.Cont
 				if u := pwrules.LookupChangeURL(ctx, hostname); u != "" {
 					_ = sec.Set("password-change-url", u)
 				}
+				values[k] = sv
 				_ = sec.Set(k, sv)
 			case "password":
-				var err error
+				if sv, found := sets[k]; found {
+					genPw = false
+					password = sv
+					if err := v.validate(password); err != nil {
+						return err
+					}
+					values[k] = password
+					sec.SetPassword(password)
+
+					continue
+				}
+
 				if !v.AlwaysPrompt {
 					genPw, err = termio.AskForBool(ctx, fmtfn(2, strconv.Itoa(step), "Generate Password?"), true)
 					if err != nil {
@@ -282,19 +371,22 @@ func mkActFunc(tpl Template, s *root.Store, cb ActionCallback) func(context.Cont
 						return err
 					}
 				} else {
-					password, err = termio.AskForPassword(ctx, v.Prompt, true)
+					if batch {
+						return fmt.Errorf("%s must be given with --set", k)
+					}
+					password, err = termio.AskForPassword(ctx, v.prompt(), true)
 					if err != nil {
 						return err
 					}
-					if v.Min > 0 && len(password) < v.Min {
-						return fmt.Errorf("%s is too short (needs %d)", v.Name, v.Min)
-					}
-					if v.Max > 0 && len(password) > v.Min {
-						return fmt.Errorf("%s is too long (at most %d)", v.Name, v.Max)
+					if err := v.validate(password); err != nil {
+						return err
 					}
 				}
 
+				values[k] = password
 				sec.SetPassword(password)
+			default:
+				return fmt.Errorf("unknown type %q for attribute %s", v.Type, k)
 			}
 		}
 
@@ -321,6 +413,9 @@ func mkActFunc(tpl Template, s *root.Store, cb ActionCallback) func(context.Cont
 
 		// force will also override the check for existing entries.
 		if s.Exists(ctx, name) && !force {
+			if batch {
+				return fmt.Errorf("secret %q already exists, use --force to overwrite", name)
+			}
 			step++
 			var err error
 			name, err = termio.AskForString(ctx, fmtfn(2, strconv.Itoa(step), "Secret already exists. Choose another path or enter to overwrite"), name)
@@ -361,10 +456,21 @@ func renderTemplate(ctx context.Context, name string, s *root.Store) ([]byte, er
 	return nc, nil
 }
 
-// generatePassword will walk through the password generation steps.
+// generatePassword will walk through the password generation steps. A charset
+// starting with mask: is used as a mask, see pwgen.Mask.
 func generatePassword(ctx context.Context, hostname, charset string) (string, error) {
 	defaultLength, _ := config.DefaultPasswordLengthFromEnv(ctx)
 
+	if mask, found := strings.CutPrefix(charset, "mask:"); found {
+		m, err := pwgen.NewMask(mask, nil)
+		if err != nil {
+			return "", fmt.Errorf("invalid mask %q: %w", mask, err)
+		}
+		out.Noticef(ctx, "Using mask %q (%.1f bits of entropy) ...", mask, m.Entropy())
+
+		return m.Password(), nil
+	}
+
 	if charset != "" {
 		length, err := termio.AskForInt(ctx, fmtfn(4, "a", "How long?"), 4)
 		if err != nil {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/store/mockstore/inmem"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -105,3 +106,79 @@ func TestExtractHostname(t *testing.T) {
 		assert.Equal(t, out, extractHostname(in))
 	}
 }
+
+func TestGeneratePasswordMask(t *testing.T) {
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	pw, err := generatePassword(ctx, "", "mask:?d?d?d?d-[ab]")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{4}-[ab]$`, pw)
+
+	_, err = generatePassword(ctx, "", "mask:?x")
+	require.Error(t, err)
+}
+
+func TestAttributeValidate(t *testing.T) {
+	t.Parallel()
+
+	a := Attribute{Name: "port", Min: 2, Max: 5, Pattern: `[0-9]+`}
+	require.NoError(t, a.validate("8080"))
+	require.Error(t, a.validate("1"))
+	require.Error(t, a.validate("123456"))
+	require.Error(t, a.validate("80a"), "pattern must match the whole value")
+
+	a = Attribute{Name: "kind", Type: "enum", Choices: []string{"ssh", "web"}}
+	require.NoError(t, a.validate("ssh"))
+	require.Error(t, a.validate("ftp"))
+	assert.Equal(t, "KIND (ssh|web)", a.prompt())
+
+	a = Attribute{Name: "bad", Pattern: `(`}
+	require.Error(t, a.validate("foo"))
+}
+
+func TestAttributeActive(t *testing.T) {
+	t.Parallel()
+
+	a := Attribute{Name: "key", When: map[string]string{"kind": "ssh"}}
+	assert.True(t, a.active(map[string]string{"kind": "ssh"}))
+	assert.False(t, a.active(map[string]string{"kind": "web"}))
+	assert.False(t, a.active(map[string]string{}))
+	assert.True(t, Attribute{Name: "foo"}.active(nil))
+}
+
+func TestAttributeDefault(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	cfg, _ := config.FromContext(ctx)
+	require.NoError(t, cfg.Set("", "create.default-username", "john"))
+
+	values := map[string]string{"host": "example.org"}
+
+	def, err := Attribute{Name: "username", Default: `{{ config "create.default-username" }}`}.defaultValue(ctx, values)
+	require.NoError(t, err)
+	assert.Equal(t, "john", def)
+
+	def, err = Attribute{Name: "kind", Choices: []string{"ssh", "web"}}.defaultValue(ctx, values)
+	require.NoError(t, err)
+	assert.Equal(t, "ssh", def)
+
+	sv, err := renderValue(ctx, "url", "https://{{ .host }}/login{{ .missing }}", values)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/login", sv)
+
+	_, err = renderValue(ctx, "url", "{{ .host", values)
+	require.Error(t, err)
+}
+
+func TestParseSets(t *testing.T) {
+	t.Parallel()
+
+	sets, err := parseSets([]string{"user=john", "url=https://example.org/?a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "john", "url": "https://example.org/?a=b"}, sets)
+
+	_, err = parseSets([]string{"user"})
+	require.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -40,3 +42,40 @@ func GetSelection(ctx context.Context, prompt string, choices []string) (string,
 
 	return "default", i
 }
+
+// SideBySide renders two texts next to each other. Long lines are truncated
+// so the columns fit into a regular terminal.
+func SideBySide(leftTitle, rightTitle string, left, right []string) string {
+	const maxWidth = 38
+
+	width := utf8.RuneCountInString(leftTitle)
+	for _, l := range left {
+		width = max(width, utf8.RuneCountInString(l))
+	}
+	width = min(width, maxWidth)
+
+	rows := max(len(left), len(right))
+	lines := make([]string, 0, rows+2)
+	lines = append(lines, fmt.Sprintf("%-*s | %s", width, leftTitle, rightTitle))
+	lines = append(lines, strings.Repeat("-", width)+"-+-"+strings.Repeat("-", width))
+	for i := range rows {
+		var l, r string
+		if i < len(left) {
+			l = truncate(left[i], maxWidth)
+		}
+		if i < len(right) {
+			r = truncate(right[i], maxWidth)
+		}
+		lines = append(lines, strings.TrimRight(fmt.Sprintf("%-*s | %s", width, l, r), " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n-1]) + "…"
+}
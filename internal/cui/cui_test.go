@@ -1,6 +1,7 @@
 package cui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/config"
@@ -18,3 +19,15 @@ func TestGetSelection(t *testing.T) {
 	assert.Equal(t, "impossible", act)
 	assert.Equal(t, 0, sel)
 }
+
+func TestSideBySide(t *testing.T) {
+	t.Parallel()
+
+	got := SideBySide("mine", "theirs", []string{"*****", "user: foo"}, []string{"*****", "user: bar", strings.Repeat("x", 50)})
+	want := `mine      | theirs
+----------+----------
+*****     | *****
+user: foo | user: bar
+          | ` + strings.Repeat("x", 37) + "…"
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,116 @@
+package diff
+
+import "strings"
+
+// maxLCS limits the size of the LCS table. Larger inputs are treated as
+// completely changed.
+const maxLCS = 4 << 20
+
+// Op is the kind of change of a line.
+type Op int
+
+const (
+	// Equal is used for unchanged lines.
+	Equal Op = iota
+	// Insert is used for lines only present on the right side.
+	Insert
+	// Delete is used for lines only present on the left side.
+	Delete
+)
+
+// Line is a single line of a line based diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// String returns the line with a diff style prefix.
+func (l Line) String() string {
+	switch l.Op {
+	case Insert:
+		return "+ " + l.Text
+	case Delete:
+		return "- " + l.Text
+	default:
+		return "  " + l.Text
+	}
+}
+
+// Matches returns the line numbers of the longest common subsequence of l
+// and r as a mapping from lines in l to lines in r.
+func Matches[K comparable](l, r []K) map[int]int {
+	m := make(map[int]int, min(len(l), len(r)))
+	if len(l) == 0 || len(r) == 0 || len(l)*len(r) > maxLCS {
+		return m
+	}
+
+	// lcs[i][j] is the length of the LCS of l[i:] and r[j:].
+	lcs := make([][]int, len(l)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(r)+1)
+	}
+	for i := len(l) - 1; i >= 0; i-- {
+		for j := len(r) - 1; j >= 0; j-- {
+			if l[i] == r[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(l) && j < len(r); {
+		switch {
+		case l[i] == r[j]:
+			m[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return m
+}
+
+// Lines returns a line based diff from l to r.
+func Lines(l, r []string) []Line {
+	m := Matches(l, r)
+	res := make([]Line, 0, max(len(l), len(r)))
+
+	var j int
+	for i, line := range l {
+		k, found := m[i]
+		if !found {
+			res = append(res, Line{Op: Delete, Text: line})
+
+			continue
+		}
+		for ; j < k; j++ {
+			res = append(res, Line{Op: Insert, Text: r[j]})
+		}
+		res = append(res, Line{Op: Equal, Text: line})
+		j++
+	}
+	for ; j < len(r); j++ {
+		res = append(res, Line{Op: Insert, Text: r[j]})
+	}
+
+	return res
+}
+
+// Changes renders only the changed lines of a line based diff from l to r.
+func Changes(l, r []string) string {
+	var sb strings.Builder
+	for _, line := range Lines(l, r) {
+		if line.Op == Equal {
+			continue
+		}
+		sb.WriteString(line.String())
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,33 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []Line{
+		{Op: Delete, Text: "secret"},
+		{Op: Insert, Text: "changed"},
+		{Op: Equal, Text: "user: john"},
+		{Op: Delete, Text: "url: example.com"},
+		{Op: Insert, Text: "url: example.org"},
+		{Op: Insert, Text: "notes"},
+	}, Lines(
+		[]string{"secret", "user: john", "url: example.com"},
+		[]string{"changed", "user: john", "url: example.org", "notes"},
+	))
+
+	assert.Equal(t, []Line{{Op: Insert, Text: "foo"}}, Lines(nil, []string{"foo"}))
+	assert.Equal(t, []Line{{Op: Delete, Text: "foo"}}, Lines([]string{"foo"}, nil))
+}
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "- b\n+ c\n", Changes([]string{"a", "b"}, []string{"a", "c"}))
+	assert.Equal(t, "", Changes([]string{"a"}, []string{"a"}))
+}
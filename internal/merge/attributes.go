@@ -0,0 +1,138 @@
+package merge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/store"
+)
+
+const (
+	// DriverName is the description of the merge driver in the git config.
+	DriverName = "gopass secret merge driver"
+	// DriverCommand is the command git runs to merge encrypted secrets.
+	DriverCommand = "gopass git merge-driver %O %A %B %P"
+
+	attributesFile = ".gitattributes"
+)
+
+// attributes configures the diff and merge drivers for encrypted secrets.
+// The first field is the pattern, the others are its attributes.
+var attributes = [][]string{
+	{"*.gpg", "diff=gpg", "merge=gopass"},
+	{"*.age", "merge=gopass"},
+}
+
+// UpdateAttributes adds the attributes for encrypted secrets to the content
+// of a .gitattributes file. Existing lines for the same pattern are extended,
+// other lines are kept as they are. The returned bool is false if nothing
+// had to be changed.
+func UpdateAttributes(buf []byte) ([]byte, bool) {
+	lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+	if len(buf) < 1 {
+		lines = nil
+	}
+
+	var changed bool
+	for _, want := range attributes {
+		i := findPattern(lines, want[0])
+		if i < 0 {
+			lines = append(lines, strings.Join(want, " "))
+			changed = true
+
+			continue
+		}
+
+		line, ok := setAttributes(lines[i], want[1:])
+		if ok {
+			lines[i] = line
+			changed = true
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), changed
+}
+
+// findPattern returns the index of the last line for pattern or -1. git uses
+// the last matching line, so that's the one to change.
+func findPattern(lines []string, pattern string) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		fields := strings.Fields(lines[i])
+		if len(fields) > 0 && fields[0] == pattern {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// setAttributes sets the given name=value attributes in line. Attributes with
+// the same name are replaced, even if they are unset (-name) or unspecified
+// (!name). All others are kept.
+func setAttributes(line string, want []string) (string, bool) {
+	fields := strings.Fields(line)
+
+	var changed bool
+	for _, attr := range want {
+		name, _, _ := strings.Cut(attr, "=")
+
+		found := false
+		for i, f := range fields[1:] {
+			if n, _, _ := strings.Cut(strings.TrimLeft(f, "-!"), "="); n != name {
+				continue
+			}
+			found = true
+			if f != attr {
+				fields[i+1] = attr
+				changed = true
+			}
+		}
+		if !found {
+			fields = append(fields, attr)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return line, false
+	}
+
+	return strings.Join(fields, " "), true
+}
+
+type committer interface {
+	Add(ctx context.Context, files ...string) error
+	Commit(ctx context.Context, msg string) error
+}
+
+// WriteAttributes updates .gitattributes in the repository at dir and
+// commits it if it changed.
+func WriteAttributes(ctx context.Context, repo committer, dir string) error {
+	fn := filepath.Join(dir, attributesFile)
+	buf, err := os.ReadFile(fn)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", fn, err)
+	}
+
+	buf, changed := UpdateAttributes(buf)
+	if !changed {
+		return nil
+	}
+
+	if err := os.WriteFile(fn, buf, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", fn, err)
+	}
+	if err := repo.Add(ctx, fn); err != nil {
+		return fmt.Errorf("failed to add %s: %w", fn, err)
+	}
+
+	if err := repo.Commit(ctx, "Configure git repository for gpg file diff and merge."); err != nil && !errors.Is(err, store.ErrGitNothingToCommit) {
+		return fmt.Errorf("failed to commit %s: %w", fn, err)
+	}
+
+	return nil
+}
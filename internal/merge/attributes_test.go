@@ -0,0 +1,56 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAttributes(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		in      string
+		out     string
+		changed bool
+	}{
+		{
+			name:    "empty",
+			out:     "*.gpg diff=gpg merge=gopass\n*.age merge=gopass\n",
+			changed: true,
+		},
+		{
+			name:    "up to date",
+			in:      "*.gpg diff=gpg merge=gopass\n*.age merge=gopass\n",
+			out:     "*.gpg diff=gpg merge=gopass\n*.age merge=gopass\n",
+			changed: false,
+		},
+		{
+			name:    "extend existing line",
+			in:      "*.gpg diff=gpg\n*.txt text",
+			out:     "*.gpg diff=gpg merge=gopass\n*.txt text\n*.age merge=gopass\n",
+			changed: true,
+		},
+		{
+			name:    "replace other driver",
+			in:      "*.gpg merge=binary -text\n*.age merge=gopass\n",
+			out:     "*.gpg merge=gopass -text diff=gpg\n*.age merge=gopass\n",
+			changed: true,
+		},
+		{
+			name:    "last matching line wins",
+			in:      "*.gpg diff=gpg\n# override\n*.gpg -diff\n",
+			out:     "*.gpg diff=gpg\n# override\n*.gpg diff=gpg merge=gopass\n*.age merge=gopass\n",
+			changed: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, changed := UpdateAttributes([]byte(tc.in))
+			assert.Equal(t, tc.out, string(out))
+			assert.Equal(t, tc.changed, changed)
+		})
+	}
+}
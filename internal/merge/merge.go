@@ -0,0 +1,200 @@
+// Package merge implements a three-way merge for decrypted secrets. It is used
+// by the git merge driver to merge concurrent changes to the same secret.
+package merge
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/diff"
+)
+
+const (
+	markerOurs   = "<<<<<<< ours"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>> theirs"
+
+	kvSep = ": "
+)
+
+// Secrets does a three-way merge of the decrypted content of a secret. It first
+// tries a line based merge that preserves the layout of the secret. If that
+// conflicts it merges the password, each key and the body separately. It
+// returns the merged content and false if there were conflicts. Conflicts are
+// marked with git style conflict markers inside the content.
+func Secrets(base, ours, theirs []byte) ([]byte, bool) {
+	if merged, ok := Lines(splitLines(base), splitLines(ours), splitLines(theirs)); ok {
+		return joinLines(merged), true
+	}
+
+	b, o, t := parse(base), parse(ours), parse(theirs)
+	clean := true
+	res := make([]string, 0, len(o.body)+len(o.keys)+1)
+
+	if pw, ok := values(b.password, o.password, t.password); ok {
+		res = append(res, pw...)
+	} else {
+		clean = false
+		res = append(res, conflict(o.password, t.password)...)
+	}
+
+	keys := slices.Clone(o.keys)
+	for _, k := range t.keys {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+		vs, ok := values(b.kvp[k], o.kvp[k], t.kvp[k])
+		if !ok {
+			clean = false
+			res = append(res, conflict(kvLines(k, o.kvp[k]), kvLines(k, t.kvp[k]))...)
+
+			continue
+		}
+		res = append(res, kvLines(k, vs)...)
+	}
+
+	body, ok := Lines(b.body, o.body, t.body)
+	if !ok {
+		clean = false
+	}
+	res = append(res, body...)
+
+	return joinLines(res), clean
+}
+
+// Lines does a three-way merge of the given lines (diff3). Changes that only
+// happened on one side are applied, overlapping changes that differ are
+// marked as conflicts. It returns false if there were any conflicts.
+func Lines(base, ours, theirs []string) ([]string, bool) {
+	ma := diff.Matches(base, ours)
+	mb := diff.Matches(base, theirs)
+
+	res := make([]string, 0, max(len(ours), len(theirs)))
+	clean := true
+
+	var i, a, b int
+	for {
+		// find the next base line that is unchanged in both versions.
+		j := i
+		for ; j < len(base); j++ {
+			_, inA := ma[j]
+			_, inB := mb[j]
+			if inA && inB {
+				break
+			}
+		}
+
+		ja, jb := len(ours), len(theirs)
+		if j < len(base) {
+			ja, jb = ma[j], mb[j]
+		}
+
+		vs, ok := values(base[i:j], ours[a:ja], theirs[b:jb])
+		if !ok {
+			clean = false
+			vs = conflict(ours[a:ja], theirs[b:jb])
+		}
+		res = append(res, vs...)
+
+		if j >= len(base) {
+			break
+		}
+
+		res = append(res, base[j])
+		i, a, b = j+1, ja+1, jb+1
+	}
+
+	return res, clean
+}
+
+// values merges a single chunk. A change on only one side wins, the same
+// change on both sides is fine, different changes are a conflict.
+func values(base, ours, theirs []string) ([]string, bool) {
+	switch {
+	case slices.Equal(ours, theirs):
+		return ours, true
+	case slices.Equal(base, ours):
+		return theirs, true
+	case slices.Equal(base, theirs):
+		return ours, true
+	default:
+		return nil, false
+	}
+}
+
+func conflict(ours, theirs []string) []string {
+	res := make([]string, 0, len(ours)+len(theirs)+3)
+	res = append(res, markerOurs)
+	res = append(res, ours...)
+	res = append(res, markerSep)
+	res = append(res, theirs...)
+
+	return append(res, markerTheirs)
+}
+
+type secret struct {
+	password []string
+	keys     []string
+	kvp      map[string][]string
+	body     []string
+}
+
+// parse splits a secret into password, key-value pairs and body in the same
+// way as secrets.ParseAKV does but keeps the order of the keys.
+func parse(in []byte) secret {
+	s := secret{
+		kvp: map[string][]string{},
+	}
+
+	for i, line := range splitLines(in) {
+		if i == 0 {
+			s.password = []string{line}
+
+			continue
+		}
+
+		key, val, found := strings.Cut(line, kvSep)
+		if !found {
+			s.body = append(s.body, line)
+
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		if _, seen := s.kvp[key]; !seen {
+			s.keys = append(s.keys, key)
+		}
+		s.kvp[key] = append(s.kvp[key], val)
+	}
+
+	return s
+}
+
+func kvLines(key string, vs []string) []string {
+	res := make([]string, 0, len(vs))
+	for _, v := range vs {
+		res = append(res, key+kvSep+v)
+	}
+
+	return res
+}
+
+func splitLines(in []byte) []string {
+	s := strings.TrimSuffix(string(in), "\n")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+func joinLines(lines []string) []byte {
+	if len(lines) < 1 {
+		return nil
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,136 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		base   []string
+		ours   []string
+		theirs []string
+		out    []string
+		clean  bool
+	}{
+		{
+			name:  "empty",
+			clean: true,
+			out:   []string{},
+		},
+		{
+			name:   "only ours changed",
+			base:   []string{"a", "b", "c"},
+			ours:   []string{"a", "B", "c"},
+			theirs: []string{"a", "b", "c"},
+			out:    []string{"a", "B", "c"},
+			clean:  true,
+		},
+		{
+			name:   "both changed different lines",
+			base:   []string{"a", "b", "c"},
+			ours:   []string{"A", "b", "c"},
+			theirs: []string{"a", "b", "C", "d"},
+			out:    []string{"A", "b", "C", "d"},
+			clean:  true,
+		},
+		{
+			name:   "same change",
+			base:   []string{"a", "b"},
+			ours:   []string{"a", "c"},
+			theirs: []string{"a", "c"},
+			out:    []string{"a", "c"},
+			clean:  true,
+		},
+		{
+			name:   "deleted on one side",
+			base:   []string{"a", "b", "c"},
+			ours:   []string{"a", "c"},
+			theirs: []string{"a", "b", "c"},
+			out:    []string{"a", "c"},
+			clean:  true,
+		},
+		{
+			name:   "conflict",
+			base:   []string{"a", "b", "c"},
+			ours:   []string{"a", "x", "c"},
+			theirs: []string{"a", "y", "c"},
+			out:    []string{"a", markerOurs, "x", markerSep, "y", markerTheirs, "c"},
+			clean:  false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, clean := Lines(tc.base, tc.ours, tc.theirs)
+			assert.Equal(t, tc.clean, clean)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		base   string
+		ours   string
+		theirs string
+		out    string
+		clean  bool
+	}{
+		{
+			name:   "line merge",
+			base:   "secret\nuser: john\nurl: example.org\n",
+			ours:   "secret\nuser: jane\nurl: example.org\n",
+			theirs: "secret\nuser: john\nurl: example.org\nnotes\n",
+			out:    "secret\nuser: jane\nurl: example.org\nnotes\n",
+			clean:  true,
+		},
+		{
+			name:   "key merge",
+			base:   "secret\nuser: john\n",
+			ours:   "secret\nuser: john\nurl: example.org\n",
+			theirs: "changed\nuser: john\npin: 1234\n",
+			out:    "changed\nuser: john\nurl: example.org\npin: 1234\n",
+			clean:  true,
+		},
+		{
+			name:   "added on both sides",
+			base:   "",
+			ours:   "secret\nuser: john\n",
+			theirs: "secret\nurl: example.org\n",
+			out:    "secret\nuser: john\nurl: example.org\n",
+			clean:  true,
+		},
+		{
+			name:   "key conflict",
+			base:   "secret\nuser: john\n",
+			ours:   "secret\nuser: jane\nurl: example.org\n",
+			theirs: "secret\nuser: jim\npin: 1234\n",
+			out:    "secret\n" + markerOurs + "\nuser: jane\n" + markerSep + "\nuser: jim\n" + markerTheirs + "\nurl: example.org\npin: 1234\n",
+			clean:  false,
+		},
+		{
+			name:   "password conflict",
+			base:   "secret\n",
+			ours:   "foo\nuser: john\n",
+			theirs: "bar\n",
+			out:    markerOurs + "\nfoo\n" + markerSep + "\nbar\n" + markerTheirs + "\nuser: john\n",
+			clean:  false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, clean := Secrets([]byte(tc.base), []byte(tc.ours), []byte(tc.theirs))
+			assert.Equal(t, tc.clean, clean)
+			assert.Equal(t, tc.out, string(out))
+		})
+	}
+}
@@ -7,6 +7,7 @@ type contextKey int
 const (
 	ctxKeyPrefix contextKey = iota
 	ctxKeyNewline
+	ctxKeyNoticeStderr
 )
 
 // WithPrefix returns a context with the given prefix set.
@@ -53,3 +54,19 @@ func HasNewline(ctx context.Context) bool {
 
 	return bv
 }
+
+// WithNoticeStderr returns a context with the flag value for printing notices
+// to stderr set. Use it if stdout is meant to be consumed by other programs.
+func WithNoticeStderr(ctx context.Context, stderr bool) context.Context {
+	return context.WithValue(ctx, ctxKeyNoticeStderr, stderr)
+}
+
+// IsNoticeStderr returns the value of notice stderr or the default (false).
+func IsNoticeStderr(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyNoticeStderr).(bool)
+	if !ok {
+		return false
+	}
+
+	return bv
+}
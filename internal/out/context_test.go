@@ -32,3 +32,12 @@ func TestNewline(t *testing.T) {
 	assert.True(t, HasNewline(ctx))
 	assert.False(t, HasNewline(WithNewline(ctx, false)))
 }
+
+func TestNoticeStderr(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+
+	assert.False(t, IsNoticeStderr(ctx))
+	assert.True(t, IsNoticeStderr(WithNoticeStderr(ctx, true)))
+}
@@ -53,13 +53,21 @@ func Printf(ctx context.Context, format string, args ...any) {
 	fmt.Fprintf(Stdout, Prefix(ctx)+format+newline(ctx), args...)
 }
 
+func noticeWriter(ctx context.Context) io.Writer {
+	if IsNoticeStderr(ctx) {
+		return Stderr
+	}
+
+	return Stdout
+}
+
 // Notice prints the string with an exclamation mark.
 func Notice(ctx context.Context, arg any) {
 	if ctxutil.IsHidden(ctx) {
 		return
 	}
 	debug.LogN(1, "NOTICE: %s", arg)
-	fmt.Fprintf(Stdout, Prefix(ctx)+"⚠ %s"+newline(ctx), arg)
+	fmt.Fprintf(noticeWriter(ctx), Prefix(ctx)+"⚠ %s"+newline(ctx), arg)
 }
 
 // Noticef prints the string with an exclamation mark in front.
@@ -68,7 +76,7 @@ func Noticef(ctx context.Context, format string, args ...any) {
 		return
 	}
 	debug.LogN(1, "NOTICE: "+format, args...)
-	fmt.Fprintf(Stdout, Prefix(ctx)+"⚠ "+format+newline(ctx), args...)
+	fmt.Fprintf(noticeWriter(ctx), Prefix(ctx)+"⚠ "+format+newline(ctx), args...)
 }
 
 // Error prints the string with a red cross in front.
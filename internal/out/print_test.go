@@ -30,3 +30,24 @@ func TestPrint(t *testing.T) {
 	assert.Equal(t, "foo = 42", buf.String())
 	buf.Reset()
 }
+
+func TestNotice(t *testing.T) {
+	ctx := config.NewContextInMemory()
+	bufOut := &bytes.Buffer{}
+	bufErr := &bytes.Buffer{}
+	Stdout = bufOut
+	Stderr = bufErr
+	defer func() {
+		Stdout = os.Stdout
+		Stderr = os.Stderr
+	}()
+
+	Noticef(ctx, "%s = %d", "foo", 42)
+	assert.Equal(t, "⚠ foo = 42\n", bufOut.String())
+	assert.Equal(t, "", bufErr.String())
+	bufOut.Reset()
+
+	Notice(WithNoticeStderr(ctx, true), "foo")
+	assert.Equal(t, "", bufOut.String())
+	assert.Equal(t, "⚠ foo\n", bufErr.String())
+}
@@ -0,0 +1,196 @@
+package recipients
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/set"
+)
+
+// GroupPrefix marks a reference to a group in a recipients file, e.g. @sre.
+const GroupPrefix = "@"
+
+var reGroupName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// IsGroup returns true if the recipient is a reference to a group.
+func IsGroup(id string) bool {
+	return strings.HasPrefix(strings.TrimSpace(id), GroupPrefix)
+}
+
+// GroupName returns the name of the referenced group.
+func GroupName(id string) string {
+	return strings.TrimPrefix(strings.TrimSpace(id), GroupPrefix)
+}
+
+// Groups are named lists of recipients. They are stored in one file per store
+// with one section per group:
+//
+//	[sre]
+//	0xDEADBEEF # Alice
+//	0xFEEDBEEF
+//
+// Like Recipients it tries to retain comments while manipulating the groups.
+type Groups struct {
+	header strings.Builder
+	g      map[string]*Recipients
+}
+
+// NewGroups creates an empty set of groups.
+func NewGroups() *Groups {
+	return &Groups{
+		g: make(map[string]*Recipients, 2),
+	}
+}
+
+// Names returns the sorted names of all groups.
+func (g *Groups) Names() []string {
+	return set.SortedKeys(g.g)
+}
+
+// Get returns the members of a group.
+func (g *Groups) Get(name string) (*Recipients, bool) {
+	rs, found := g.g[GroupName(name)]
+
+	return rs, found
+}
+
+// Add adds a recipient to a group. The group is created if it does not exist.
+// It returns true if the recipient was added.
+func (g *Groups) Add(name, key string) (bool, error) {
+	name = GroupName(name)
+	if !reGroupName.MatchString(name) {
+		return false, fmt.Errorf("invalid group name %q", name)
+	}
+	if IsGroup(key) {
+		return false, fmt.Errorf("groups can not contain other groups")
+	}
+
+	rs, found := g.g[name]
+	if !found {
+		rs = New()
+		g.g[name] = rs
+	}
+
+	return rs.Add(key), nil
+}
+
+// Remove removes a recipient from a group. It returns true if the recipient
+// was a member and got removed.
+func (g *Groups) Remove(name, key string) bool {
+	rs, found := g.g[GroupName(name)]
+	if !found {
+		return false
+	}
+
+	return rs.Remove(key)
+}
+
+// Expand replaces all group references with the members of these groups.
+func (g *Groups) Expand(ids []string) ([]string, error) {
+	out := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !IsGroup(id) {
+			out[id] = true
+
+			continue
+		}
+
+		rs, found := g.Get(id)
+		if !found {
+			return nil, fmt.Errorf("unknown recipient group %q", id)
+		}
+		for _, k := range rs.IDs() {
+			out[k] = true
+		}
+	}
+
+	return set.SortedKeys(out), nil
+}
+
+// Marshal writes all groups, sorted by name.
+func (g *Groups) Marshal() []byte {
+	out := bytes.Buffer{}
+	out.WriteString(g.header.String())
+
+	for i, name := range g.Names() {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("[" + name + "]\n")
+		if rs := g.g[name]; rs.Len() > 0 {
+			out.Write(rs.Marshal())
+		}
+	}
+
+	return out.Bytes()
+}
+
+// Hash returns the hex encoded SHA256 sum of the groups.
+func (g *Groups) Hash() string {
+	h := sha256.New()
+	_, _ = h.Write(g.Marshal())
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// UnmarshalGroups parses a group file.
+func UnmarshalGroups(buf []byte) (*Groups, error) {
+	in := strings.ReplaceAll(string(buf), "\r", "\n")
+
+	g := NewGroups()
+	sections := make(map[string]*strings.Builder, 2)
+
+	var cur *strings.Builder
+	s := bufio.NewScanner(strings.NewReader(in))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if !reGroupName.MatchString(name) {
+				return nil, fmt.Errorf("invalid group name %q", name)
+			}
+			if _, found := sections[name]; found {
+				return nil, fmt.Errorf("duplicate group %q", name)
+			}
+			cur = &strings.Builder{}
+			sections[name] = cur
+
+			continue
+		}
+
+		if cur == nil {
+			if line != "" && !strings.HasPrefix(line, "#") {
+				return nil, fmt.Errorf("recipient %q outside of a group", line)
+			}
+			g.header.WriteString(line)
+			g.header.WriteString("\n")
+
+			continue
+		}
+
+		cur.WriteString(line)
+		cur.WriteString("\n")
+	}
+
+	for name, sb := range sections {
+		// blank lines between groups are added by Marshal.
+		rs := New()
+		if body := strings.TrimSpace(sb.String()); body != "" {
+			rs = Unmarshal([]byte(body + "\n"))
+		}
+		for _, k := range rs.IDs() {
+			if IsGroup(k) {
+				return nil, fmt.Errorf("group %q can not contain other groups", name)
+			}
+		}
+		g.g[name] = rs
+	}
+
+	return g, nil
+}
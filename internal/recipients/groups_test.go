@@ -0,0 +1,93 @@
+package recipients
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups(t *testing.T) {
+	t.Parallel()
+
+	in := `# team groups
+[sre]
+0xDEADBEEF # Alice
+0xFEEDBEEF
+
+[dev]
+0xCAFEBABE
+`
+	g, err := UnmarshalGroups([]byte(in))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev", "sre"}, g.Names())
+
+	rs, found := g.Get("@sre")
+	require.True(t, found)
+	assert.Equal(t, []string{"0xDEADBEEF", "0xFEEDBEEF"}, rs.IDs())
+
+	ids, err := g.Expand([]string{"@sre", "0xCAFEBABE", "0x12345678"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0x12345678", "0xCAFEBABE", "0xDEADBEEF", "0xFEEDBEEF"}, ids)
+
+	_, err = g.Expand([]string{"@ops"})
+	require.Error(t, err)
+
+	hash := g.Hash()
+
+	added, err := g.Add("dev", "0x12345678")
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = g.Add("dev", "0x12345678")
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.NotEqual(t, hash, g.Hash())
+
+	_, err = g.Add("dev", "@sre")
+	require.Error(t, err)
+	_, err = g.Add("in valid", "0x12345678")
+	require.Error(t, err)
+
+	assert.True(t, g.Remove("@dev", "0x12345678"))
+	assert.False(t, g.Remove("dev", "0x12345678"))
+	assert.False(t, g.Remove("ops", "0x12345678"))
+
+	// comments are retained and groups are sorted.
+	want := `# team groups
+[dev]
+0xCAFEBABE
+
+[sre]
+0xDEADBEEF # Alice
+0xFEEDBEEF
+`
+	assert.Equal(t, want, string(g.Marshal()))
+
+	g2, err := UnmarshalGroups(g.Marshal())
+	require.NoError(t, err)
+	assert.Equal(t, g.Hash(), g2.Hash())
+}
+
+func TestUnmarshalGroupsInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{
+		"0xDEADBEEF\n[sre]\n",
+		"[sre]\n0xDEADBEEF\n[sre]\n0xFEEDBEEF\n",
+		"[sre]\n@dev\n",
+		"[s r e]\n",
+	} {
+		_, err := UnmarshalGroups([]byte(in))
+		require.Error(t, err, in)
+	}
+}
+
+func TestIsGroup(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsGroup("@sre"))
+	assert.True(t, IsGroup(" @sre "))
+	assert.False(t, IsGroup("0xDEADBEEF"))
+	assert.Equal(t, "sre", GroupName("@sre"))
+	assert.Equal(t, "sre", GroupName("sre"))
+}
@@ -0,0 +1,269 @@
+// Package reference implements references from a field of one secret to
+// the password or a field of another secret, e.g. `password: !ref db/prod`
+// or `token: ref+gopass://ci/token#value`.
+package reference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+)
+
+const (
+	tagPrefix = "!ref "
+	urlPrefix = "ref+gopass://"
+	// PasswordKey references the password of a secret unless it has a
+	// field with this name.
+	PasswordKey = "password"
+)
+
+var (
+	// ErrDangling is returned if a referenced secret or field does not exist.
+	ErrDangling = errors.New("dangling reference")
+	// ErrCycle is returned if references point back to themselves.
+	ErrCycle = errors.New("reference cycle")
+	// ErrMount is returned if a reference points to a secret in another
+	// mount than the secret it is in.
+	ErrMount = errors.New("reference to another mount")
+)
+
+// Getter is the subset of a store needed to resolve references.
+type Getter interface {
+	Get(context.Context, string) (gopass.Secret, error)
+}
+
+// mountPointer is implemented by stores with mounts. References never leave
+// the mount of the secret they are in. Otherwise anyone who can write to a
+// shared mount could copy secrets from any other mount of its users, e.g.
+// with `!ref personal/bank`.
+type mountPointer interface {
+	MountPoint(string) string
+}
+
+// Ref is a reference to the password (empty Key) or a field of a secret.
+type Ref struct {
+	Name string
+	Key  string
+	url  bool
+}
+
+// String returns the reference as `name#key`.
+func (r Ref) String() string {
+	if r.Key == "" {
+		return r.Name
+	}
+
+	return r.Name + "#" + r.Key
+}
+
+// format returns the reference in the syntax it was written in.
+func (r Ref) format() string {
+	if r.url {
+		return urlPrefix + r.String()
+	}
+
+	return tagPrefix + r.String()
+}
+
+// Parse returns the reference in the given value, if any.
+func Parse(value string) (Ref, bool) {
+	v := strings.TrimSpace(value)
+
+	var r Ref
+	switch {
+	case strings.HasPrefix(v, tagPrefix):
+		v = strings.TrimPrefix(v, tagPrefix)
+	case strings.HasPrefix(v, urlPrefix):
+		v = strings.TrimPrefix(v, urlPrefix)
+		r.url = true
+	default:
+		return r, false
+	}
+
+	name, key, _ := strings.Cut(strings.TrimSpace(v), "#")
+	r.Name = strings.Trim(name, "/")
+	r.Key = key
+
+	return r, r.Name != ""
+}
+
+// Field is a reference found in a secret. Key is empty for the first line
+// of the secret, i.e. the password.
+type Field struct {
+	Key string
+	Ref Ref
+}
+
+// Refs returns all references in a secret. Only key-value secrets can
+// contain references.
+func Refs(sec gopass.Secret) []Field {
+	var fields []Field
+	_, _ = scan(sec, func(key string, ref Ref) (string, bool, error) {
+		fields = append(fields, Field{Key: key, Ref: ref})
+
+		return "", false, nil
+	})
+
+	return fields
+}
+
+// Resolve returns a copy of the secret with all references replaced by the
+// values they point to. Secrets without references are returned as is.
+func Resolve(ctx context.Context, g Getter, name string, sec gopass.Secret) (gopass.Secret, error) {
+	name = strings.Trim(name, "/")
+
+	return scan(sec, func(key string, ref Ref) (string, bool, error) {
+		v, err := lookup(ctx, g, ref, []string{Ref{Name: name, Key: key}.String()}, mountOf(g, name))
+		if err != nil {
+			return "", false, err
+		}
+
+		return v, true, nil
+	})
+}
+
+// Check returns an error for each reference in the secret that can not be
+// resolved.
+func Check(ctx context.Context, g Getter, name string, sec gopass.Secret) []error {
+	name = strings.Trim(name, "/")
+
+	var errs []error
+	for _, f := range Refs(sec) {
+		if _, err := lookup(ctx, g, f.Ref, []string{Ref{Name: name, Key: f.Key}.String()}, mountOf(g, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Rename rewrites all references to secrets that were moved. fn returns the
+// new name of a moved secret. The syntax of each reference is kept.
+func Rename(sec gopass.Secret, fn func(string) (string, bool)) (gopass.Secret, bool) {
+	var changed bool
+	out, _ := scan(sec, func(_ string, ref Ref) (string, bool, error) {
+		to, ok := fn(ref.Name)
+		if !ok {
+			return "", false, nil
+		}
+		ref.Name = to
+		changed = true
+
+		return ref.format(), true, nil
+	})
+
+	return out, changed
+}
+
+// scan calls fn for every reference in the secret. If fn returns true the
+// value of the field is replaced.
+func scan(sec gopass.Secret, fn func(key string, ref Ref) (string, bool, error)) (gopass.Secret, error) {
+	if _, ok := sec.(*secrets.AKV); !ok {
+		return sec, nil
+	}
+
+	lines := strings.SplitAfter(string(sec.Bytes()), "\n")
+
+	var changed bool
+	for i, line := range lines {
+		body := strings.TrimSuffix(line, "\n")
+
+		var prefix, key string
+		value := body
+		if i > 0 {
+			k, v, found := strings.Cut(body, ": ")
+			if !found {
+				continue
+			}
+			prefix, key, value = k+": ", strings.TrimSpace(k), v
+		}
+
+		ref, ok := Parse(value)
+		if !ok {
+			continue
+		}
+
+		nv, replace, err := fn(key, ref)
+		if err != nil {
+			return nil, err
+		}
+		if !replace {
+			continue
+		}
+
+		lines[i] = prefix + nv + strings.TrimPrefix(line, body)
+		changed = true
+	}
+
+	if !changed {
+		return sec, nil
+	}
+
+	return secrets.ParseAKV([]byte(strings.Join(lines, ""))), nil
+}
+
+// mountOf returns the mount point of the given secret.
+func mountOf(g Getter, name string) string {
+	if mp, ok := g.(mountPointer); ok {
+		return mp.MountPoint(name)
+	}
+
+	return ""
+}
+
+// lookup returns the value a reference points to. It follows references
+// to references. stack holds the fields visited so far and mount the mount
+// point of the secret the first reference is in.
+func lookup(ctx context.Context, g Getter, ref Ref, stack []string, mount string) (string, error) {
+	id := ref.String()
+	if slices.Contains(stack, id) {
+		return "", fmt.Errorf("%w: %s", ErrCycle, strings.Join(append(stack, id), " -> "))
+	}
+	stack = append(stack, id)
+
+	if mp := mountOf(g, ref.Name); mp != mount {
+		return "", fmt.Errorf("%w: %s", ErrMount, id)
+	}
+
+	debug.V(1).Log("resolving reference %s", id)
+
+	sec, err := g.Get(ctx, ref.Name)
+	if err != nil {
+		return "", fmt.Errorf("%w %s: %w", ErrDangling, id, err)
+	}
+
+	v, found := field(sec, ref.Key)
+	if !found {
+		return "", fmt.Errorf("%w %s: no such field", ErrDangling, id)
+	}
+
+	if next, ok := Parse(v); ok {
+		if _, ok := sec.(*secrets.AKV); ok {
+			return lookup(ctx, g, next, stack, mount)
+		}
+	}
+
+	return v, nil
+}
+
+func field(sec gopass.Secret, key string) (string, bool) {
+	if key == "" {
+		return sec.Password(), true
+	}
+
+	if v, found := sec.Get(key); found {
+		return v, true
+	}
+
+	if key == PasswordKey {
+		return sec.Password(), true
+	}
+
+	return "", false
+}
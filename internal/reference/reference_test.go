@@ -0,0 +1,182 @@
+package reference
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapStore map[string]string
+
+func (m mapStore) Get(_ context.Context, name string) (gopass.Secret, error) {
+	content, found := m[name]
+	if !found {
+		return nil, store.ErrNotFound
+	}
+
+	return secrets.ParseAKV([]byte(content)), nil
+}
+
+// mountedStore has a mount at "team".
+type mountedStore struct {
+	mapStore
+}
+
+func (m mountedStore) MountPoint(name string) string {
+	if strings.HasPrefix(name+"/", "team/") {
+		return "team"
+	}
+
+	return ""
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: "!ref db/prod", want: "db/prod", ok: true},
+		{in: " !ref /db/prod#user ", want: "db/prod#user", ok: true},
+		{in: "ref+gopass://ci/token#value", want: "ci/token#value", ok: true},
+		{in: "!ref ", ok: false},
+		{in: "!reference", ok: false},
+		{in: "s3cr3t", ok: false},
+	} {
+		r, ok := Parse(tc.in)
+		assert.Equal(t, tc.ok, ok, tc.in)
+		if tc.ok {
+			assert.Equal(t, tc.want, r.String(), tc.in)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ms := mapStore{
+		"db/prod":  "dbpass\nuser: admin\n",
+		"ci/token": "\nvalue: t0ken\n",
+		"svc/a":    "!ref db/prod\nuser: !ref db/prod#user\ntoken: ref+gopass://ci/token#value\nurl: https://example.com\n",
+		"svc/b":    "!ref svc/a\n",
+		"svc/bad":  "x\nuser: !ref db/staging\n",
+		"loop/a":   "!ref loop/b\n",
+		"loop/b":   "!ref loop/a\n",
+		"self":     "!ref self#alt\nalt: !ref self#password\npassword: pw\n",
+	}
+
+	sec, err := ms.Get(ctx, "svc/a")
+	require.NoError(t, err)
+	assert.Len(t, Refs(sec), 3)
+
+	res, err := Resolve(ctx, ms, "svc/a", sec)
+	require.NoError(t, err)
+	assert.Equal(t, "dbpass\nuser: admin\ntoken: t0ken\nurl: https://example.com\n", string(res.Bytes()))
+	// the original secret is not modified.
+	assert.Equal(t, ms["svc/a"], string(sec.Bytes()))
+
+	t.Run("chained", func(t *testing.T) {
+		t.Parallel()
+
+		sec, err := ms.Get(ctx, "svc/b")
+		require.NoError(t, err)
+		res, err := Resolve(ctx, ms, "svc/b", sec)
+		require.NoError(t, err)
+		assert.Equal(t, "dbpass", res.Password())
+	})
+
+	t.Run("same secret", func(t *testing.T) {
+		t.Parallel()
+
+		sec, err := ms.Get(ctx, "self")
+		require.NoError(t, err)
+		res, err := Resolve(ctx, ms, "self", sec)
+		require.NoError(t, err)
+		assert.Equal(t, "pw", res.Password())
+	})
+
+	t.Run("dangling", func(t *testing.T) {
+		t.Parallel()
+
+		sec, err := ms.Get(ctx, "svc/bad")
+		require.NoError(t, err)
+		_, err = Resolve(ctx, ms, "svc/bad", sec)
+		require.ErrorIs(t, err, ErrDangling)
+		assert.Len(t, Check(ctx, ms, "svc/bad", sec), 1)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		t.Parallel()
+
+		sec, err := ms.Get(ctx, "loop/a")
+		require.NoError(t, err)
+		_, err = Resolve(ctx, ms, "loop/a", sec)
+		require.ErrorIs(t, err, ErrCycle)
+		assert.Contains(t, err.Error(), "loop/a -> loop/b -> loop/a")
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		t.Parallel()
+
+		y, err := secrets.ParseYAML([]byte("pw\n---\nuser: \"!ref db/prod\"\n"))
+		require.NoError(t, err)
+		res, err := Resolve(ctx, ms, "y", y)
+		require.NoError(t, err)
+		assert.Equal(t, y, res)
+	})
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+
+	sec := secrets.ParseAKV([]byte("!ref db/prod\nuser: !ref db/prod#user\ntoken: ref+gopass://ci/token#value\n"))
+
+	res, changed := Rename(sec, func(name string) (string, bool) {
+		if name != "db/prod" {
+			return "", false
+		}
+
+		return "db/live", true
+	})
+	assert.True(t, changed)
+	assert.Equal(t, "!ref db/live\nuser: !ref db/live#user\ntoken: ref+gopass://ci/token#value\n", string(res.Bytes()))
+
+	_, changed = Rename(sec, func(string) (string, bool) { return "", false })
+	assert.False(t, changed)
+}
+
+func TestResolveMounts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ms := mountedStore{mapStore{
+		"bank":       "s3cr3t\n",
+		"team/db":    "dbpass\n",
+		"team/svc":   "!ref team/db\n",
+		"team/steal": "x\nbank: !ref bank\n",
+		"chain":      "!ref team/svc\n",
+	}}
+
+	sec, err := ms.Get(ctx, "team/svc")
+	require.NoError(t, err)
+	res, err := Resolve(ctx, ms, "team/svc", sec)
+	require.NoError(t, err)
+	assert.Equal(t, "dbpass", res.Password())
+
+	for _, name := range []string{"team/steal", "chain"} {
+		sec, err := ms.Get(ctx, name)
+		require.NoError(t, err)
+		_, err = Resolve(ctx, ms, name, sec)
+		require.ErrorIs(t, err, ErrMount, name)
+		assert.Len(t, Check(ctx, ms, name, sec), 1, name)
+	}
+}
@@ -0,0 +1,139 @@
+// Package sensitive decides which fields of a secret must not be printed
+// in clear text and masks them.
+package sensitive
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// Mask replaces the value of a sensitive field.
+const Mask = "*****"
+
+// MarkerKey is the key of a secret that lists additional sensitive keys of
+// this secret only, e.g. `unsafe-keys: pin, cvv`.
+const MarkerKey = "unsafe-keys"
+
+type contextKey int
+
+const ctxKeyMasking contextKey = iota
+
+// WithMasking returns a context with masking of sensitive fields enabled.
+func WithMasking(ctx context.Context, mask bool) context.Context {
+	return context.WithValue(ctx, ctxKeyMasking, mask)
+}
+
+// IsMasking returns true if sensitive fields should be masked.
+func IsMasking(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyMasking).(bool)
+	if !ok {
+		return false
+	}
+
+	return bv
+}
+
+// Patterns returns the sensitive keys configured in `show.sensitive-keys`
+// for the mount in the context.
+func Patterns(ctx context.Context) []string {
+	return split(config.String(ctx, "show.sensitive-keys"))
+}
+
+func split(list string) []string {
+	var out []string
+	for _, p := range strings.Split(list, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		out = append(out, p)
+	}
+
+	return out
+}
+
+// IsSensitive returns true if the key matches one of the configured
+// patterns or is listed in the marker key of the secret. Keys are
+// compared case insensitive.
+func IsSensitive(ctx context.Context, key string, sec gopass.Secret) bool {
+	key = strings.ToLower(key)
+
+	for _, p := range Patterns(ctx) {
+		if ok, err := path.Match(p, key); err == nil && ok {
+			return true
+		}
+	}
+
+	if sec == nil {
+		return false
+	}
+
+	uks, found := sec.Get(MarkerKey)
+	if !found {
+		return false
+	}
+
+	for _, uk := range split(uks) {
+		if uk == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasSensitive returns true if any key of the secret is sensitive.
+func HasSensitive(ctx context.Context, sec gopass.Secret) bool {
+	for _, k := range sec.Keys() {
+		if IsSensitive(ctx, k, sec) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact returns the keys and the body of the secret with the values of all
+// sensitive keys masked. If password is true a `password` key is masked,
+// too. The first line of the secret is not included.
+func Redact(ctx context.Context, sec gopass.Secret, password bool) string {
+	var sb strings.Builder
+	for i, k := range sec.Keys() {
+		sb.WriteString(k)
+		sb.WriteString(": ")
+		// check if this key should be obstructed.
+		if (password && strings.EqualFold(k, "password")) || IsSensitive(ctx, k, sec) {
+			debug.V(1).Log("obstructing sensitive key %s", k)
+			sb.WriteString(Mask)
+		} else {
+			v, found := sec.Values(k)
+			if !found {
+				continue
+			}
+			sb.WriteString(strings.Join(v, "\n"+k+": "))
+		}
+		// we only add a final new line if the body is non-empty.
+		if sec.Body() != "" || i < len(sec.Keys())-1 {
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString(sec.Body())
+
+	return sb.String()
+}
+
+// Content returns the whole secret with all sensitive values masked. It
+// returns the secret unchanged if it has no sensitive keys.
+func Content(ctx context.Context, sec gopass.Secret) string {
+	if !HasSensitive(ctx, sec) {
+		return string(sec.Bytes())
+	}
+
+	return sec.Password() + "\n" + Redact(ctx, sec, false)
+}
@@ -0,0 +1,51 @@
+package sensitive
+
+import (
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSensitive(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	cfg, _ := config.FromContext(ctx)
+	require.NoError(t, cfg.Set("", "show.sensitive-keys", "PIN, recovery-*"))
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("secret")
+	require.NoError(t, sec.Set("user", "john"))
+	require.NoError(t, sec.Set("pin", "1234"))
+	require.NoError(t, sec.Set("recovery-codes", "a b c"))
+	require.NoError(t, sec.Set("cvv", "999"))
+	require.NoError(t, sec.Set(MarkerKey, "CVV"))
+
+	for key, want := range map[string]bool{
+		"user":           false,
+		"pin":            true,
+		"Pin":            true,
+		"recovery-codes": true,
+		"cvv":            true,
+		"password":       false,
+	} {
+		assert.Equal(t, want, IsSensitive(ctx, key, sec), key)
+	}
+	assert.True(t, HasSensitive(ctx, sec))
+
+	content := Content(ctx, sec)
+	assert.Contains(t, content, "secret\n")
+	assert.Contains(t, content, "user: john")
+	assert.Contains(t, content, "pin: "+Mask)
+	assert.NotContains(t, content, "1234")
+	assert.NotContains(t, content, "999")
+
+	plain := secrets.NewAKV()
+	plain.SetPassword("secret")
+	require.NoError(t, plain.Set("user", "john"))
+	assert.False(t, HasSensitive(ctx, plain))
+	assert.Equal(t, string(plain.Bytes()), Content(ctx, plain))
+}
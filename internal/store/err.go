@@ -21,6 +21,10 @@ var (
 	ErrGitNoRemote = fmt.Errorf("git has no remote origin")
 	// ErrGitNothingToCommit is returned if there are no staged changes.
 	ErrGitNothingToCommit = fmt.Errorf("git has nothing to commit")
+	// ErrGitUnverifiedCommits is returned if commits from a remote are not signed by a trusted signer.
+	ErrGitUnverifiedCommits = fmt.Errorf("git commits failed signature verification")
+	// ErrGitConflict is returned if a pull stopped with merge conflicts.
+	ErrGitConflict = fmt.Errorf("git merge conflict")
 	// ErrEmptySecret is returned if a secret exists but has no content.
 	ErrEmptySecret = fmt.Errorf("empty secret. see https://go.gopass.pw/faq#empty-secret")
 	// ErrMeaninglessWrite is returned if a secret is overwritten with its current (ciphertext) content.
@@ -0,0 +1,96 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Conflict is a secret that was changed both locally and on the remote. The
+// content is decrypted. A version is nil if the secret did not exist in it.
+type Conflict struct {
+	Name   string
+	Base   []byte
+	Ours   []byte
+	Theirs []byte
+}
+
+type conflictLister interface {
+	Conflicts(ctx context.Context) ([]string, error)
+}
+
+// Conflicts returns all secrets with unresolved merge conflicts. Conflicts in
+// other files, e.g. the recipients, can not be resolved here.
+func (s *Store) Conflicts(ctx context.Context) ([]Conflict, error) {
+	cl, ok := s.storage.(conflictLister)
+	if !ok {
+		return nil, backend.ErrNotSupported
+	}
+
+	files, err := cl.Conflicts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicts: %w", err)
+	}
+
+	ext := "." + s.crypto.Ext()
+	conflicts := make([]Conflict, 0, len(files))
+	for _, fn := range files {
+		if !strings.HasSuffix(fn, ext) {
+			return nil, fmt.Errorf("conflict in %s must be resolved manually", fn)
+		}
+
+		c := Conflict{Name: strings.TrimSuffix(fn, ext)}
+		for i, dst := range []*[]byte{&c.Base, &c.Ours, &c.Theirs} {
+			ciphertext, err := s.storage.GetRevision(ctx, fn, fmt.Sprintf(":%d", i+1))
+			if err != nil {
+				debug.Log("no version %d of %s: %s", i+1, fn, err)
+
+				continue
+			}
+
+			content, err := s.crypto.Decrypt(ctx, ciphertext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt version %d of %s: %w", i+1, c.Name, err)
+			}
+			*dst = content
+		}
+		s.logAccess(ctx, c.Name, "resolve conflict")
+		conflicts = append(conflicts, c)
+	}
+
+	return conflicts, nil
+}
+
+// ResolveConflict writes the resolved content of a secret and stages it. The
+// content is encrypted for the current recipients. Nil content deletes the
+// secret.
+func (s *Store) ResolveConflict(ctx context.Context, name string, content []byte) error {
+	p := s.Passfile(name)
+
+	if content == nil {
+		if err := s.storage.Delete(ctx, p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+
+		return s.storage.Add(ctx, p)
+	}
+
+	ciphertext, err := s.encryptFor(ctx, name, content)
+	if err != nil {
+		return err
+	}
+
+	// the file still needs to be added to mark the conflict as resolved, even
+	// if it didn't change.
+	if err := s.storage.Set(ctx, p, ciphertext); err != nil && !errors.Is(err, store.ErrMeaninglessWrite) {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return s.storage.Add(ctx, p)
+}
@@ -16,6 +16,7 @@ const (
 	ctxKeyFsckDecrypt
 	ctxKeyNoGitOps
 	ctxKeyPubkeyUpdate
+	ctxKeyNoTagIndex
 )
 
 // WithFsckCheck returns a context with the flag for fscks check set.
@@ -133,6 +134,19 @@ func IsNoGitOps(ctx context.Context) bool {
 	return is(ctx, ctxKeyNoGitOps, false)
 }
 
+// WithNoTagIndex returns a context with the value for NoTagIndex set.
+// This will skip updating the tag index, e.g. when the tags of the secrets
+// don't change.
+func WithNoTagIndex(ctx context.Context, d bool) context.Context {
+	return context.WithValue(ctx, ctxKeyNoTagIndex, d)
+}
+
+// IsNoTagIndex returns the value for NoTagIndex from the context
+// or the default (false).
+func IsNoTagIndex(ctx context.Context) bool {
+	return is(ctx, ctxKeyNoTagIndex, false)
+}
+
 // IsPubkeyUpdate returns true if we should update all exported
 // recipients pub keys.
 func IsPubkeyUpdate(ctx context.Context) bool {
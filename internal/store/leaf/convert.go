@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/cui"
 	"github.com/gopasspw/gopass/internal/out"
@@ -101,13 +103,16 @@ func (s *Store) Convert(ctx context.Context, cryptoBe backend.CryptoBackend, sto
 				return fmt.Errorf("failed to list revision of %s: %w", e, err)
 			}
 		}
-		sort.Sort(sort.Reverse(backend.Revisions(revs)))
+		// oldest first. Backends list revisions newest first and some only
+		// record the day, so keep their order for revisions of the same day.
+		sort.Stable(backend.Revisions(revs))
+		slices.Reverse(revs)
 
 		// fail if the first revision fails, but if others fail only warn
 		first := true
 		for _, r := range revs {
 			debug.Log("converting %s@%s", e, r.Hash)
-			sec, err := s.GetRevision(ctx, e, r.Hash)
+			sec, err := s.GetRevision(accesslog.WithInternalRead(ctx, true), e, r.Hash)
 			if err != nil {
 				if first {
 					return fmt.Errorf("failed to convert revision %s of %s: %w", r.Hash, e, err)
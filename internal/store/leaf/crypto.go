@@ -47,7 +47,10 @@ func (s *Store) ImportMissingPublicKeys(ctx context.Context, newrs ...string) er
 		return fmt.Errorf("failed to get recipients: %w", err)
 	}
 
-	ids := append(rs.IDs(), newrs...)
+	ids, err := s.expandGroups(ctx, append(rs.IDs(), newrs...))
+	if err != nil {
+		return fmt.Errorf("failed to expand recipient groups: %w", err)
+	}
 	for _, r := range ids {
 		debug.Log("Checking recipients %s ...", r)
 		// check if this recipient is missing
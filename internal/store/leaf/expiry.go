@@ -0,0 +1,79 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/set"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// CheckRecipientKeys checks the keys of all recipients of this store,
+// including the ones of sub folders. It returns the recipients with invalid
+// keys, e.g. missing, expired or revoked ones, and the recipients whose keys
+// will expire within `recipients.expiry-warning` days.
+func (s *Store) CheckRecipientKeys(ctx context.Context) (map[string]error, map[string]time.Time) {
+	tree, err := s.RecipientsTree(ctx)
+	if err != nil {
+		return map[string]error{GroupsFile: err}, nil
+	}
+
+	ids := set.New[string]()
+	for _, rs := range tree {
+		ids.Add(rs...)
+	}
+
+	return s.checkKeys(ctx, ids.Elements())
+}
+
+// checkKeys checks the given recipient keys, see CheckRecipientKeys.
+func (s *Store) checkKeys(ctx context.Context, ids []string) (map[string]error, map[string]time.Time) {
+	cfg, _ := config.FromContext(ctx)
+	window := time.Duration(config.AsIntWithDefault(cfg.GetM(s.alias, "recipients.expiry-warning"), 30)) * 24 * time.Hour
+	now := time.Now()
+
+	invalid := make(map[string]error, len(ids))
+	expiring := make(map[string]time.Time, len(ids))
+	for _, k := range ids {
+		if kc, ok := s.crypto.(backend.KeyChecker); ok {
+			st, err := kc.KeyStatus(ctx, k)
+			switch {
+			case err != nil:
+				debug.Log("no key status for %s: %s", k, err)
+			case st.Revoked:
+				invalid[k] = fmt.Errorf("key revoked")
+
+				continue
+			case st.Expired(now):
+				invalid[k] = fmt.Errorf("key expired on %s", st.Expires.Format(time.DateOnly))
+
+				continue
+			case window > 0 && st.ExpiresWithin(now, window):
+				debug.Log("key %s expires on %s", k, st.Expires)
+				expiring[k] = st.Expires
+			}
+		}
+
+		validKeys, err := s.crypto.FindRecipients(ctx, k)
+		if err != nil {
+			debug.Log("no GPG key info (unexpected) for %s: %s", k, err)
+			invalid[k] = err
+
+			continue
+		}
+
+		if len(validKeys) < 1 {
+			debug.Log("no valid keys (expired?) for %s", k)
+			invalid[k] = fmt.Errorf("no valid keys (expired?)")
+
+			continue
+		}
+
+		debug.Log("valid keys found for %s", k)
+	}
+
+	return invalid, expiring
+}
@@ -0,0 +1,113 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statusCrypto struct {
+	*plain.Mocker
+	status map[string]backend.KeyStatus
+}
+
+func (c *statusCrypto) FindRecipients(ctx context.Context, keys ...string) ([]string, error) {
+	return keys, nil
+}
+
+func (c *statusCrypto) KeyStatus(ctx context.Context, id string) (backend.KeyStatus, error) {
+	return c.status[id], nil
+}
+
+func TestCheckRecipientKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	tempdir := t.TempDir()
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, plain.IDFile), []byte("0xDEADBEEF\n0xFEEDBEEF\n0xCAFEBABE\n0xBADC0FFEE\n"), 0o600))
+
+	now := time.Now()
+	crypto := &statusCrypto{
+		Mocker: plain.New(),
+		status: map[string]backend.KeyStatus{
+			"0xFEEDBEEF":  {Revoked: true},
+			"0xCAFEBABE":  {Expires: now.Add(-time.Hour)},
+			"0xBADC0FFEE": {Expires: now.Add(7 * 24 * time.Hour)},
+		},
+	}
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  crypto,
+		storage: fs.New(tempdir),
+	}
+
+	invalid, expiring := s.CheckRecipientKeys(ctx)
+	assert.Len(t, invalid, 2)
+	require.Error(t, invalid["0xFEEDBEEF"])
+	assert.Contains(t, invalid["0xFEEDBEEF"].Error(), "revoked")
+	require.Error(t, invalid["0xCAFEBABE"])
+	assert.Contains(t, invalid["0xCAFEBABE"].Error(), "expired")
+	assert.Len(t, expiring, 1)
+	assert.Contains(t, expiring, "0xBADC0FFEE")
+
+	require.Error(t, s.CheckRecipients(ctx))
+
+	// disable the warning window
+	cfg := config.NewInMemory()
+	require.NoError(t, cfg.Set("", "recipients.expiry-warning", "0"))
+	_, expiring = s.CheckRecipientKeys(cfg.WithConfig(ctx))
+	assert.Empty(t, expiring)
+}
+
+func TestCheckRecipientsFor(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	tempdir := t.TempDir()
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, plain.IDFile), []byte("0xDEADBEEF\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(tempdir, "team"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, "team", plain.IDFile), []byte("0xDEADBEEF\n0xCAFEBABE\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, "team", "foo."+plain.Ext), []byte("secret"), 0o600))
+
+	crypto := &statusCrypto{
+		Mocker: plain.New(),
+		status: map[string]backend.KeyStatus{
+			"0xCAFEBABE": {Expires: time.Now().Add(-time.Hour)},
+		},
+	}
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  crypto,
+		storage: fs.New(tempdir),
+	}
+
+	// the expired key only matters for secrets below team.
+	require.NoError(t, s.CheckRecipientsFor(ctx, "foo"))
+	require.NoError(t, s.CheckRecipientsFor(ctx, ""))
+	require.Error(t, s.CheckRecipientsFor(ctx, "team/foo"))
+
+	// the tree-wide check still finds it.
+	require.Error(t, s.CheckRecipients(ctx))
+}
@@ -0,0 +1,126 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// BaselineFile is the encrypted audit baseline, see internal/audit.
+	BaselineFile = ".gopass-audit-baseline"
+	// WorklistFile is the encrypted rotation worklist, see internal/audit.
+	WorklistFile = ".gopass-rotation-worklist"
+)
+
+// encryptedFiles are files in the root of a store that are encrypted for its
+// recipients but are no secrets. Like the tag index they are kept for both
+// crypto backends during a migration.
+var encryptedFiles = []string{BaselineFile, WorklistFile}
+
+// dualFile is the name of the copy of an encrypted file for the new crypto
+// backend of a migration. It must not end in the extension of the backend,
+// otherwise it would be listed as a secret.
+func (s *Store) dualFile(name string) string {
+	return name + "-" + s.crypto.Ext()
+}
+
+// ReadFile decrypts one of the encrypted files in the root of the store. It
+// returns fs.ErrNotExist if the file does not exist. During a migration the
+// copy for the new backend is used if the file can not be decrypted.
+func (s *Store) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	if !s.storage.Exists(ctx, name) {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+
+	ciphertext, err := s.storage.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	buf, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil && s.dual != nil && s.storage.Exists(ctx, s.dual.dualFile(name)) {
+		debug.Log("failed to decrypt %s with %s, trying %s: %s", name, s.crypto.Name(), s.dual.crypto.Name(), err)
+
+		return s.dual.readFile(ctx, s.dual.dualFile(name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+
+	return buf, nil
+}
+
+func (s *Store) readFile(ctx context.Context, p string) ([]byte, error) {
+	ciphertext, err := s.storage.Get(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+
+	buf, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", p, err)
+	}
+
+	return buf, nil
+}
+
+// WriteFile encrypts content for the recipients of the store root and writes
+// it to the named file. Nil content removes the file. Like secrets the file is
+// added to git but not committed.
+func (s *Store) WriteFile(ctx context.Context, name string, content []byte) error {
+	if err := s.writeFile(ctx, name, content); err != nil {
+		return err
+	}
+
+	if s.dual == nil {
+		return nil
+	}
+
+	return s.dual.writeFile(ctx, s.dual.dualFile(name), content)
+}
+
+func (s *Store) writeFile(ctx context.Context, p string, content []byte) error {
+	if content == nil {
+		if !s.storage.Exists(ctx, p) {
+			return nil
+		}
+		if err := s.storage.Delete(ctx, p); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+
+		return s.storage.TryAdd(ctx, p)
+	}
+
+	ciphertext, err := s.encryptFor(ctx, "", content)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Set(ctx, p, ciphertext); err != nil && !errors.Is(err, store.ErrMeaninglessWrite) {
+		return fmt.Errorf("failed to write %s: %w", p, err)
+	}
+
+	return s.storage.TryAdd(ctx, p)
+}
+
+// readEncryptedFiles decrypts all encrypted files that exist in this store.
+func (s *Store) readEncryptedFiles(ctx context.Context) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(encryptedFiles))
+	for _, name := range encryptedFiles {
+		buf, err := s.ReadFile(ctx, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[name] = buf
+	}
+
+	return files, nil
+}
@@ -7,12 +7,14 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/backend/crypto/age"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/diff"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/queue"
+	"github.com/gopasspw/gopass/internal/set"
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -203,6 +205,8 @@ func (s *Store) fsckLoop(ctx context.Context, path string) error {
 		out.Warning(ctx, warnings.String())
 	}
 
+	s.fsckPostQuantum(ctx, names)
+
 	if ctxutil.GetCommitMessageBody(ctx) == "" {
 		if warnings.Len() > 0 {
 			out.Errorf(ctx, "Nothing to commit: all secrets that were not up to date failed to be updated")
@@ -219,9 +223,79 @@ func (s *Store) fsckLoop(ctx context.Context, path string) error {
 	return nil
 }
 
+// fsckPostQuantum reports all secrets that are not protected by post-quantum
+// recipients only. It does nothing unless the crypto backend supports them
+// and the store has at least one post-quantum recipient.
+func (s *Store) fsckPostQuantum(ctx context.Context, names []string) {
+	pqc, ok := s.crypto.(backend.PostQuantumChecker)
+	if !ok {
+		return
+	}
+
+	tree, err := s.RecipientsTree(ctx)
+	if err != nil {
+		out.Errorf(ctx, "Failed to check for post-quantum recipients: %s", err)
+
+		return
+	}
+
+	classic := set.New[string]()
+	var pq bool
+	for _, rs := range tree {
+		for _, r := range rs {
+			if pqc.IsPostQuantumRecipient(r) {
+				pq = true
+
+				continue
+			}
+			classic.Add(r)
+		}
+	}
+	if !pq {
+		debug.Log("no post-quantum recipients in %q", s.alias)
+
+		return
+	}
+
+	unprotected := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimPrefix(name, s.alias+"/")
+
+		ciphertext, err := s.storage.Get(ctx, s.Passfile(name))
+		if err != nil {
+			debug.Log("failed to get raw secret %s: %s", name, err)
+
+			continue
+		}
+
+		if !pqc.IsPostQuantum(ctx, ciphertext) {
+			unprotected = append(unprotected, name)
+		}
+	}
+
+	if len(unprotected) < 1 {
+		out.OKf(ctx, "All secrets are protected by post-quantum recipients")
+
+		return
+	}
+
+	out.Warningf(ctx, "%d secrets are not protected by post-quantum recipients, yet:", len(unprotected))
+	for _, name := range unprotected {
+		out.Printf(ctx, "  - %s", name)
+	}
+
+	if classic.Len() > 0 {
+		out.Noticef(ctx, "These recipients do not use post-quantum keys: %s", strings.Join(classic.Elements(), ", "))
+	}
+	out.Notice(ctx, "Once all recipients and your own identities use post-quantum keys run 'gopass fsck --decrypt' to re-encrypt them")
+}
+
 func (s *Store) fsckUpdatePublicKeys(ctx context.Context) error {
 	ctx = WithPubkeyUpdate(ctx, true)
-	rs := s.Recipients(ctx)
+	rs, err := s.ExpandedRecipients(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to get recipients: %w", err)
+	}
 
 	// first import possibly new/updated keys to merge any changes
 	// that might come from others.
@@ -272,7 +346,7 @@ func (s *Store) fsckCheckEntry(ctx context.Context, name string) (string, error)
 
 	// we need to make sure Parsing is enabled in order to parse old Mime secrets
 	ctx = ctxutil.WithShowParsing(ctx, true)
-	sec, err := s.Get(ctx, name)
+	sec, err := s.Get(accesslog.WithAction(ctx, "fsck"), name)
 	if err != nil {
 		return "", errs.Append(errsFatal, fmt.Errorf("failed to decode secret %s: %w", name, err)).ErrorOrNil()
 	}
@@ -337,12 +411,12 @@ func (s *Store) fsckCheckRecipients(ctx context.Context, name string) *fsckMulti
 
 	itemRecps = fingerprints(ctx, s.crypto, itemRecps)
 
-	rs, err := s.GetRecipients(ctx, name)
+	ids, err := s.ExpandedRecipients(ctx, name)
 	if err != nil {
 		return e.Append(errsFatal, fmt.Errorf("failed to get recipients from store: %w", err))
 	}
 
-	perItemStoreRecps := fingerprints(ctx, s.crypto, rs.IDs())
+	perItemStoreRecps := fingerprints(ctx, s.crypto, ids)
 
 	// check itemRecps matches storeRecps
 	extra, missing := diff.List(perItemStoreRecps, itemRecps)
@@ -2,7 +2,10 @@ package leaf
 
 import (
 	"bytes"
+	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
@@ -86,3 +89,50 @@ func TestCompareStringSlices(t *testing.T) {
 		})
 	}
 }
+
+type pqCrypto struct {
+	*plain.Mocker
+}
+
+func (c *pqCrypto) IsPostQuantumRecipient(id string) bool {
+	return strings.HasPrefix(id, "pq-")
+}
+
+func (c *pqCrypto) IsPostQuantum(ctx context.Context, ciphertext []byte) bool {
+	return bytes.HasPrefix(ciphertext, []byte("pq:"))
+}
+
+func TestFsckPostQuantum(t *testing.T) {
+	ctx := config.NewContextInMemory()
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	out.Stderr = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	tempdir := t.TempDir()
+	_, _, err := createStore(tempdir, []string{"0xDEADBEEF"}, []string{"foo", "bar"})
+	require.NoError(t, err)
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  &pqCrypto{Mocker: plain.New()},
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.storage.Set(ctx, s.Passfile("foo"), []byte("pq:secret")))
+
+	// no post-quantum recipients, nothing to report
+	s.fsckPostQuantum(ctx, []string{"bar", "foo"})
+	assert.Empty(t, obuf.String())
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, plain.IDFile), []byte("0xDEADBEEF\npq-alice\n"), 0o600))
+	s.fsckPostQuantum(ctx, []string{"bar", "foo"})
+	assert.Contains(t, obuf.String(), "1 secrets are not protected")
+	assert.Contains(t, obuf.String(), "- bar")
+	assert.NotContains(t, obuf.String(), "- foo")
+	assert.Contains(t, obuf.String(), "do not use post-quantum keys: 0xDEADBEEF")
+}
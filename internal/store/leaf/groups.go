@@ -0,0 +1,312 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// GroupsFile defines the recipient groups of a store. Recipient files can
+// refer to these groups, e.g. @sre.
+const GroupsFile = ".gopass-groups"
+
+// GroupsSigFile holds a detached signature of the groups file.
+const GroupsSigFile = GroupsFile + ".sig"
+
+// ErrInvalidSignature indicates a missing or untrusted signature of the
+// groups file.
+var ErrInvalidSignature = fmt.Errorf("groups signature invalid")
+
+// GetGroups returns the recipient groups of this store. Unlike the
+// recipients file the groups are always checked, even without
+// `recipients.check`. A single line in the synced groups file changes who can
+// read the secrets of every folder that uses the group, so it must be signed
+// by a key that is listed directly in the recipients file at the root of the
+// store. Crypto backends that can not sign, e.g. age, pin the hash of the
+// groups in `recipients.groups-hash` instead.
+func (s *Store) GetGroups(ctx context.Context) (*recipients.Groups, error) {
+	if !s.storage.Exists(ctx, GroupsFile) {
+		return recipients.NewGroups(), nil
+	}
+
+	buf, err := s.storage.Get(ctx, GroupsFile)
+	if err != nil {
+		return recipients.NewGroups(), fmt.Errorf("failed to get groups from %q: %w", GroupsFile, err)
+	}
+
+	g, err := recipients.UnmarshalGroups(buf)
+	if err != nil {
+		return recipients.NewGroups(), fmt.Errorf("failed to parse %q: %w", GroupsFile, err)
+	}
+
+	if signer, ok := s.crypto.(backend.Signer); ok {
+		return g, s.verifyGroups(ctx, signer, buf)
+	}
+
+	cfg, _ := config.FromContext(ctx)
+	// we do NOT support local hash keys since they could be remotely changed
+	cfgHash := cfg.GetGlobal(s.ghKey())
+	if gHash := g.Hash(); gHash != cfgHash {
+		return g, fmt.Errorf("config hash %q= %q - Groups file %q = %q, review the groups and confirm them with 'gopass recipients ack': %w", s.ghKey(), cfgHash, GroupsFile, gHash, ErrInvalidHash)
+	}
+
+	return g, nil
+}
+
+// verifyGroups checks that the groups file is signed by one of the keys in
+// the root recipients file. Groups can not sign, so only keys that are listed
+// directly are accepted.
+func (s *Store) verifyGroups(ctx context.Context, signer backend.Signer, buf []byte) error {
+	if !s.storage.Exists(ctx, GroupsSigFile) {
+		return fmt.Errorf("groups file %q is not signed, review the groups and sign them with 'gopass recipients ack': %w", GroupsFile, ErrInvalidSignature)
+	}
+
+	sig, err := s.storage.Get(ctx, GroupsSigFile)
+	if err != nil {
+		return fmt.Errorf("failed to get signature from %q: %w", GroupsSigFile, err)
+	}
+
+	fp, err := signer.Verify(ctx, buf, sig)
+	if err != nil {
+		return fmt.Errorf("signature of groups file %q does not match, review the groups and sign them with 'gopass recipients ack': %s: %w", GroupsFile, err, ErrInvalidSignature)
+	}
+
+	rs, err := s.getRecipients(ctx, s.crypto.IDFile())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range rs.IDs() {
+		if recipients.IsGroup(id) {
+			continue
+		}
+		if s.crypto.Fingerprint(ctx, id) == fp {
+			debug.Log("groups file signed by %s (%s)", id, fp)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("groups file %q is signed by %s, who is not a recipient of the store, review the groups and sign them with 'gopass recipients ack': %w", GroupsFile, fp, ErrInvalidSignature)
+}
+
+// ExpandedRecipients returns the recipients for the given secret with all
+// groups replaced by their members.
+func (s *Store) ExpandedRecipients(ctx context.Context, name string) ([]string, error) {
+	rs, err := s.GetRecipients(ctx, name)
+	if err != nil {
+		return rs.IDs(), err
+	}
+
+	return s.expandGroups(ctx, rs.IDs())
+}
+
+// expandGroups replaces any group references with their members. The groups
+// file is only read if there is at least one reference.
+func (s *Store) expandGroups(ctx context.Context, ids []string) ([]string, error) {
+	var hasGroups bool
+	for _, id := range ids {
+		if recipients.IsGroup(id) {
+			hasGroups = true
+
+			break
+		}
+	}
+	if !hasGroups {
+		return ids, nil
+	}
+
+	g, err := s.GetGroups(ctx)
+	if err != nil {
+		return ids, err
+	}
+
+	return g.Expand(ids)
+}
+
+// AddGroupRecipient adds a recipient to a group and re-encrypts all secrets
+// that use this group.
+func (s *Store) AddGroupRecipient(ctx context.Context, group, id string) error {
+	g, err := s.GetGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	added, err := g.Add(group, id)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return fmt.Errorf("%s is already a member of %s%s", id, recipients.GroupPrefix, recipients.GroupName(group))
+	}
+
+	msg := fmt.Sprintf("Added Recipient %s to %s%s", id, recipients.GroupPrefix, recipients.GroupName(group))
+	if err := s.saveGroups(ctx, g, msg); err != nil {
+		return fmt.Errorf("failed to save groups: %w", err)
+	}
+
+	return s.reencryptGroup(ctxutil.WithCommitMessage(ctx, msg), group)
+}
+
+// RemoveGroupRecipient removes a recipient from a group and re-encrypts all
+// secrets that use this group.
+func (s *Store) RemoveGroupRecipient(ctx context.Context, group, id string) error {
+	g, err := s.GetGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !g.Remove(group, id) {
+		return fmt.Errorf("recipient not in group")
+	}
+
+	msg := fmt.Sprintf("Removed Recipient %s from %s%s", id, recipients.GroupPrefix, recipients.GroupName(group))
+	if err := s.saveGroups(ctx, g, msg); err != nil {
+		return fmt.Errorf("failed to save groups: %w", err)
+	}
+
+	return s.reencryptGroup(ctxutil.WithCommitMessage(ctx, msg), group)
+}
+
+// reencryptGroup re-encrypts all secrets whose recipients file refers to the
+// given group.
+func (s *Store) reencryptGroup(ctx context.Context, group string) error {
+	ref := recipients.GroupPrefix + recipients.GroupName(group)
+
+	affected := make(map[string]bool, 2)
+	for _, idf := range append(s.idFiles(ctx), s.crypto.IDFile()) {
+		rs, err := s.getRecipients(ctx, idf)
+		if err != nil {
+			debug.Log("failed to read recipients from %s: %s", idf, err)
+
+			continue
+		}
+		if rs.Has(ref) {
+			affected[idf] = true
+		}
+	}
+
+	if len(affected) < 1 {
+		out.Noticef(ctx, "No recipients file refers to %s. Nothing to re-encrypt.", ref)
+
+		return nil
+	}
+
+	entries, err := s.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list store: %w", err)
+	}
+
+	filtered := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := strings.TrimPrefix(strings.TrimPrefix(e, s.alias), Sep)
+		if affected[s.idFile(ctx, name)] {
+			filtered = append(filtered, e)
+		}
+	}
+	debug.Log("re-encrypting %d of %d secrets for %s", len(filtered), len(entries), ref)
+
+	out.Printf(ctx, "Reencrypting %d secrets using %s. This may take some time ...", len(filtered), ref)
+
+	return s.reencryptEntries(ctx, filtered)
+}
+
+// saveGroups writes the groups file and signs it. If the crypto backend can
+// not sign, the hash of the groups is pinned in the global config instead.
+func (s *Store) saveGroups(ctx context.Context, g *recipients.Groups, msg string) error {
+	buf := g.Marshal()
+	errSet := s.storage.Set(ctx, GroupsFile, buf)
+	if errSet != nil && !errors.Is(errSet, store.ErrMeaninglessWrite) {
+		return fmt.Errorf("failed to write groups file: %w", errSet)
+	}
+
+	files := []string{GroupsFile}
+	if signer, ok := s.crypto.(backend.Signer); ok {
+		// signatures are not deterministic, so only sign if needed.
+		if errSet == nil || s.verifyGroups(ctx, signer, buf) != nil {
+			if err := s.signGroups(ctx, signer, buf); err != nil {
+				return err
+			}
+			errSet = nil
+			files = append(files, GroupsSigFile)
+		}
+	} else {
+		// always save the groups hash to global config
+		cfg, _ := config.FromContext(ctx)
+		if err := cfg.Set("", s.ghKey(), g.Hash()); err != nil {
+			out.Errorf(ctx, "Failed to update %s: %s", s.ghKey(), err)
+		}
+	}
+
+	if errors.Is(errSet, store.ErrMeaninglessWrite) {
+		return nil
+	}
+
+	for _, fn := range files {
+		if err := s.storage.TryAdd(ctx, fn); err != nil {
+			return fmt.Errorf("failed to add file %q to git: %w", fn, err)
+		}
+	}
+
+	if err := s.storage.TryCommit(ctx, msg); err != nil {
+		return fmt.Errorf("failed to commit changes to git: %w", err)
+	}
+
+	return nil
+}
+
+// signGroups signs the groups file with the first of our keys that is listed
+// in the root recipients file.
+func (s *Store) signGroups(ctx context.Context, signer backend.Signer, buf []byte) error {
+	rs, err := s.getRecipients(ctx, s.crypto.IDFile())
+	if err != nil && !errors.Is(err, ErrInvalidHash) {
+		return fmt.Errorf("failed to get recipients: %w", err)
+	}
+
+	direct := make([]string, 0, len(rs.IDs()))
+	for _, id := range rs.IDs() {
+		if !recipients.IsGroup(id) {
+			direct = append(direct, id)
+		}
+	}
+
+	var ids []string
+	if len(direct) > 0 {
+		ids, err = s.crypto.FindIdentities(ctx, direct...)
+		if err != nil {
+			return fmt.Errorf("failed to find a signing key: %w", err)
+		}
+	}
+	if len(ids) < 1 {
+		return fmt.Errorf("none of your keys is listed in %q, can not sign the groups file %q", s.crypto.IDFile(), GroupsFile)
+	}
+
+	sig, err := signer.Sign(ctx, ids[0], buf)
+	if err != nil {
+		return fmt.Errorf("failed to sign groups file: %w", err)
+	}
+	debug.Log("signed groups file with %s", ids[0])
+
+	if err := s.storage.Set(ctx, GroupsSigFile, sig); err != nil && !errors.Is(err, store.ErrMeaninglessWrite) {
+		return fmt.Errorf("failed to write groups signature: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) ghKey() string {
+	if s.alias == "" {
+		return "recipients.groups-hash"
+	}
+
+	return fmt.Sprintf("recipients.%s.groups-hash", s.alias)
+}
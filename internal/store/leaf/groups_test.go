@@ -0,0 +1,192 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingCrypto struct {
+	*plain.Mocker
+	sync.Mutex
+	encrypted []string
+}
+
+func (r *recordingCrypto) Encrypt(ctx context.Context, content []byte, recipients []string) ([]byte, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.encrypted = append(r.encrypted, strings.Join(recipients, ","))
+
+	return r.Mocker.Encrypt(ctx, content, recipients)
+}
+
+func TestGroups(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = config.NewInMemory().WithConfig(ctx)
+
+	tempdir := t.TempDir()
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, "foo", plain.IDFile), []byte("@sre\n0xFEEDBEEF\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, GroupsFile), []byte("[sre]\n0xCAFEBABE\n"), 0o600))
+
+	crypto := &recordingCrypto{Mocker: plain.New()}
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  crypto,
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.SaveRecipients(ctx, true))
+
+	rs, err := s.ExpandedRecipients(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xCAFEBABE", "0xFEEDBEEF"}, rs)
+
+	// files that are not secrets use the same recipients.
+	rs, err = s.UseableRecipients(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Contains(t, rs, "0xCAFEBABE")
+	assert.NotContains(t, rs, "@sre")
+
+	rs, err = s.ExpandedRecipients(ctx, "baz/ing/a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xDEADBEEF", "0xFEEDBEEF"}, rs)
+
+	// only the secrets below foo use the group and are re-encrypted.
+	require.NoError(t, s.AddGroupRecipient(ctx, "sre", "0xA3683834"))
+	require.Len(t, crypto.encrypted, 1)
+	assert.Contains(t, crypto.encrypted[0], "0xA3683834,0xCAFEBABE,0xFEEDBEEF")
+
+	g, err := s.GetGroups(ctx)
+	require.NoError(t, err)
+	members, found := g.Get("sre")
+	require.True(t, found)
+	assert.Equal(t, []string{"0xA3683834", "0xCAFEBABE"}, members.IDs())
+
+	crypto.encrypted = nil
+	require.NoError(t, s.RemoveGroupRecipient(ctx, "@sre", "0xCAFEBABE"))
+	require.Len(t, crypto.encrypted, 1)
+	assert.NotContains(t, crypto.encrypted[0], "0xCAFEBABE")
+
+	require.Error(t, s.RemoveGroupRecipient(ctx, "sre", "0xCAFEBABE"))
+
+	// unknown groups can not be referenced.
+	require.Error(t, s.AddRecipient(ctx, "@ops"))
+
+	// nor listed.
+	require.NoError(t, os.MkdirAll(filepath.Join(tempdir, "ops"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, "ops", plain.IDFile), []byte("@ops\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, "ops", "db.txt"), []byte("secret\n"), 0o600))
+	_, err = s.RecipientsTree(ctx)
+	require.Error(t, err)
+}
+
+func TestGroupsSignature(t *testing.T) {
+	t.Parallel()
+
+	// the groups signature is checked even without recipients.check.
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = config.NewInMemory().WithConfig(ctx)
+
+	tempdir := t.TempDir()
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, GroupsFile), []byte("[sre]\n0xCAFEBABE\n"), 0o600))
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	// the groups file has not been signed yet.
+	_, err = s.GetGroups(ctx)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	require.Error(t, s.AddGroupRecipient(ctx, "sre", "0xA3683834"))
+
+	// recipients ack signs the groups as well.
+	require.NoError(t, s.SaveRecipients(ctx, true))
+	assert.FileExists(t, filepath.Join(tempdir, GroupsSigFile))
+	require.NoError(t, s.AddGroupRecipient(ctx, "sre", "0xA3683834"))
+	_, err = s.GetGroups(ctx)
+	require.NoError(t, err)
+
+	// changes by anyone else are rejected.
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, GroupsFile), []byte("[sre]\n0xA3683834\n0xCAFEBABE\n0xBADC0FFE\n"), 0o600))
+	_, err = s.GetGroups(ctx)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// even if they sign them, unless they are a recipient of the store.
+	sig, err := plain.New().Sign(ctx, "0xBADC0FFE", []byte("[sre]\n0xA3683834\n0xCAFEBABE\n0xBADC0FFE\n"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, GroupsSigFile), sig, 0o600))
+	_, err = s.GetGroups(ctx)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	sig, err = plain.New().Sign(ctx, "0xFEEDBEEF", []byte("[sre]\n0xA3683834\n0xCAFEBABE\n0xBADC0FFE\n"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, GroupsSigFile), sig, 0o600))
+	_, err = s.GetGroups(ctx)
+	require.NoError(t, err)
+}
+
+// unsignedCrypto hides the Signer implementation of the wrapped backend.
+type unsignedCrypto struct {
+	backend.Crypto
+}
+
+func TestGroupsHash(t *testing.T) {
+	t.Parallel()
+
+	// backends that can not sign pin the groups hash instead.
+	cfg := config.NewInMemory()
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = cfg.WithConfig(ctx)
+
+	tempdir := t.TempDir()
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, GroupsFile), []byte("[sre]\n0xCAFEBABE\n"), 0o600))
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  unsignedCrypto{Crypto: plain.New()},
+		storage: fs.New(tempdir),
+	}
+
+	// the groups file has not been acknowledged yet.
+	_, err = s.GetGroups(ctx)
+	require.ErrorIs(t, err, ErrInvalidHash)
+	require.Error(t, s.AddGroupRecipient(ctx, "sre", "0xA3683834"))
+
+	// recipients ack pins the groups as well.
+	require.NoError(t, s.SaveRecipients(ctx, true))
+	require.NoError(t, s.AddGroupRecipient(ctx, "sre", "0xA3683834"))
+	_, err = s.GetGroups(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, cfg.Get("recipients.groups-hash"))
+	assert.NoFileExists(t, filepath.Join(tempdir, GroupsSigFile))
+}
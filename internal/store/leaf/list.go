@@ -25,7 +25,7 @@ func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 	out := make([]string, 0, len(lst))
 	cExt := "." + s.crypto.Ext()
 	for _, path := range lst {
-		if !strings.HasSuffix(path, cExt) {
+		if !strings.HasSuffix(path, cExt) || path == TagIndex+cExt {
 			continue
 		}
 		path = strings.TrimSuffix(path, cExt)
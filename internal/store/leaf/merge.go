@@ -0,0 +1,75 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/merge"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Merge does a three-way merge of the encrypted base, ours and theirs versions
+// of the named secret. The result is encrypted for the current recipients of
+// the secret. An empty base is treated as an empty secret, i.e. the secret was
+// added on both sides. The returned bool is false if the merged content
+// contains conflict markers that need to be resolved manually.
+func (s *Store) Merge(ctx context.Context, name string, base, ours, theirs []byte) ([]byte, bool, error) {
+	plain := make([][]byte, 0, 3)
+	for i, ciphertext := range [][]byte{base, ours, theirs} {
+		if len(ciphertext) < 1 {
+			plain = append(plain, nil)
+
+			continue
+		}
+
+		content, err := s.crypto.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decrypt version %d of %s: %w", i, name, err)
+		}
+		plain = append(plain, content)
+	}
+
+	s.logAccess(ctx, name, "merge")
+
+	merged, clean := merge.Secrets(plain[0], plain[1], plain[2])
+	debug.Log("merged %s (clean: %t)", name, clean)
+
+	ciphertext, err := s.encryptFor(ctx, name, merged)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return ciphertext, clean, nil
+}
+
+// Decrypt decrypts a version of the named secret that was not read from the
+// store, e.g. one passed in by git.
+func (s *Store) Decrypt(ctx context.Context, name string, ciphertext []byte) ([]byte, error) {
+	content, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+	s.logAccess(ctx, name, "decrypt")
+
+	return content, nil
+}
+
+// encryptFor encrypts content for the current recipients of the named secret.
+func (s *Store) encryptFor(ctx context.Context, name string, content []byte) ([]byte, error) {
+	recipients, err := s.useableKeys(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list useable keys for %q: %w", name, err)
+	}
+	recipients = s.ensureOurKeyID(ctx, recipients)
+
+	if len(recipients) < 1 {
+		return nil, fmt.Errorf("no useable recipients for %q. can not encrypt without recipients.", name)
+	}
+
+	ciphertext, err := s.crypto.Encrypt(ctx, content, recipients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+
+	return ciphertext, nil
+}
@@ -0,0 +1,43 @@
+package leaf
+
+import (
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	ctx := config.NewContextInMemory()
+
+	s, err := createSubStore(t)
+	require.NoError(t, err)
+
+	enc := func(in string) []byte {
+		t.Helper()
+
+		buf, err := s.crypto.Encrypt(ctx, []byte(in), []string{"0xDEADBEEF"})
+		require.NoError(t, err)
+
+		return buf
+	}
+	dec := func(in []byte) string {
+		t.Helper()
+
+		buf, err := s.crypto.Decrypt(ctx, in)
+		require.NoError(t, err)
+
+		return string(buf)
+	}
+
+	out, clean, err := s.Merge(ctx, "foo", enc("secret\nuser: john\n"), enc("secret\nuser: jane\n"), enc("changed\nuser: john\n"))
+	require.NoError(t, err)
+	assert.True(t, clean)
+	assert.Equal(t, "changed\nuser: jane\n", dec(out))
+
+	out, clean, err = s.Merge(ctx, "foo", nil, enc("foo\n"), enc("bar\n"))
+	require.NoError(t, err)
+	assert.False(t, clean)
+	assert.Contains(t, dec(out), "<<<<<<< ours\nfoo\n=======\nbar\n>>>>>>> theirs\n")
+}
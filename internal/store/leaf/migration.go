@@ -0,0 +1,467 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/accesslog"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/cui"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/set"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"gopkg.in/yaml.v3"
+)
+
+// MigrationFile marks a store that is being migrated from one crypto backend
+// to another. While it exists every secret is encrypted with both backends,
+// e.g. as foo.gpg and foo.age, and each backend uses its own recipients file.
+// This allows team members to switch to the new backend one by one.
+const MigrationFile = ".gopass-migration"
+
+// migrationTargets are the crypto backends a store can be migrated to. The
+// migration file is synced with the store so it must never be able to make
+// gopass write weaker copies of the secrets, e.g. with the plain backend.
+var migrationTargets = []backend.CryptoBackend{backend.Age, backend.GPGCLI, backend.OpenPGP}
+
+type migration struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// MigrationStatus reports the progress of a migration between two crypto
+// backends.
+type MigrationStatus struct {
+	From string
+	To   string
+	// Secrets is the number of secrets in the store, Migrated the number of
+	// secrets that are also encrypted with the new backend.
+	Secrets  int
+	Migrated int
+	Missing  []string
+	// FromRecipients and ToRecipients are the recipients of the old and the
+	// new backend.
+	FromRecipients []string
+	ToRecipients   []string
+}
+
+// view returns a view of this store that uses the given crypto backend.
+// It shares the storage but has its own recipients and secret files.
+func (s *Store) view(crypto backend.Crypto) *Store {
+	return &Store{
+		alias:     s.alias,
+		path:      s.path,
+		crypto:    crypto,
+		storage:   s.storage,
+		secondary: true,
+		accessLog: s.accessLog,
+	}
+}
+
+// initMigration sets up the secondary crypto backend if the store is being
+// migrated.
+func (s *Store) initMigration(ctx context.Context) error {
+	if s.crypto == nil || !s.storage.Exists(ctx, MigrationFile) {
+		return nil
+	}
+
+	buf, err := s.storage.Get(ctx, MigrationFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", MigrationFile, err)
+	}
+
+	m := migration{}
+	if err := yaml.Unmarshal(buf, &m); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", MigrationFile, err)
+	}
+
+	to, err := migrationCrypto(ctx, m.To)
+	if err != nil {
+		out.Errorf(ctx, "Ignoring migration of store %q: %s", s.alias, err)
+
+		return nil
+	}
+
+	// the new backend may have a higher priority than the old one. The old
+	// backend always stays the primary one until the migration is finished.
+	from := s.crypto
+	if to.IDFile() == s.crypto.IDFile() {
+		from, err = migrationCrypto(ctx, m.From)
+		if err != nil {
+			out.Errorf(ctx, "Ignoring migration of store %q: %s", s.alias, err)
+
+			return nil
+		}
+	}
+
+	// the migration file can be changed by anyone who can push to the
+	// store. Only the local config can opt in to encrypt every secret for
+	// another backend.
+	cfg, _ := config.FromContext(ctx)
+	if pin := cfg.GetGlobal(s.migrationKey()); pin != m.To {
+		// the answer is remembered, so we don't ask on every command.
+		if cfg.GetGlobal(s.migrationDeclinedKey()) == m.To {
+			debug.Log("not joining the migration of store %q to %s", s.alias, m.To)
+
+			return nil
+		}
+		if !termio.AskForConfirmation(ctx, fmt.Sprintf("Store %q is being migrated from %s to %s. Do you want to encrypt all secrets for %s, too?", s.alias, from.Name(), to.Name(), to.Name())) {
+			out.Warningf(ctx, "Not joining the migration of store %q to %s. Secrets will only be encrypted for %s. Run 'gopass convert --dual' to join later.", s.alias, to.Name(), s.crypto.Name())
+			// without a terminal nobody was asked.
+			if !ctxutil.IsInteractive(ctx) {
+				return nil
+			}
+			if err := cfg.Set("", s.migrationDeclinedKey(), m.To); err != nil {
+				out.Errorf(ctx, "Failed to update %s: %s", s.migrationDeclinedKey(), err)
+			}
+
+			return nil
+		}
+		if err := cfg.Set("", s.migrationKey(), m.To); err != nil {
+			return fmt.Errorf("failed to update %s: %w", s.migrationKey(), err)
+		}
+	}
+
+	debug.Log("store %q is migrating from %s to %s", s.alias, from.Name(), to.Name())
+	s.crypto = from
+	s.dual = s.view(to)
+
+	return nil
+}
+
+// migrationKey is the config key that pins the crypto backend this store is
+// being migrated to. Like the recipients hash it is only read from and set
+// at the global level.
+func (s *Store) migrationKey() string {
+	if s.alias == "" {
+		return "migration.to"
+	}
+
+	return fmt.Sprintf("migration.%s.to", s.alias)
+}
+
+// migrationDeclinedKey is the config key that records the crypto backend of
+// a migration the user did not want to join.
+func (s *Store) migrationDeclinedKey() string {
+	if s.alias == "" {
+		return "migration.declined"
+	}
+
+	return fmt.Sprintf("migration.%s.declined", s.alias)
+}
+
+// migrationCrypto initializes the named crypto backend if it is one of the
+// migration targets.
+func migrationCrypto(ctx context.Context, name string) (backend.Crypto, error) {
+	be, err := backend.CryptoRegistry.Backend(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown crypto backend %q: %w", name, err)
+	}
+	if !slices.Contains(migrationTargets, be) {
+		return nil, fmt.Errorf("can not migrate with crypto backend %s", name)
+	}
+
+	return backend.NewCrypto(ctx, be)
+}
+
+// passfiles returns the files of the given secret, i.e. one per crypto
+// backend.
+func (s *Store) passfiles(name string) []string {
+	if s.dual == nil {
+		return []string{s.Passfile(name)}
+	}
+
+	return []string{s.Passfile(name), s.dual.Passfile(name)}
+}
+
+// Migrating returns the name of the crypto backend this store is being
+// migrated to or an empty string.
+func (s *Store) Migrating() string {
+	if s.dual == nil {
+		return ""
+	}
+
+	return s.dual.crypto.Name()
+}
+
+// StartMigration starts a staged migration of this store to the given
+// crypto backend. All secrets are encrypted with both backends until
+// FinishMigration is called. It can be run again to encrypt any secrets
+// that were added without the new backend.
+func (s *Store) StartMigration(ctx context.Context, cryptoBe backend.CryptoBackend) error {
+	if !slices.Contains(migrationTargets, cryptoBe) {
+		return fmt.Errorf("can not migrate to crypto backend %s", cryptoBe)
+	}
+
+	crypto, err := backend.NewCrypto(ctx, cryptoBe)
+	if err != nil {
+		return fmt.Errorf("failed to initialize crypto backend %s: %w", cryptoBe, err)
+	}
+
+	var key string
+	if s.dual == nil && !s.view(crypto).IsInitialized(ctx) {
+		key, err = cui.AskForPrivateKey(ctx, crypto, "Please select a private key")
+		if err != nil {
+			return fmt.Errorf("failed to ask for the private key for %v: %w", crypto, err)
+		}
+	}
+
+	return s.startMigration(ctx, crypto, key)
+}
+
+func (s *Store) startMigration(ctx context.Context, crypto backend.Crypto, ids ...string) error {
+	if s.dual != nil && s.dual.crypto.Name() != crypto.Name() {
+		return fmt.Errorf("store is already being migrated to %s", s.dual.crypto.Name())
+	}
+	if crypto.IDFile() == s.crypto.IDFile() {
+		return fmt.Errorf("%s and %s share the same keys, no migration needed", s.crypto.Name(), crypto.Name())
+	}
+
+	dual := s.view(crypto)
+	ctx = ctxutil.WithGitCommit(ctx, false)
+
+	if !dual.IsInitialized(ctx) {
+		if err := dual.Init(ctx, s.path, ids...); err != nil {
+			return fmt.Errorf("failed to initialize %s recipients: %w", crypto.Name(), err)
+		}
+	}
+
+	buf, err := yaml.Marshal(migration{From: s.crypto.Name(), To: crypto.Name()})
+	if err != nil {
+		return err
+	}
+	if err := s.storage.Set(ctx, MigrationFile, buf); err != nil && !errors.Is(err, store.ErrMeaninglessWrite) {
+		return fmt.Errorf("failed to write %s: %w", MigrationFile, err)
+	}
+	if err := s.storage.TryAdd(ctx, MigrationFile); err != nil {
+		return fmt.Errorf("failed to add %s to git: %w", MigrationFile, err)
+	}
+
+	cfg, _ := config.FromContext(ctx)
+	if err := cfg.Set("", s.migrationKey(), crypto.Name()); err != nil {
+		return fmt.Errorf("failed to update %s: %w", s.migrationKey(), err)
+	}
+	if cfg.GetGlobal(s.migrationDeclinedKey()) != "" {
+		if err := cfg.Unset("", s.migrationDeclinedKey()); err != nil {
+			out.Errorf(ctx, "Failed to remove %s: %s", s.migrationDeclinedKey(), err)
+		}
+	}
+
+	files, err := s.readEncryptedFiles(ctx)
+	if err != nil {
+		return err
+	}
+	for name, buf := range files {
+		if err := dual.writeFile(ctx, dual.dualFile(name), buf); err != nil {
+			return fmt.Errorf("failed to encrypt %s for %s: %w", name, crypto.Name(), err)
+		}
+	}
+
+	s.dual = dual
+
+	names, err := s.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list store: %w", err)
+	}
+
+	out.Printf(ctx, "Encrypting %d secrets for %s ...", len(names), crypto.Name())
+	var n int
+	for _, name := range names {
+		name = strings.TrimPrefix(name, s.alias+Sep)
+		if dual.Exists(ctx, name) {
+			continue
+		}
+
+		sec, err := s.Get(accesslog.WithInternalRead(ctx, true), name)
+		if err != nil {
+			out.Errorf(ctx, "Failed to decrypt %s: %s", name, err)
+
+			continue
+		}
+
+		p, err := dual.write(ctx, name, sec.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s for %s: %w", name, crypto.Name(), err)
+		}
+		if err := s.storage.TryAdd(ctx, p); err != nil {
+			return fmt.Errorf("failed to add %q to git: %w", p, err)
+		}
+		n++
+	}
+	debug.Log("encrypted %d secrets for %s", n, crypto.Name())
+
+	if err := s.storage.TryCommit(ctx, fmt.Sprintf("Started migration from %s to %s", s.crypto.Name(), crypto.Name())); err != nil {
+		return fmt.Errorf("failed to commit changes to git: %w", err)
+	}
+
+	return s.reencryptGitPush(ctx)
+}
+
+// MigrationStatus returns the progress of the migration of this store.
+func (s *Store) MigrationStatus(ctx context.Context) (*MigrationStatus, error) {
+	if s.dual == nil {
+		return nil, fmt.Errorf("store is not being migrated")
+	}
+
+	names, err := s.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store: %w", err)
+	}
+
+	from, err := s.recipientsOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.dual.recipientsOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ms := &MigrationStatus{
+		From:           s.crypto.Name(),
+		To:             s.dual.crypto.Name(),
+		Secrets:        len(names),
+		FromRecipients: from,
+		ToRecipients:   to,
+	}
+
+	for _, name := range names {
+		name = strings.TrimPrefix(name, s.alias+Sep)
+		if s.dual.Exists(ctx, name) {
+			ms.Migrated++
+
+			continue
+		}
+		ms.Missing = append(ms.Missing, name)
+	}
+
+	return ms, nil
+}
+
+// recipientsOf returns all recipients of the store, incl. sub folders.
+func (s *Store) recipientsOf(ctx context.Context) ([]string, error) {
+	tree, err := s.RecipientsTree(ctxutil.WithHidden(ctx, true))
+	if err != nil {
+		return nil, err
+	}
+
+	rs := set.New[string]()
+	for _, ids := range tree {
+		rs.Add(ids...)
+	}
+
+	return rs.Elements(), nil
+}
+
+// FinishMigration removes all files of the old crypto backend. Afterwards
+// the store only uses the new backend.
+func (s *Store) FinishMigration(ctx context.Context) error {
+	ms, err := s.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(ms.Missing) > 0 {
+		return fmt.Errorf("%d secrets are not encrypted for %s, yet: %s", len(ms.Missing), ms.To, strings.Join(ms.Missing, ", "))
+	}
+
+	// the audit baseline and the rotation worklist are re-encrypted for the
+	// new backend below.
+	encFiles, err := s.readEncryptedFiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	files, err := s.storage.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list store: %w", err)
+	}
+
+	ext := "." + s.crypto.Ext()
+	rm := make([]string, 0, len(files))
+	for _, f := range files {
+		if strings.HasSuffix(f, ext) || path.Base(f) == s.crypto.IDFile() {
+			rm = append(rm, f)
+		}
+	}
+	for name := range encFiles {
+		if s.storage.Exists(ctx, s.dual.dualFile(name)) {
+			rm = append(rm, s.dual.dualFile(name))
+		}
+	}
+	rm = append(rm, MigrationFile)
+
+	for _, f := range rm {
+		debug.Log("removing %s", f)
+		if err := s.storage.Delete(ctx, f); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", f, err)
+		}
+		if err := s.storage.TryAdd(ctx, f); err != nil {
+			return fmt.Errorf("failed to add %q to git: %w", f, err)
+		}
+	}
+
+	cfg, _ := config.FromContext(ctx)
+	hash := cfg.GetGlobal(s.dual.rhKey())
+
+	s.crypto = s.dual.crypto
+	s.dual = nil
+
+	for _, name := range set.SortedKeys(encFiles) {
+		if err := s.WriteFile(ctx, name, encFiles[name]); err != nil {
+			return fmt.Errorf("failed to encrypt %s for %s: %w", name, ms.To, err)
+		}
+	}
+
+	if err := s.storage.TryCommit(ctx, fmt.Sprintf("Finished migration from %s to %s", ms.From, ms.To)); err != nil {
+		return fmt.Errorf("failed to commit changes to git: %w", err)
+	}
+
+	if err := cfg.Unset("", s.migrationKey()); err != nil {
+		out.Errorf(ctx, "Failed to remove %s: %s", s.migrationKey(), err)
+	}
+
+	// the recipients of the new backend are now the only ones, carry over
+	// their hash.
+	if hash != "" {
+		if err := cfg.Set("", s.rhKey(), hash); err != nil {
+			out.Errorf(ctx, "Failed to update %s: %s", s.rhKey(), err)
+		}
+	}
+
+	return s.reencryptGitPush(ctx)
+}
+
+// dualRecipient returns the secondary view of this store if the given
+// recipient belongs to the new crypto backend.
+func (s *Store) dualRecipient(ctx context.Context, id string) *Store {
+	if s.dual == nil {
+		return nil
+	}
+
+	if rs, err := s.GetRecipients(ctx, ""); err == nil && rs.Has(id) {
+		return nil
+	}
+
+	if rs, err := s.dual.GetRecipients(ctx, ""); err == nil && rs.Has(id) {
+		return s.dual
+	}
+
+	if kl, err := s.crypto.FindRecipients(ctx, id); err == nil && len(kl) > 0 {
+		return nil
+	}
+
+	if kl, err := s.dual.crypto.FindRecipients(ctx, id); err == nil && len(kl) > 0 {
+		return s.dual
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// otherCrypto is a second crypto backend with its own files and keys, like
+// age next to gpg.
+type otherCrypto struct {
+	*plain.Mocker
+}
+
+func (o *otherCrypto) Name() string   { return "other" }
+func (o *otherCrypto) Ext() string    { return "oth" }
+func (o *otherCrypto) IDFile() string { return ".other-id" }
+
+func (o *otherCrypto) FindRecipients(_ context.Context, keys ...string) ([]string, error) {
+	res := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if strings.HasPrefix(k, "other1") {
+			res = append(res, k)
+		}
+	}
+
+	return res, nil
+}
+
+func (o *otherCrypto) FindIdentities(ctx context.Context, keys ...string) ([]string, error) {
+	return o.FindRecipients(ctx, keys...)
+}
+
+// lockedOut can not decrypt anything, like a team member that only has
+// a key for the new backend.
+type lockedOut struct {
+	*plain.Mocker
+}
+
+func (l *lockedOut) Decrypt(context.Context, []byte) ([]byte, error) {
+	return nil, fmt.Errorf("no secret key")
+}
+
+func TestMigration(t *testing.T) {
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	tempdir := t.TempDir()
+	_, _, err := createStore(tempdir, nil, []string{})
+	require.NoError(t, err)
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("secret")
+	for _, name := range []string{"foo", "bar/baz"} {
+		require.NoError(t, s.Set(ctx, name, sec))
+	}
+
+	require.NoError(t, s.WriteFile(ctx, BaselineFile, []byte("baseline")))
+
+	// same keys, nothing to migrate
+	require.Error(t, s.startMigration(ctx, plain.New()))
+	assert.Empty(t, s.Migrating())
+
+	require.NoError(t, s.startMigration(ctx, &otherCrypto{Mocker: plain.New()}, "other1alice"))
+	assert.Equal(t, "other", s.Migrating())
+	assert.True(t, s.storage.Exists(ctx, MigrationFile))
+	assert.True(t, s.storage.Exists(ctx, ".other-id"))
+	assert.True(t, s.storage.Exists(ctx, BaselineFile+"-oth"))
+
+	ms, err := s.MigrationStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "plain", ms.From)
+	assert.Equal(t, "other", ms.To)
+	assert.Equal(t, 2, ms.Secrets)
+	assert.Equal(t, 2, ms.Migrated)
+	assert.Empty(t, ms.Missing)
+	assert.Equal(t, []string{"other1alice"}, ms.ToRecipients)
+
+	t.Run("writes both", func(t *testing.T) {
+		require.NoError(t, s.Set(ctx, "new", sec))
+		assert.True(t, s.storage.Exists(ctx, "new.txt"))
+		assert.True(t, s.storage.Exists(ctx, "new.oth"))
+
+		require.NoError(t, s.Move(ctx, "new", "moved"))
+		assert.False(t, s.storage.Exists(ctx, "new.oth"))
+		assert.True(t, s.storage.Exists(ctx, "moved.txt"))
+		assert.True(t, s.storage.Exists(ctx, "moved.oth"))
+
+		require.NoError(t, s.Delete(ctx, "moved"))
+		assert.False(t, s.storage.Exists(ctx, "moved.txt"))
+		assert.False(t, s.storage.Exists(ctx, "moved.oth"))
+	})
+
+	t.Run("recipients", func(t *testing.T) {
+		require.NoError(t, s.AddRecipient(ctx, "other1bob"))
+
+		rs, err := s.dual.GetRecipients(ctx, "")
+		require.NoError(t, err)
+		assert.True(t, rs.Has("other1bob"))
+
+		rs, err = s.GetRecipients(ctx, "")
+		require.NoError(t, err)
+		assert.False(t, rs.Has("other1bob"))
+
+		require.NoError(t, s.RemoveRecipient(ctx, "other1bob"))
+		rs, err = s.dual.GetRecipients(ctx, "")
+		require.NoError(t, err)
+		assert.False(t, rs.Has("other1bob"))
+	})
+
+	t.Run("read fallback", func(t *testing.T) {
+		crypto := s.crypto
+		s.crypto = &lockedOut{Mocker: plain.New()}
+		defer func() { s.crypto = crypto }()
+
+		got, err := s.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", got.Password())
+
+		buf, err := s.ReadFile(ctx, BaselineFile)
+		require.NoError(t, err)
+		assert.Equal(t, "baseline", string(buf))
+	})
+
+	t.Run("encrypted files", func(t *testing.T) {
+		require.NoError(t, s.WriteFile(ctx, WorklistFile, []byte("worklist")))
+		assert.True(t, s.storage.Exists(ctx, WorklistFile))
+		assert.True(t, s.storage.Exists(ctx, WorklistFile+"-oth"))
+	})
+
+	// a secret that was added without the new backend blocks finishing
+	require.NoError(t, s.storage.Delete(ctx, "bar/baz.oth"))
+	err = s.FinishMigration(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bar/baz")
+
+	// resume
+	require.NoError(t, s.startMigration(ctx, &otherCrypto{Mocker: plain.New()}))
+	assert.True(t, s.storage.Exists(ctx, "bar/baz.oth"))
+
+	require.NoError(t, s.FinishMigration(ctx))
+	assert.Empty(t, s.Migrating())
+	assert.Equal(t, "other", s.crypto.Name())
+	assert.False(t, s.storage.Exists(ctx, MigrationFile))
+
+	// the baseline and the worklist are re-encrypted for the new backend.
+	for name, want := range map[string]string{BaselineFile: "baseline", WorklistFile: "worklist"} {
+		assert.False(t, s.storage.Exists(ctx, name+"-oth"), name)
+		buf, err := s.ReadFile(ctx, name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, string(buf), name)
+	}
+	assert.False(t, s.storage.Exists(ctx, plain.IDFile))
+	assert.False(t, s.storage.Exists(ctx, "foo.txt"))
+
+	list, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar/baz", "foo"}, list)
+}
+
+func TestInitMigration(t *testing.T) {
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	tempdir := t.TempDir()
+	_, _, err := createStore(tempdir, nil, []string{})
+	require.NoError(t, err)
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	t.Run("plain is refused", func(t *testing.T) {
+		require.NoError(t, s.storage.Set(ctx, MigrationFile, []byte("from: gpgcli\nto: plain\n")))
+		require.NoError(t, s.initMigration(ctx))
+		assert.Empty(t, s.Migrating())
+	})
+
+	t.Run("not pinned", func(t *testing.T) {
+		require.NoError(t, s.storage.Set(ctx, MigrationFile, []byte("from: plain\nto: age\n")))
+		require.NoError(t, s.initMigration(ctx))
+		assert.Empty(t, s.Migrating())
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		cfg, _ := config.FromContext(ctx)
+		require.NoError(t, cfg.Set("", "migration.to", "age"))
+		require.NoError(t, s.initMigration(ctx))
+		assert.Equal(t, "age", s.Migrating())
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		cfg, _ := config.FromContext(ctx)
+		require.NoError(t, cfg.Unset("", "migration.to"))
+		s.dual = nil
+
+		require.NoError(t, s.initMigration(ctxutil.WithAlwaysYes(ctx, true)))
+		assert.Equal(t, "age", s.Migrating())
+		assert.Equal(t, "age", cfg.GetGlobal("migration.to"))
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		cfg, _ := config.FromContext(ctx)
+		require.NoError(t, cfg.Unset("", "migration.to"))
+		s.dual = nil
+
+		termio.Stdin = strings.NewReader("n\n")
+		defer func() {
+			termio.Stdin = os.Stdin
+		}()
+
+		require.NoError(t, s.initMigration(ctxutil.WithInteractive(ctx, true)))
+		assert.Empty(t, s.Migrating())
+		assert.Equal(t, "age", cfg.GetGlobal("migration.declined"))
+
+		// not asked again, confirming would join the migration.
+		require.NoError(t, s.initMigration(ctxutil.WithAlwaysYes(ctx, true)))
+		assert.Empty(t, s.Migrating())
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/queue"
@@ -34,7 +35,7 @@ func (s *Store) Copy(ctx context.Context, from, to string) error {
 
 	debug.Log("direct copy failed: %v", err)
 
-	content, err := s.Get(ctx, from)
+	content, err := s.Get(accesslog.WithInternalRead(ctx, true), from)
 	if err != nil {
 		return fmt.Errorf("failed to get %q from store: %w", from, err)
 	}
@@ -70,7 +71,7 @@ func (s *Store) Move(ctx context.Context, from, to string) error {
 	debug.Log("direct move failed: %v", err)
 
 	// fall back to copy and delete
-	content, err := s.Get(ctx, from)
+	content, err := s.Get(accesslog.WithInternalRead(ctx, true), from)
 	if err != nil {
 		return fmt.Errorf("failed to decrypt %q: %w", from, err)
 	}
@@ -100,6 +101,19 @@ func (s *Store) directMove(ctx context.Context, from, to string, del bool) error
 		return fmt.Errorf("failed to move %q to %q: %w", from, to, err)
 	}
 
+	paths := []string{pFrom, pTo}
+	if s.dual != nil && s.dual.Exists(ctx, from) {
+		dFrom, dTo := s.dual.Passfile(from), s.dual.Passfile(to)
+		if err := s.storage.Move(ctx, dFrom, dTo, del); err != nil {
+			return fmt.Errorf("failed to move %q to %q: %w", dFrom, dTo, err)
+		}
+		paths = append(paths, dFrom, dTo)
+	}
+
+	if err := s.copyTags(ctx, from, to, del); err != nil {
+		return fmt.Errorf("failed to update tag index: %w", err)
+	}
+
 	// It is not possible to perform concurrent git add and git commit commands
 	// so we need to skip this step when using concurrency and perform them
 	// at the end of the batch processing.
@@ -109,7 +123,7 @@ func (s *Store) directMove(ctx context.Context, from, to string, del bool) error
 		return nil
 	}
 
-	if err := s.storage.TryAdd(ctx, pFrom, pTo); err != nil {
+	if err := s.storage.TryAdd(ctx, paths...); err != nil {
 		return fmt.Errorf("failed to add %q and %q to git: %w", pFrom, pTo, err)
 	}
 
@@ -155,6 +169,16 @@ func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
 			return err
 		}
 	}
+	if s.dual != nil {
+		// the secret may not have been migrated, yet.
+		if err := s.deleteSingle(ctx, s.dual.Passfile(name)); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+
+	if err := s.unindexTags(ctx, name, recurse); err != nil {
+		return fmt.Errorf("failed to update tag index: %w", err)
+	}
 
 	if !ctxutil.IsGitCommit(ctx) {
 		return nil
@@ -164,6 +188,7 @@ func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
 		return fmt.Errorf("failed to commit changes to git: %w", err)
 	}
 
+	ctx = config.WithMount(ctx, s.alias)
 	if !config.Bool(ctx, "core.autopush") {
 		debug.Log("not pushing to git remote, core.autopush is false")
 
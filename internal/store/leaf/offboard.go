@@ -0,0 +1,177 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// ExposedSecrets returns all secrets that could be decrypted by the given
+// recipient, either now or in any earlier revision. The recipient can be
+// given by all its known identifiers, e.g. the key ID and the fingerprint.
+// Without revision history only the current recipients are considered.
+func (s *Store) ExposedSecrets(ctx context.Context, ids ...string) ([]string, error) {
+	entries, err := s.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store: %w", err)
+	}
+
+	tree, err := s.RecipientsTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exposed := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := strings.TrimPrefix(strings.TrimPrefix(e, s.alias), Sep)
+
+		if matchesRecipient(recipientsFor(tree, name), ids) {
+			debug.Log("%s is currently encrypted for %v", name, ids)
+			exposed = append(exposed, name)
+
+			continue
+		}
+
+		if s.exposedInHistory(ctx, name, ids) {
+			exposed = append(exposed, name)
+		}
+	}
+
+	return exposed, nil
+}
+
+// exposedInHistory checks if any earlier revision of the secret was encrypted
+// for the recipient. If the crypto backend can not tell the recipients of a
+// ciphertext we look at the recipients file of that revision instead.
+func (s *Store) exposedInHistory(ctx context.Context, name string, ids []string) bool {
+	revs, err := s.ListRevisions(ctx, name)
+	if err != nil {
+		debug.Log("failed to list revisions of %s: %s", name, err)
+
+		return false
+	}
+
+	p := s.Passfile(name)
+	for _, rev := range revs {
+		buf, err := s.storage.GetRevision(ctx, p, rev.Hash)
+		if err != nil {
+			debug.Log("failed to get %s@%s: %s", name, rev.Hash, err)
+
+			continue
+		}
+
+		rids, err := s.crypto.RecipientIDs(ctx, buf)
+		if err != nil {
+			debug.Log("failed to read recipients of %s@%s: %s", name, rev.Hash, err)
+			rids = s.recipientsAt(ctx, name, rev.Hash)
+		}
+
+		if matchesRecipient(rids, ids) {
+			debug.Log("%s@%s was encrypted for %v", name, rev.Hash, ids)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// recipientsAt returns the recipients of the secret according to the closest
+// recipients file at the given revision, with all groups expanded.
+func (s *Store) recipientsAt(ctx context.Context, name, revision string) []string {
+	var buf []byte
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		idf := s.crypto.IDFile()
+		if dir != "." && dir != "/" {
+			idf = path.Join(dir, idf)
+		}
+
+		b, err := s.storage.GetRevision(ctx, idf, revision)
+		if err == nil {
+			buf = b
+
+			break
+		}
+
+		if idf == s.crypto.IDFile() {
+			debug.Log("no recipients file for %s@%s: %s", name, revision, err)
+
+			return nil
+		}
+	}
+
+	ids := recipients.Unmarshal(buf).IDs()
+
+	gbuf, err := s.storage.GetRevision(ctx, GroupsFile, revision)
+	if err != nil {
+		return ids
+	}
+
+	g, err := recipients.UnmarshalGroups(gbuf)
+	if err != nil {
+		debug.Log("failed to parse %s@%s: %s", GroupsFile, revision, err)
+
+		return ids
+	}
+
+	if exp, err := g.Expand(ids); err == nil {
+		return exp
+	}
+
+	return ids
+}
+
+// recipientsFor returns the recipients of the closest folder in the tree
+// returned by RecipientsTree.
+func recipientsFor(tree map[string][]string, name string) []string {
+	for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if rs, found := tree[dir]; found {
+			return rs
+		}
+	}
+
+	return tree[""]
+}
+
+// matchesRecipient returns true if any of the recipients refers to the same
+// key as any of the given ids. Hex key IDs match if one is a suffix of the
+// other, e.g. a long key ID and a fingerprint.
+func matchesRecipient(rs, ids []string) bool {
+	for _, r := range rs {
+		for _, id := range ids {
+			if sameKey(r, id) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func sameKey(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	a = strings.ToUpper(strings.TrimPrefix(a, "0x"))
+	b = strings.ToUpper(strings.TrimPrefix(b, "0x"))
+	if len(a) < 16 || len(b) < 16 || !isHex(a) || !isHex(b) {
+		return false
+	}
+
+	return strings.HasSuffix(a, b) || strings.HasSuffix(b, a)
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", c) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,97 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noRecipientIDs can not tell the recipients of a ciphertext, like age.
+type noRecipientIDs struct {
+	*plain.Mocker
+}
+
+func (n *noRecipientIDs) RecipientIDs(context.Context, []byte) ([]string, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func TestExposedSecrets(t *testing.T) {
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithUsername(ctx, "foo")
+	ctx = ctxutil.WithEmail(ctx, "foo@baz.com")
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	t.Setenv("GIT_AUTHOR_NAME", "foo")
+	t.Setenv("GIT_AUTHOR_EMAIL", "foo@baz.com")
+	t.Setenv("GIT_COMMITTER_NAME", "foo")
+	t.Setenv("GIT_COMMITTER_EMAIL", "foo@baz.com")
+
+	tempdir := t.TempDir()
+
+	_, _, err := createStore(tempdir, nil, []string{})
+	require.NoError(t, err)
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  &noRecipientIDs{Mocker: plain.New()},
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.GitInit(ctx))
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("secret")
+	for _, name := range []string{"foo/bar", "baz/a", "baz/b"} {
+		require.NoError(t, s.Set(ctx, name, sec))
+	}
+
+	// restrict baz to 0xFEEDBEEF. baz/a and baz/b were exposed to
+	// 0xDEADBEEF before, baz/c never was.
+	require.NoError(t, s.storage.Set(ctx, "baz/"+plain.IDFile, []byte("0xFEEDBEEF\n")))
+	require.NoError(t, s.storage.TryAdd(ctx, "baz/"+plain.IDFile))
+	require.NoError(t, s.storage.TryCommit(ctx, "Restrict baz"))
+	sec.SetPassword("rotated")
+	require.NoError(t, s.Set(ctx, "baz/b", sec))
+	require.NoError(t, s.Set(ctx, "baz/c", sec))
+
+	exposed, err := s.ExposedSecrets(ctx, "0xDEADBEEF")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo/bar", "baz/a", "baz/b"}, exposed)
+
+	exposed, err = s.ExposedSecrets(ctx, "0xFEEDBEEF")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo/bar", "baz/a", "baz/b", "baz/c"}, exposed)
+
+	exposed, err = s.ExposedSecrets(ctx, "0xCAFEBABE")
+	require.NoError(t, err)
+	assert.Empty(t, exposed)
+}
+
+func TestSameKey(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"0xDEADBEEF", "0xDEADBEEF", true},
+		{"age1foo", "age1foo", true},
+		{"age1foo", "age1bar", false},
+		{"0x1234567890ABCDEF", "AAAABBBBCCCCDDDDEEEEFFFF1234567890ABCDEE", false},
+		{"0x1234567890ABCDEF", "AAAABBBBCCCCDDDDEEEEFFFF1234567890abcdef", true},
+		// short IDs are too ambiguous
+		{"0xDEADBEEF", "AAAABBBBCCCCDDDDEEEEFFFF00000000DEADBEEF", false},
+	} {
+		assert.Equal(t, tc.want, sameKey(tc.a, tc.b), "%s - %s", tc.a, tc.b)
+	}
+}
@@ -3,6 +3,8 @@ package leaf
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/out"
@@ -44,6 +46,7 @@ func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.
 
 		return nil, store.ErrDecrypt
 	}
+	s.logAccess(ctx, name, "decrypt revision "+revision)
 
 	sec, err := secparse.Parse(content)
 	if err != nil {
@@ -53,6 +56,35 @@ func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.
 	return sec, nil
 }
 
+// ListDeleted lists all secrets that were deleted in the history of this
+// store and do not exist anymore, newest first.
+func (s *Store) ListDeleted(ctx context.Context) ([]backend.Deletion, error) {
+	dels, err := s.storage.Deletions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := "." + s.crypto.Ext()
+	res := make([]backend.Deletion, 0, len(dels))
+	for _, d := range dels {
+		name, found := strings.CutSuffix(filepath.ToSlash(d.Name), ext)
+		if !found || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if s.Exists(ctx, name) {
+			debug.Log("%s was deleted in %s but exists again", name, d.Hash)
+
+			continue
+		}
+
+		d.Name = name
+		res = append(res, d)
+	}
+
+	return res, nil
+}
+
 // GitStatus shows the git status output.
 func (s *Store) GitStatus(ctx context.Context, _ string) error {
 	debug.Log("RCS status for %s", s.path)
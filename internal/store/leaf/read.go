@@ -24,11 +24,18 @@ func (s *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
 	}
 
 	content, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil && s.dual != nil && s.dual.Exists(ctx, name) {
+		// during a migration users may only have a key for the new backend.
+		debug.Log("failed to decrypt %s with %s, trying %s: %s", p, s.crypto.Name(), s.dual.crypto.Name(), err)
+
+		return s.dual.Get(ctx, name)
+	}
 	if err != nil {
 		out.Errorf(ctx, "Decryption failed: %s\n%s", err, string(content))
 
 		return nil, store.ErrDecrypt
 	}
+	s.logAccess(ctx, name, "decrypt")
 
 	if !ctxutil.IsShowParsing(ctx) {
 		debug.Log("secrets parsing is disabled. parsing as AKV")
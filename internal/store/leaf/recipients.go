@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/gopasspw/gopass/internal/config"
@@ -63,11 +64,25 @@ func (s *Store) Recipients(ctx context.Context) []string {
 	return rs.IDs()
 }
 
+// UseableRecipients returns the recipients a file at the given location is
+// encrypted for. Groups are expanded and our own key is always included, the
+// same as for secrets.
+func (s *Store) UseableRecipients(ctx context.Context, name string) ([]string, error) {
+	rs, err := s.useableKeys(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ensureOurKeyID(ctx, rs), nil
+}
+
 // RecipientsTree returns a mapping of secrets to recipients.
 // Note: Usually that is one set of recipients per store, but we
 // offer limited support of different recipients per sub-directory
-// so this is why we are here.
-func (s *Store) RecipientsTree(ctx context.Context) map[string][]string {
+// so this is why we are here. Recipient groups are expanded. It fails
+// if any group can not be expanded, otherwise the members of that group
+// would be missing.
+func (s *Store) RecipientsTree(ctx context.Context) (map[string][]string, error) {
 	idfs := s.idFiles(ctx)
 	out := make(map[string][]string, len(idfs))
 
@@ -87,45 +102,65 @@ func (s *Store) RecipientsTree(ctx context.Context) map[string][]string {
 
 			continue
 		}
+		ids, err := s.expandGroups(ctx, srs.IDs())
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand recipient groups of %s: %w", idf, err)
+		}
 		dir := filepath.Dir(idf)
-		debug.Log("adding recipients %+v for %s", srs, dir)
-		out[dir] = srs.IDs()
+		debug.Log("adding recipients %+v for %s", ids, dir)
+		out[dir] = ids
 	}
 
-	out[""] = root
+	ids, err := s.expandGroups(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand recipient groups: %w", err)
+	}
+	out[""] = ids
 
-	return out
+	return out, nil
 }
 
-// CheckRecipients makes sure all existing recipients are valid.
+// CheckRecipients makes sure all existing recipients of the store, including
+// the ones of sub folders, are valid. It warns about keys that will expire
+// soon.
 func (s *Store) CheckRecipients(ctx context.Context) error {
 	rs, err := s.GetRecipients(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to read recipient list: %w", err)
 	}
 
-	er := InvalidRecipientsError{
-		Invalid: make(map[string]error, len(rs.IDs())),
+	if _, err := s.expandGroups(ctx, rs.IDs()); err != nil {
+		return fmt.Errorf("failed to expand recipient groups: %w", err)
 	}
-	for _, k := range rs.IDs() {
-		validKeys, err := s.crypto.FindRecipients(ctx, k)
-		if err != nil {
-			debug.Log("no GPG key info (unexpected) for %s: %s", k, err)
-			er.Invalid[k] = err
 
-			continue
-		}
+	invalid, expiring := s.CheckRecipientKeys(ctx)
 
-		if len(validKeys) < 1 {
-			debug.Log("no valid keys (expired?) for %s", k)
-			er.Invalid[k] = fmt.Errorf("no valid keys (expired?)")
+	return reportKeys(ctx, invalid, expiring)
+}
 
-			continue
-		}
+// CheckRecipientsFor makes sure the recipients that a secret or folder name
+// is encrypted for are valid. Unlike CheckRecipients it ignores the
+// recipients of other folders.
+func (s *Store) CheckRecipientsFor(ctx context.Context, name string) error {
+	ids, err := s.ExpandedRecipients(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to read recipient list: %w", err)
+	}
+
+	invalid, expiring := s.checkKeys(ctx, ids)
+
+	return reportKeys(ctx, invalid, expiring)
+}
 
-		debug.Log("valid keys found for %s", k)
+// reportKeys warns about expiring keys and returns an error for invalid ones.
+func reportKeys(ctx context.Context, invalid map[string]error, expiring map[string]time.Time) error {
+	for _, k := range set.SortedKeys(expiring) {
+		out.Warningf(ctx, "The key of recipient %s expires on %s", k, expiring[k].Format(time.DateOnly))
 	}
 
+	er := InvalidRecipientsError{
+		Invalid: invalid,
+	}
 	if er.IsError() {
 		return er
 	}
@@ -135,6 +170,10 @@ func (s *Store) CheckRecipients(ctx context.Context) error {
 
 // AddRecipient adds a new recipient to the list.
 func (s *Store) AddRecipient(ctx context.Context, id string) error {
+	if d := s.dualRecipient(ctx, id); d != nil {
+		return d.AddRecipient(ctx, id)
+	}
+
 	rs, err := s.GetRecipients(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to read recipient list: %w", err)
@@ -142,6 +181,12 @@ func (s *Store) AddRecipient(ctx context.Context, id string) error {
 
 	debug.Log("new recipient: %q - existing: %+v", id, rs)
 
+	if recipients.IsGroup(id) {
+		if _, err := s.expandGroups(ctx, []string{id}); err != nil {
+			return err
+		}
+	}
+
 	idAlreadyInStore := rs.Has(id)
 	if idAlreadyInStore {
 		if !termio.AskForConfirmation(ctx, fmt.Sprintf("key %q already in store. Do you want to re-encrypt with public key? This is useful if you changed your public key (e.g. added subkeys).", id)) {
@@ -177,6 +222,16 @@ func (s *Store) SaveRecipients(ctx context.Context, ack bool) error {
 		}
 	}
 
+	if s.storage.Exists(ctx, GroupsFile) {
+		g, err := s.GetGroups(ctx)
+		if err != nil && ((!errors.Is(err, ErrInvalidHash) && !errors.Is(err, ErrInvalidSignature)) || !ack) {
+			return fmt.Errorf("failed to get groups: %w", err)
+		}
+		if err := s.saveGroups(ctx, g, "Save Groups"); err != nil {
+			return err
+		}
+	}
+
 	return s.saveRecipients(ctx, rs, "Save Recipients")
 }
 
@@ -189,6 +244,10 @@ func (s *Store) SetRecipients(ctx context.Context, rs *recipients.Recipients) er
 // but if this key is not available on this machine we
 // just try to remove it literally.
 func (s *Store) RemoveRecipient(ctx context.Context, key string) error {
+	if d := s.dualRecipient(ctx, key); d != nil {
+		return d.RemoveRecipient(ctx, key)
+	}
+
 	rs, err := s.GetRecipients(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to read recipient list: %w", err)
@@ -208,6 +267,11 @@ RECIPIENTS:
 			continue RECIPIENTS
 		}
 
+		// groups are only removed by name
+		if recipients.IsGroup(k) {
+			continue RECIPIENTS
+		}
+
 		// If we don't match immediately, we may need to loop through the recipient keys to try and match.
 		// To do this though, we need to ensure that we also do a FindRecipients on the id name from the stored ids.
 		recipientIds, err := s.crypto.FindRecipients(ctx, k)
@@ -247,7 +311,15 @@ RECIPIENTS:
 		return fmt.Errorf("failed to save recipients: %w", err)
 	}
 
-	return s.reencrypt(ctxutil.WithCommitMessage(ctx, "Removed Recipient "+key))
+	if err := s.reencrypt(ctxutil.WithCommitMessage(ctx, "Removed Recipient "+key)); err != nil {
+		return err
+	}
+
+	if _, fast := s.rewrapper(ctx); fast {
+		out.Warningf(ctx, "The secrets were only rewrapped. %s can still decrypt the old revisions in the history and the current secrets if they kept their file keys. Please rotate all affected secrets.", key)
+	}
+
+	return nil
 }
 
 func (s *Store) ensureOurKeyID(ctx context.Context, rs []string) []string {
@@ -326,6 +398,11 @@ func (s *Store) UpdateExportedPublicKeys(ctx context.Context, rs []string) (bool
 		return false, nil
 	}
 
+	rs, err := s.expandGroups(ctx, rs)
+	if err != nil {
+		return false, fmt.Errorf("failed to expand recipient groups: %w", err)
+	}
+
 	recipients := make(map[string]bool, len(rs))
 	for _, r := range rs {
 		recipients[r] = true
@@ -494,7 +571,7 @@ func (s *Store) saveRecipients(ctx context.Context, rs recipientMarshaler, msg s
 	}
 
 	// push to remote repo
-	if err := s.storage.Push(ctx, "", ""); err != nil {
+	if err := s.storage.Push(config.WithMount(ctx, s.alias), "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -514,9 +591,15 @@ func (s *Store) saveRecipients(ctx context.Context, rs recipientMarshaler, msg s
 }
 
 func (s *Store) rhKey() string {
+	key := "hash"
+	if s.secondary {
+		// the recipients of the new backend during a migration.
+		key = s.crypto.Name() + "-hash"
+	}
+
 	if s.alias == "" {
-		return "recipients.hash"
+		return "recipients." + key
 	}
 
-	return fmt.Sprintf("recipients.%s.hash", s.alias)
+	return fmt.Sprintf("recipients.%s.%s", s.alias, key)
 }
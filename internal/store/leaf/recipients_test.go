@@ -3,6 +3,7 @@ package leaf
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -223,6 +224,54 @@ func TestRemoveRecipient(t *testing.T) {
 	assert.Equal(t, []string{"0xFEEDBEEF"}, rs.IDs())
 }
 
+type rewrapCrypto struct {
+	*plain.Mocker
+	rewrapped []string
+}
+
+func (r *rewrapCrypto) Rewrap(ctx context.Context, ciphertext []byte, recipients []string) ([]byte, error) {
+	r.rewrapped = append(r.rewrapped, strings.Join(recipients, ","))
+
+	return append(ciphertext, '\n'), nil
+}
+
+func TestRemoveRecipientRewrap(t *testing.T) {
+	cfg := config.NewInMemory()
+	require.NoError(t, cfg.Set("", "age.rewrap", "true"))
+	ctx := config.NewContextInMemory()
+	ctx = cfg.WithConfig(ctx)
+
+	tempdir := t.TempDir()
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	out.Stderr = obuf
+
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	crypto := &rewrapCrypto{Mocker: plain.New()}
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  crypto,
+		storage: fs.New(tempdir),
+	}
+
+	require.NoError(t, s.RemoveRecipient(ctx, "0xDEADBEEF"))
+
+	assert.Len(t, crypto.rewrapped, 2)
+	for _, r := range crypto.rewrapped {
+		assert.NotContains(t, r, "0xDEADBEEF")
+	}
+	assert.Contains(t, obuf.String(), "rotate all affected secrets")
+}
+
 func TestListRecipients(t *testing.T) {
 	t.Parallel()
 
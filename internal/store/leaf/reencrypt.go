@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
@@ -25,6 +26,11 @@ func (s *Store) reencrypt(ctx context.Context) error {
 		return fmt.Errorf("failed to list store: %w", err)
 	}
 
+	return s.reencryptEntries(ctx, entries)
+}
+
+// reencryptEntries re-encrypts the given entries for their current recipients.
+func (s *Store) reencryptEntries(ctx context.Context, entries []string) error {
 	// Most gnupg setups don't work well with concurrency > 1, but
 	// for other backends - e.g. age - this could very well be > 1.
 	conc := s.crypto.Concurrency()
@@ -33,6 +39,8 @@ func (s *Store) reencrypt(ctx context.Context) error {
 	{
 		// shadow ctx in this block only
 		ctx := ctxutil.WithGitCommit(ctx, false)
+		// the tag index does not change. It is re-encrypted once below.
+		ctx = WithNoTagIndex(ctx, true)
 
 		// progress bar
 		bar := termio.NewProgressBar(int64(len(entries)))
@@ -42,14 +50,27 @@ func (s *Store) reencrypt(ctx context.Context) error {
 		jobs := make(chan string)
 		// We use a logger to write without race condition on stdout
 		logger := log.New(os.Stdout, "", 0)
-		out.Print(ctx, "Starting reencrypt")
+		rw, fast := s.rewrapper(ctx)
+		if fast {
+			out.Print(ctx, "Starting rewrap")
+		} else {
+			out.Print(ctx, "Starting reencrypt")
+		}
 
 		for i := range conc {
 			wg.Add(1) // we start a new job
 			go func(workerId int) {
 				// the workers are fed through an unbuffered channel
 				for e := range jobs {
-					content, err := s.Get(ctx, e)
+					if fast {
+						if err := s.rewrap(WithNoGitOps(ctx, conc > 1), rw, e); err != nil && !errors.Is(err, store.ErrMeaninglessWrite) {
+							logger.Printf("Worker %d: Failed to rewrap %s: %s\n", workerId, e, err)
+						}
+
+						continue
+					}
+
+					content, err := s.Get(accesslog.WithInternalRead(ctx, true), e)
 					if err != nil {
 						logger.Printf("Worker %d: Failed to get current value for %s: %s\n", workerId, e, err)
 
@@ -99,15 +120,20 @@ func (s *Store) reencrypt(ctx context.Context) error {
 	// to avoid a race condition on git .index.lock file, so we do it now.
 	if conc > 1 {
 		for _, name := range entries {
-			p := s.Passfile(name)
-			if err := s.storage.TryAdd(ctx, p); err != nil {
-				return fmt.Errorf("failed to add %q to git: %w", p, err)
-			}
+			for _, p := range s.passfiles(name) {
+				if err := s.storage.TryAdd(ctx, p); err != nil {
+					return fmt.Errorf("failed to add %q to git: %w", p, err)
+				}
 
-			debug.Log("added %s to git", p)
+				debug.Log("added %s to git", p)
+			}
 		}
 	}
 
+	if err := s.reencryptTags(ctx); err != nil {
+		return err
+	}
+
 	if err := s.storage.TryCommit(ctx, ctxutil.GetCommitMessage(ctx)); err != nil {
 		return fmt.Errorf("failed to commit changes to git: %w", err)
 	}
@@ -129,3 +155,57 @@ func (s *Store) reencryptGitPush(ctx context.Context) error {
 
 	return nil
 }
+
+// rewrapper is implemented by crypto backends that can change the recipients
+// of a secret without decrypting its content, e.g. age.
+type rewrapper interface {
+	Rewrap(ctx context.Context, ciphertext []byte, recipients []string) ([]byte, error)
+}
+
+// rewrapper returns the crypto backend as a rewrapper if it supports it and
+// age.rewrap is enabled for this store.
+func (s *Store) rewrapper(ctx context.Context) (rewrapper, bool) {
+	rw, ok := s.crypto.(rewrapper)
+	if !ok {
+		return nil, false
+	}
+
+	ctx = config.WithMount(ctx, s.alias)
+
+	return rw, config.Bool(ctx, "age.rewrap")
+}
+
+// rewrap only replaces the recipients of a single secret. The payload and
+// its key stay the same.
+func (s *Store) rewrap(ctx context.Context, rw rewrapper, name string) error {
+	p := s.Passfile(name)
+
+	ciphertext, err := s.storage.Get(ctx, p)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", p, err)
+	}
+
+	recipients, err := s.useableKeys(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to list useable keys for %q: %w", p, err)
+	}
+	recipients = s.ensureOurKeyID(ctx, recipients)
+	if len(recipients) < 1 {
+		return fmt.Errorf("no useable recipients for %q", name)
+	}
+
+	ciphertext, err = rw.Rewrap(ctx, ciphertext, recipients)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Set(ctx, p, ciphertext); err != nil {
+		return fmt.Errorf("failed to write secret: %w", err)
+	}
+
+	if IsNoGitOps(ctx) {
+		return nil
+	}
+
+	return s.storage.TryAdd(ctx, p)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/set"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -17,6 +18,12 @@ type Store struct {
 	path    string
 	crypto  backend.Crypto
 	storage backend.Storage
+	// dual is set while the store is being migrated to another crypto
+	// backend. See migration.go.
+	dual      *Store
+	secondary bool
+	// accessLog records decrypted secrets. See SetAccessLog.
+	accessLog func(ctx context.Context, name, action string)
 }
 
 // Init initializes this sub store.
@@ -70,6 +77,10 @@ func New(ctx context.Context, alias, path string) (*Store, error) {
 
 	debug.Log("Crypto for %s => %s initialized as %v", alias, path, s.crypto)
 
+	if err := s.initMigration(ctx); err != nil {
+		return nil, fmt.Errorf("failed to init migration: %w", err)
+	}
+
 	return s, nil
 }
 
@@ -158,18 +169,18 @@ func (s *Store) Exists(ctx context.Context, name string) bool {
 }
 
 func (s *Store) useableKeys(ctx context.Context, name string) ([]string, error) {
-	rs, err := s.GetRecipients(ctx, name)
+	ids, err := s.ExpandedRecipients(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recipients: %w", err)
 	}
 
 	if !IsCheckRecipients(ctx) {
-		return rs.IDs(), nil
+		return ids, nil
 	}
 
-	kl, err := s.crypto.FindRecipients(ctx, rs.IDs()...)
+	kl, err := s.crypto.FindRecipients(ctx, ids...)
 	if err != nil {
-		return rs.IDs(), err
+		return ids, err
 	}
 
 	return kl, nil
@@ -200,6 +211,29 @@ func (s *Store) Storage() backend.Storage {
 	return s.storage
 }
 
+// SetAccessLog sets the function that records the secrets decrypted from this
+// store. All reads of secrets call it, unless the context marks them as
+// internal with accesslog.WithInternalRead.
+func (s *Store) SetAccessLog(fn func(ctx context.Context, name, action string)) {
+	s.accessLog = fn
+	if s.dual != nil {
+		s.dual.accessLog = fn
+	}
+}
+
+// logAccess records that the named secret was decrypted.
+func (s *Store) logAccess(ctx context.Context, name, action string) {
+	if s.accessLog == nil || accesslog.IsInternalRead(ctx) {
+		return
+	}
+
+	if s.alias != "" {
+		name = s.alias + Sep + name
+	}
+
+	s.accessLog(ctx, name, accesslog.GetAction(ctx, action))
+}
+
 // Valid returns true if this store is not nil.
 func (s *Store) Valid() bool {
 	return s != nil
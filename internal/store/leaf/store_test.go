@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
 	"github.com/gopasspw/gopass/internal/backend"
 	_ "github.com/gopasspw/gopass/internal/backend/crypto"
 	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
@@ -92,6 +93,40 @@ func TestStore(t *testing.T) {
 	}
 }
 
+func TestAccessLog(t *testing.T) {
+	s, err := createSubStore(t)
+	require.NoError(t, err)
+
+	ctx := config.NewContextInMemory()
+
+	var logged []string
+	s.SetAccessLog(func(_ context.Context, name, action string) {
+		logged = append(logged, name+" "+action)
+	})
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("foo")
+	require.NoError(t, s.Set(ctx, "foo", sec))
+	assert.Empty(t, logged)
+
+	_, err = s.Get(ctx, "foo")
+	require.NoError(t, err)
+	_, err = s.Get(accesslog.WithAction(ctx, "edit"), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo decrypt", "foo edit"}, logged)
+
+	logged = nil
+	_, err = s.Get(accesslog.WithInternalRead(ctx, true), "foo")
+	require.NoError(t, err)
+	require.NoError(t, s.RebuildTags(ctx))
+	assert.Empty(t, logged)
+
+	s.alias = "team"
+	_, err = s.Decrypt(ctx, "bar", []byte("content"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team/bar decrypt"}, logged)
+}
+
 func TestIdFile(t *testing.T) {
 	ctx := config.NewContextInMemory()
 
@@ -0,0 +1,426 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/accesslog"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/set"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"gopkg.in/yaml.v3"
+)
+
+// TagIndex is the name of the encrypted index of the tags of all secrets in
+// a store. It has the extension of the crypto backend, e.g.
+// `.gopass-tags.gpg`, and is encrypted for the recipients of the store root.
+// Looking up tags would require decrypting every secret otherwise.
+const TagIndex = ".gopass-tags"
+
+// tagIndex maps secret names to their tags.
+type tagIndex map[string][]string
+
+// tagKey returns the name of the secret in the tag index.
+func tagKey(name string) string {
+	return strings.TrimPrefix(name, Sep)
+}
+
+func (s *Store) tagIndexFile() string {
+	return TagIndex + "." + s.crypto.Ext()
+}
+
+// hasTagIndex returns true if the tag index exists.
+func (s *Store) hasTagIndex(ctx context.Context) bool {
+	return s.storage.Exists(ctx, s.tagIndexFile())
+}
+
+// loadTags decrypts the tag index. It is empty if no secret was tagged, yet.
+func (s *Store) loadTags(ctx context.Context) (tagIndex, error) {
+	idx := tagIndex{}
+	if !s.hasTagIndex(ctx) {
+		return idx, nil
+	}
+
+	ciphertext, err := s.storage.Get(ctx, s.tagIndexFile())
+	if err != nil {
+		return idx, fmt.Errorf("failed to read tag index: %w", err)
+	}
+
+	buf, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		if s.dual != nil && s.dual.hasTagIndex(ctx) {
+			return s.dual.loadTags(ctx)
+		}
+
+		return idx, fmt.Errorf("failed to decrypt tag index: %w", err)
+	}
+
+	if err := yaml.Unmarshal(buf, &idx); err != nil {
+		return idx, fmt.Errorf("failed to parse tag index: %w", err)
+	}
+
+	return idx, nil
+}
+
+// saveTags encrypts and writes the tag index. Like secrets it is added to
+// git but not committed.
+func (s *Store) saveTags(ctx context.Context, idx tagIndex) error {
+	buf, err := yaml.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tag index: %w", err)
+	}
+
+	recipients, err := s.useableKeys(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list useable keys: %w", err)
+	}
+	recipients = s.ensureOurKeyID(ctx, recipients)
+	if len(recipients) < 1 {
+		return fmt.Errorf("no useable recipients for the tag index")
+	}
+
+	ciphertext, err := s.crypto.Encrypt(ctx, buf, recipients)
+	if err != nil {
+		debug.Log("Failed encrypt tag index: %s", err)
+
+		return store.ErrEncrypt
+	}
+
+	p := s.tagIndexFile()
+	if err := s.storage.Set(ctx, p, ciphertext); err != nil && !errors.Is(err, store.ErrMeaninglessWrite) {
+		return fmt.Errorf("failed to write tag index: %w", err)
+	}
+
+	if s.dual != nil {
+		if err := s.dual.saveTags(ctx, idx); err != nil {
+			return err
+		}
+	}
+
+	if IsNoGitOps(ctx) {
+		return nil
+	}
+
+	return s.storage.TryAdd(ctx, p)
+}
+
+// updateTags applies fn to the tag index and saves it if anything changed.
+// An index that can not be read, e.g. because it is encrypted for the
+// recipients of the store root only, is stale. It is left alone so the
+// change to the secret itself still succeeds.
+func (s *Store) updateTags(ctx context.Context, fn func(tagIndex) bool) error {
+	if IsNoTagIndex(ctx) {
+		return nil
+	}
+
+	idx, err := s.loadTags(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Not updating the tag index: %s. Run 'gopass tag rebuild' to recreate it.", err)
+
+		return nil
+	}
+
+	if !fn(idx) {
+		return nil
+	}
+
+	return s.saveTags(ctx, idx)
+}
+
+// indexTags records the tags of the secret in the tag index. Nothing is
+// written if no secret in this store was ever tagged.
+func (s *Store) indexTags(ctx context.Context, name string, sec gopass.Byter) error {
+	if IsNoTagIndex(ctx) {
+		return nil
+	}
+
+	name = tagKey(name)
+
+	var tags []string
+	if ps, ok := sec.(gopass.Secret); ok {
+		tags = secrets.Tags(ps)
+	} else if ps, err := secparse.Parse(sec.Bytes()); err == nil {
+		tags = secrets.Tags(ps)
+	}
+
+	if len(tags) < 1 && !s.hasTagIndex(ctx) {
+		return nil
+	}
+
+	return s.updateTags(ctx, func(idx tagIndex) bool {
+		if slices.Equal(idx[name], tags) {
+			return false
+		}
+		if len(tags) < 1 {
+			delete(idx, name)
+
+			return true
+		}
+		idx[name] = tags
+
+		return true
+	})
+}
+
+// unindexTags removes the secret, or all secrets below it if recurse is
+// true, from the tag index.
+func (s *Store) unindexTags(ctx context.Context, name string, recurse bool) error {
+	if !s.hasTagIndex(ctx) {
+		return nil
+	}
+
+	name = tagKey(name)
+
+	return s.updateTags(ctx, func(idx tagIndex) bool {
+		var changed bool
+		for n := range idx {
+			if n == name || (recurse && strings.HasPrefix(n, strings.TrimSuffix(name, Sep)+Sep)) {
+				delete(idx, n)
+				changed = true
+			}
+		}
+
+		return changed
+	})
+}
+
+// copyTags copies the tags of one secret to another in the tag index.
+func (s *Store) copyTags(ctx context.Context, from, to string, del bool) error {
+	if !s.hasTagIndex(ctx) {
+		return nil
+	}
+
+	from, to = tagKey(from), tagKey(to)
+
+	return s.updateTags(ctx, func(idx tagIndex) bool {
+		tags, found := idx[from]
+		if !found {
+			if _, found := idx[to]; !found {
+				return false
+			}
+			delete(idx, to)
+
+			return true
+		}
+
+		idx[to] = tags
+		if del {
+			delete(idx, from)
+		}
+
+		return true
+	})
+}
+
+// Tags returns all tags in this store and the secrets that have them. The
+// secret names are prefixed with the mount point.
+func (s *Store) Tags(ctx context.Context) (map[string][]string, error) {
+	idx, err := s.loadTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string][]string, len(idx))
+	for name, ts := range idx {
+		if s.alias != "" {
+			name = s.alias + Sep + name
+		}
+		for _, t := range ts {
+			tags[t] = append(tags[t], name)
+		}
+	}
+
+	for t := range tags {
+		slices.Sort(tags[t])
+	}
+
+	return tags, nil
+}
+
+// Tagged returns the names of all secrets that have all of the given tags.
+// The names are prefixed with the mount point.
+func (s *Store) Tagged(ctx context.Context, tags ...string) ([]string, error) {
+	idx, err := s.loadTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, ts := range idx {
+		if !set.New(tags...).IsSubset(set.New(ts...)) {
+			continue
+		}
+		if s.alias != "" {
+			name = s.alias + Sep + name
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// RebuildTags decrypts all secrets and recreates the tag index. Use it when
+// secrets were changed without gopass or the index can not be decrypted.
+func (s *Store) RebuildTags(ctx context.Context) error {
+	names, err := s.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list store: %w", err)
+	}
+
+	idx := tagIndex{}
+	for _, name := range names {
+		name = strings.TrimPrefix(name, s.alias+Sep)
+
+		sec, err := s.Get(accesslog.WithInternalRead(ctx, true), name)
+		if err != nil {
+			out.Errorf(ctx, "Failed to decrypt %s: %s", name, err)
+
+			continue
+		}
+
+		if tags := secrets.Tags(sec); len(tags) > 0 {
+			idx[name] = tags
+		}
+	}
+
+	if len(idx) < 1 && !s.hasTagIndex(ctx) {
+		return nil
+	}
+
+	if err := s.saveTags(ctx, idx); err != nil {
+		return err
+	}
+
+	return s.storage.TryCommit(ctx, "Rebuilt tag index")
+}
+
+// reencryptTags re-encrypts the tag index for the current recipients.
+func (s *Store) reencryptTags(ctx context.Context) error {
+	if !s.hasTagIndex(ctx) {
+		return nil
+	}
+
+	idx, err := s.loadTags(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Not re-encrypting the tag index: %s. Run 'gopass tag rebuild' to recreate it.", err)
+
+		return nil
+	}
+
+	return s.saveTags(ctx, idx)
+}
+
+// TransferTags copies the tags of a secret that was copied to another store
+// without re-encrypting it.
+func (s *Store) TransferTags(ctx context.Context, dst *Store, from, to string, del bool) error {
+	if !s.hasTagIndex(ctx) {
+		return nil
+	}
+
+	idx, err := s.loadTags(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Not transferring the tags of %s: %s. Run 'gopass tag rebuild' to recreate the tag index.", from, err)
+
+		return nil
+	}
+
+	tags, found := idx[tagKey(from)]
+	if !found {
+		return nil
+	}
+
+	if err := dst.updateTags(ctx, func(dIdx tagIndex) bool {
+		dIdx[tagKey(to)] = tags
+
+		return true
+	}); err != nil {
+		return err
+	}
+
+	if !del {
+		return nil
+	}
+
+	return s.unindexTags(ctx, from, false)
+}
+
+// MergeTags does a three-way merge of the encrypted base, ours and theirs
+// versions of the tag index in file. It is invoked by the git merge driver.
+// Each secret is merged separately, if both sides changed the tags of the
+// same secret they are combined.
+func (s *Store) MergeTags(ctx context.Context, file string, base, ours, theirs []byte) ([]byte, error) {
+	if s.dual != nil && file == s.dual.tagIndexFile() {
+		return s.dual.MergeTags(ctx, file, base, ours, theirs)
+	}
+	if file != s.tagIndexFile() {
+		return nil, fmt.Errorf("%s is not the tag index of this store", file)
+	}
+
+	idxs := make([]tagIndex, 0, 3)
+	for i, ciphertext := range [][]byte{base, ours, theirs} {
+		idx := tagIndex{}
+		if len(ciphertext) > 0 {
+			buf, err := s.crypto.Decrypt(ctx, ciphertext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt version %d of the tag index: %w", i, err)
+			}
+			if err := yaml.Unmarshal(buf, &idx); err != nil {
+				return nil, fmt.Errorf("failed to parse version %d of the tag index: %w", i, err)
+			}
+		}
+		idxs = append(idxs, idx)
+	}
+
+	merged := mergeTags(idxs[0], idxs[1], idxs[2])
+	debug.Log("merged tag index with %d entries", len(merged))
+
+	buf, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tag index: %w", err)
+	}
+
+	return s.encryptFor(ctx, "", buf)
+}
+
+func mergeTags(base, ours, theirs tagIndex) tagIndex {
+	names := set.New[string]()
+	for _, idx := range []tagIndex{base, ours, theirs} {
+		for name := range idx {
+			names.Add(name)
+		}
+	}
+
+	merged := make(tagIndex, names.Len())
+	for _, name := range names.Elements() {
+		b, o, t := base[name], ours[name], theirs[name]
+
+		var tags []string
+		switch {
+		case slices.Equal(o, t), slices.Equal(t, b):
+			tags = o
+		case slices.Equal(o, b):
+			tags = t
+		default:
+			tags = slices.Clone(o)
+			for _, tag := range t {
+				if !slices.Contains(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+		}
+
+		if len(tags) > 0 {
+			merged[name] = tags
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,132 @@
+package leaf
+
+import (
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	ctx := config.NewContextInMemory()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	tempdir := t.TempDir()
+	_, _, err := createStore(tempdir, nil, []string{})
+	require.NoError(t, err)
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	sec := secrets.NewAKV()
+	sec.SetPassword("secret")
+	require.NoError(t, s.Set(ctx, "untagged", sec))
+
+	// no index until something is tagged
+	assert.False(t, s.hasTagIndex(ctx))
+
+	for name, tags := range map[string][]string{
+		"db/prod":    {"prod", "pci"},
+		"db/staging": {"staging"},
+		"web/prod":   {"prod"},
+	} {
+		sec := secrets.NewAKV()
+		sec.SetPassword("secret")
+		require.NoError(t, secrets.SetTags(sec, tags))
+		require.NoError(t, s.Set(ctx, name, sec))
+	}
+	assert.True(t, s.hasTagIndex(ctx))
+
+	names, err := s.Tagged(ctx, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db/prod", "web/prod"}, names)
+
+	names, err = s.Tagged(ctx, "prod", "pci")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db/prod"}, names)
+
+	// the index is not listed as secret
+	list, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.NotContains(t, list, TagIndex)
+	assert.Len(t, list, 4)
+
+	t.Run("move", func(t *testing.T) {
+		require.NoError(t, s.Move(ctx, "web/prod", "web/live"))
+		names, err := s.Tagged(ctx, "prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db/prod", "web/live"}, names)
+	})
+
+	t.Run("untag", func(t *testing.T) {
+		sec, err := s.Get(ctx, "web/live")
+		require.NoError(t, err)
+		require.NoError(t, secrets.SetTags(sec, nil))
+		require.NoError(t, s.Set(ctx, "web/live", sec))
+
+		names, err := s.Tagged(ctx, "prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db/prod"}, names)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		require.NoError(t, s.Prune(ctx, "db"))
+		tags, err := s.Tags(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		sec := secrets.NewAKV()
+		sec.SetPassword("secret")
+		require.NoError(t, secrets.SetTags(sec, []string{"prod"}))
+		require.NoError(t, s.Set(WithNoTagIndex(ctx, true), "manual", sec))
+
+		names, err := s.Tagged(ctx, "prod")
+		require.NoError(t, err)
+		assert.Empty(t, names)
+
+		require.NoError(t, s.RebuildTags(ctx))
+		names, err = s.Tagged(ctx, "prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"manual"}, names)
+	})
+
+	t.Run("stale index", func(t *testing.T) {
+		require.NoError(t, s.storage.Set(ctx, s.tagIndexFile(), []byte("not: [a tag index")))
+
+		// writing secrets still works, the index is left alone.
+		require.NoError(t, s.Set(ctx, "web/prod", sec))
+		_, err := s.Tagged(ctx, "prod")
+		require.Error(t, err)
+
+		require.NoError(t, s.RebuildTags(ctx))
+		names, err := s.Tagged(ctx, "prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"manual"}, names)
+	})
+}
+
+func TestMergeTags(t *testing.T) {
+	t.Parallel()
+
+	base := tagIndex{"a": {"prod"}, "b": {"dev"}, "c": {"old"}}
+	ours := tagIndex{"a": {"prod", "pci"}, "b": {"dev"}, "c": {"old"}}
+	theirs := tagIndex{"a": {"prod", "sox"}, "b": {"staging"}, "d": {"new"}}
+
+	assert.Equal(t, tagIndex{
+		"a": {"prod", "pci", "sox"},
+		"b": {"staging"},
+		"d": {"new"},
+	}, mergeTags(base, ours, theirs))
+}
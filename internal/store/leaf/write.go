@@ -23,30 +23,22 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return fmt.Errorf("writing to %s is disabled by `core.readonly`.", s.alias)
 	}
 
-	p := s.Passfile(name)
-
-	recipients, err := s.useableKeys(ctx, name)
+	p, err := s.write(ctx, name, sec.Bytes())
 	if err != nil {
-		return fmt.Errorf("failed to list useable keys for %q: %w", p, err)
+		return err
 	}
 
-	// make sure the encryptor can decrypt later
-	recipients = s.ensureOurKeyID(ctx, recipients)
-
-	// we can not encrypt without recipients
-	if len(recipients) < 1 {
-		return fmt.Errorf("no useable recipients for %q. can not encrypt without recipients.", name)
+	paths := []string{p}
+	if s.dual != nil {
+		dp, err := s.dual.write(ctx, name, sec.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to write %s copy: %w", s.dual.crypto.Name(), err)
+		}
+		paths = append(paths, dp)
 	}
 
-	ciphertext, err := s.crypto.Encrypt(ctx, sec.Bytes(), recipients)
-	if err != nil {
-		debug.Log("Failed encrypt secret: %s", err)
-
-		return store.ErrEncrypt
-	}
-
-	if err := s.storage.Set(ctx, p, ciphertext); err != nil {
-		return fmt.Errorf("failed to write secret: %w", err)
+	if err := s.indexTags(ctx, name, sec); err != nil {
+		return fmt.Errorf("failed to update tag index: %w", err)
 	}
 
 	// It is not possible to perform concurrent git add and git commit commands
@@ -58,8 +50,10 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return nil
 	}
 
-	if err := s.storage.TryAdd(ctx, p); err != nil {
-		return fmt.Errorf("failed to add %q to git: %w", p, err)
+	for _, p := range paths {
+		if err := s.storage.TryAdd(ctx, p); err != nil {
+			return fmt.Errorf("failed to add %q to git: %w", p, err)
+		}
 	}
 
 	if !ctxutil.IsGitCommit(ctx) {
@@ -77,6 +71,38 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 	return err
 }
 
+// write encrypts the secret for the recipients of this store and writes it
+// to the storage. It returns the path of the secret.
+func (s *Store) write(ctx context.Context, name string, content []byte) (string, error) {
+	p := s.Passfile(name)
+
+	recipients, err := s.useableKeys(ctx, name)
+	if err != nil {
+		return p, fmt.Errorf("failed to list useable keys for %q: %w", p, err)
+	}
+
+	// make sure the encryptor can decrypt later
+	recipients = s.ensureOurKeyID(ctx, recipients)
+
+	// we can not encrypt without recipients
+	if len(recipients) < 1 {
+		return p, fmt.Errorf("no useable recipients for %q. can not encrypt without recipients.", name)
+	}
+
+	ciphertext, err := s.crypto.Encrypt(ctx, content, recipients)
+	if err != nil {
+		debug.Log("Failed encrypt secret: %s", err)
+
+		return p, store.ErrEncrypt
+	}
+
+	if err := s.storage.Set(ctx, p, ciphertext); err != nil {
+		return p, fmt.Errorf("failed to write secret: %w", err)
+	}
+
+	return p, nil
+}
+
 func (s *Store) gitCommitAndPush(ctx context.Context, name string) error {
 	if err := s.storage.TryCommit(ctx, fmt.Sprintf("Save secret to %s: %s", name, ctxutil.GetCommitMessage(ctx))); err != nil {
 		return fmt.Errorf("failed to commit changes to git: %w", err)
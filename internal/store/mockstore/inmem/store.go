@@ -218,6 +218,11 @@ func (m *InMem) GetRevision(context.Context, string, string) ([]byte, error) {
 	return []byte("foo\nbar"), nil
 }
 
+// Deletions is not implemented.
+func (m *InMem) Deletions(context.Context) ([]backend.Deletion, error) {
+	return nil, nil
+}
+
 // Status is not implemented.
 func (m *InMem) Status(context.Context) ([]byte, error) {
 	return []byte(""), nil
@@ -0,0 +1,29 @@
+package root
+
+import (
+	"context"
+
+	"github.com/gopasspw/gopass/internal/accesslog"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+)
+
+// LogAccess records an access to the named secret in the local access log,
+// if it is enabled. Failures are reported but do not prevent the access.
+// All mounted stores call it when they decrypt a secret. Actions should only
+// call it for accesses that don't decrypt, e.g. copying a generated password
+// to the clipboard.
+func (r *Store) LogAccess(ctx context.Context, name, action string) {
+	// this is read from the global config only, so a store can not disable it.
+	if cfg, _ := config.FromContext(ctx); !config.AsBool(cfg.GetGlobal("core.accesslog")) {
+		return
+	}
+
+	if r.store == nil {
+		return
+	}
+
+	if err := accesslog.New(accesslog.Path(), r.store.Crypto()).Append(ctx, name, action); err != nil {
+		out.Warningf(ctx, "Failed to write access log: %s", err)
+	}
+}
@@ -44,6 +44,7 @@ func (r *Store) Init(ctx context.Context, alias, path string, ids ...string) err
 	if err != nil {
 		return fmt.Errorf("failed to instantiate new sub store: %w", err)
 	}
+	sub.SetAccessLog(r.LogAccess)
 
 	if !r.store.IsInitialized(ctx) && alias == "" {
 		r.store = sub
@@ -83,6 +84,7 @@ func (r *Store) initialize(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize the root store at %q: %w", r.cfg.Path(), err)
 	}
+	s.SetAccessLog(r.LogAccess)
 
 	debug.Log("Root Store initialized at %s", path)
 
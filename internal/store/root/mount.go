@@ -73,6 +73,7 @@ func (r *Store) initSub(ctx context.Context, alias, path string, keys []string)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize store %q at %q: %w", alias, path, err)
 	}
+	s.SetAccessLog(r.LogAccess)
 
 	if s.IsInitialized(ctx) {
 		return s, nil
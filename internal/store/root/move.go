@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/accesslog"
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/internal/store/leaf"
@@ -56,7 +58,7 @@ func (r *Store) move(ctx context.Context, from, to string, del bool) error {
 		}
 	}
 
-	if err := subFrom.Storage().TryPush(ctx, "", ""); err != nil {
+	if err := subFrom.Storage().TryPush(config.WithMount(ctx, subFrom.Alias()), "", ""); err != nil {
 		return fmt.Errorf("failed to push change to git remote: %w", err)
 	}
 
@@ -64,7 +66,7 @@ func (r *Store) move(ctx context.Context, from, to string, del bool) error {
 		return nil
 	}
 
-	if err := subTo.Storage().TryPush(ctx, "", ""); err != nil {
+	if err := subTo.Storage().TryPush(config.WithMount(ctx, subTo.Alias()), "", ""); err != nil {
 		return fmt.Errorf("failed to push change to git remote: %w", err)
 	}
 
@@ -122,7 +124,7 @@ func (r *Store) moveFromTo(ctx context.Context, subFrom *leaf.Store, from, to, f
 
 		debug.Log("direct move failed to move entry %q to %q: %s. Falling back to get and set", src, dst, err)
 
-		content, err := r.Get(ctx, src)
+		content, err := r.Get(accesslog.WithInternalRead(ctx, true), src)
 		if err != nil {
 			return fmt.Errorf("source %s does not exist in source store %s: %w", from, subFrom.Alias(), err)
 		}
@@ -188,6 +190,10 @@ func (r *Store) directMove(ctx context.Context, from, to string, del bool) error
 		}
 	}
 
+	if err := subFrom.TransferTags(ctx, subTo, from, to, del); err != nil {
+		debug.Log("failed to transfer tags of %q to %s: %s", from, subTo.Alias(), err)
+	}
+
 	if err := subFrom.Storage().Add(ctx, sfn); err != nil {
 		debug.Log("failed to add %q to %s: %w", sfn, subFrom.Alias(), err)
 	}
@@ -2,10 +2,15 @@ package root
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
@@ -42,6 +47,7 @@ func (r *Store) RCSRemoveRemote(ctx context.Context, name, remote string) error
 // RCSPull performs a git pull.
 func (r *Store) RCSPull(ctx context.Context, name, origin, remote string) error {
 	store, _ := r.getStore(name)
+	ctx = config.WithMount(ctx, store.Alias())
 
 	return store.Storage().Pull(ctx, origin, remote)
 }
@@ -49,6 +55,7 @@ func (r *Store) RCSPull(ctx context.Context, name, origin, remote string) error
 // RCSPush performs a git push.
 func (r *Store) RCSPush(ctx context.Context, name, origin, remote string) error {
 	store, _ := r.getStore(name)
+	ctx = config.WithMount(ctx, store.Alias())
 
 	return store.Storage().Push(ctx, origin, remote)
 }
@@ -68,6 +75,41 @@ func (r *Store) GetRevision(ctx context.Context, name, revision string) (context
 	return ctx, sec, err
 }
 
+// ListDeleted lists all secrets that were deleted in any of the mounted
+// stores, newest first. Stores that do not support this are skipped.
+func (r *Store) ListDeleted(ctx context.Context) ([]backend.Deletion, error) {
+	dels := []backend.Deletion{}
+	for _, alias := range append([]string{""}, r.MountPoints()...) {
+		sub, err := r.GetSubStore(alias)
+		if err != nil || sub == nil {
+			continue
+		}
+
+		sd, err := sub.ListDeleted(ctx)
+		if errors.Is(err, backend.ErrNotSupported) {
+			debug.Log("listing deleted secrets not supported for %q", alias)
+
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deleted secrets in %q: %w", alias, err)
+		}
+
+		for _, d := range sd {
+			if alias != "" {
+				d.Name = alias + "/" + d.Name
+			}
+			dels = append(dels, d)
+		}
+	}
+
+	sort.SliceStable(dels, func(i, j int) bool {
+		return dels[i].Date.After(dels[j].Date)
+	})
+
+	return dels, nil
+}
+
 // RCSStatus show the git status.
 // TODO this should likely iterate over all stores.
 func (r *Store) RCSStatus(ctx context.Context, name string) error {
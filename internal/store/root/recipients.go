@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/gopasspw/gopass/internal/out"
@@ -20,12 +21,21 @@ func (r *Store) ListRecipients(ctx context.Context, store string) []string {
 	return sub.Recipients(ctx)
 }
 
-// CheckRecipients checks all current recipients to make sure that they are
-// valid, e.g. not expired.
-func (r *Store) CheckRecipients(ctx context.Context, store string) error {
+// CheckRecipients checks the recipients that apply to the given secret or
+// store to make sure that they are valid, e.g. not expired. Recipients of
+// other folders are not checked.
+func (r *Store) CheckRecipients(ctx context.Context, name string) error {
+	sub, name := r.getStore(name)
+
+	return sub.CheckRecipientsFor(ctx, name)
+}
+
+// CheckRecipientKeys returns the recipients of the given store with invalid
+// keys and the ones with keys that will expire soon.
+func (r *Store) CheckRecipientKeys(ctx context.Context, store string) (map[string]error, map[string]time.Time) {
 	sub, _ := r.getStore(store)
 
-	return sub.CheckRecipients(ctx)
+	return sub.CheckRecipientKeys(ctx)
 }
 
 // AddRecipient adds a single recipient to the given store.
@@ -42,6 +52,28 @@ func (r *Store) RemoveRecipient(ctx context.Context, store, rec string) error {
 	return sub.RemoveRecipient(ctx, rec)
 }
 
+// AddGroupRecipient adds a single recipient to a group of the given store.
+func (r *Store) AddGroupRecipient(ctx context.Context, store, group, rec string) error {
+	sub, _ := r.getStore(store)
+
+	return sub.AddGroupRecipient(ctx, group, rec)
+}
+
+// RemoveGroupRecipient removes a single recipient from a group of the given store.
+func (r *Store) RemoveGroupRecipient(ctx context.Context, store, group, rec string) error {
+	sub, _ := r.getStore(store)
+
+	return sub.RemoveGroupRecipient(ctx, group, rec)
+}
+
+// ExposedSecrets returns all secrets of the given store that the recipient
+// could decrypt now or in any earlier revision.
+func (r *Store) ExposedSecrets(ctx context.Context, store string, ids ...string) ([]string, error) {
+	sub, _ := r.getStore(store)
+
+	return sub.ExposedSecrets(ctx, ids...)
+}
+
 func (r *Store) addRecipient(ctx context.Context, prefix string, root *tree.Root, recp string, pretty bool) error {
 	sub, _ := r.getStore(prefix)
 	key := recp
@@ -95,7 +127,12 @@ func (r *Store) SaveRecipients(ctx context.Context, ack bool) error {
 func (r *Store) RecipientsTree(ctx context.Context, pretty bool) (*tree.Root, error) {
 	root := tree.New("gopass")
 
-	for name, recps := range r.store.RecipientsTree(ctx) {
+	rt, err := r.store.RecipientsTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, recps := range rt {
 		if name != "" {
 			name += "/"
 		}
@@ -124,7 +161,12 @@ func (r *Store) RecipientsTree(ctx context.Context, pretty bool) (*tree.Root, er
 			return nil, fmt.Errorf("failed to add mount: %w", err)
 		}
 
-		for name, recps := range substore.RecipientsTree(ctx) {
+		st, err := substore.RecipientsTree(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list recipients of %s: %w", alias, err)
+		}
+
+		for name, recps := range st {
 			if name != "" {
 				name += "/"
 			}
@@ -0,0 +1,66 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Tags returns all tags in any of the mounted stores and the secrets that
+// have them.
+func (r *Store) Tags(ctx context.Context) (map[string][]string, error) {
+	tags := map[string][]string{}
+	for _, alias := range append([]string{""}, r.MountPoints()...) {
+		sub, err := r.GetSubStore(alias)
+		if err != nil || sub == nil {
+			continue
+		}
+
+		st, err := sub.Tags(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tags of %q: %w", alias, err)
+		}
+
+		for t, names := range st {
+			tags[t] = append(tags[t], names...)
+		}
+	}
+
+	for t := range tags {
+		slices.Sort(tags[t])
+	}
+
+	return tags, nil
+}
+
+// Tagged returns the names of all secrets in any of the mounted stores that
+// have all of the given tags.
+func (r *Store) Tagged(ctx context.Context, tags ...string) ([]string, error) {
+	var names []string
+	for _, alias := range append([]string{""}, r.MountPoints()...) {
+		sub, err := r.GetSubStore(alias)
+		if err != nil || sub == nil {
+			continue
+		}
+
+		sn, err := sub.Tagged(ctx, tags...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tags of %q: %w", alias, err)
+		}
+
+		names = append(names, sn...)
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// RebuildTags recreates the tag index of the given store.
+func (r *Store) RebuildTags(ctx context.Context, store string) error {
+	sub, err := r.GetSubStore(store)
+	if err != nil {
+		return err
+	}
+
+	return sub.RebuildTags(ctx)
+}
@@ -9,11 +9,15 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/hashsum"
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2i"
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2id"
 	"github.com/gopasspw/gopass/internal/pwschemes/bcrypt"
+	"github.com/gopasspw/gopass/internal/reference"
+	"github.com/gopasspw/gopass/internal/sensitive"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/jsimonetti/pwscheme/md5crypt"
 	"github.com/jsimonetti/pwscheme/ssha"
 	"github.com/jsimonetti/pwscheme/ssha256"
@@ -178,6 +182,17 @@ func bcryptFunc() func(...string) (string, error) {
 	}
 }
 
+// getResolved returns the secret with all references to other secrets
+// resolved.
+func getResolved(ctx context.Context, kv kvstore, name string) (gopass.Secret, error) {
+	sec, err := kv.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return reference.Resolve(ctx, kv, name, sec)
+}
+
 func get(ctx context.Context, kv kvstore) func(...string) (string, error) {
 	return func(s ...string) (string, error) {
 		if len(s) < 1 {
@@ -188,11 +203,19 @@ func get(ctx context.Context, kv kvstore) func(...string) (string, error) {
 			return "", fmt.Errorf("KV is nil")
 		}
 
-		sec, err := kv.Get(ctx, s[0])
+		sec, err := getResolved(ctx, kv, s[0])
 		if err != nil {
 			return err.Error(), nil
 		}
 
+		if sensitive.IsMasking(ctx) {
+			if mp, ok := kv.(mountPointer); ok {
+				ctx = config.WithMount(ctx, mp.MountPoint(s[0]))
+			}
+
+			return sensitive.Content(ctx, sec), nil
+		}
+
 		return string(sec.Bytes()), nil
 	}
 }
@@ -207,7 +230,7 @@ func getPassword(ctx context.Context, kv kvstore) func(...string) (string, error
 			return "", fmt.Errorf("KV is nil")
 		}
 
-		sec, err := kv.Get(ctx, s[0])
+		sec, err := getResolved(ctx, kv, s[0])
 		if err != nil {
 			return err.Error(), nil
 		}
@@ -226,7 +249,7 @@ func getValue(ctx context.Context, kv kvstore) func(...string) (string, error) {
 			return "", fmt.Errorf("KV is nil")
 		}
 
-		sec, err := kv.Get(ctx, s[0])
+		sec, err := getResolved(ctx, kv, s[0])
 		if err != nil {
 			return err.Error(), nil
 		}
@@ -250,7 +273,7 @@ func getValues(ctx context.Context, kv kvstore) func(...string) ([]string, error
 			return nil, fmt.Errorf("KV is nil")
 		}
 
-		sec, err := kv.Get(ctx, s[0])
+		sec, err := getResolved(ctx, kv, s[0])
 		if err != nil {
 			return nil, fmt.Errorf("failed to get %q: %w", s[0], err)
 		}
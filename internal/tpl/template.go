@@ -14,6 +14,12 @@ type kvstore interface {
 	Get(context.Context, string) (gopass.Secret, error)
 }
 
+// mountPointer is implemented by stores that can tell which mount a secret
+// belongs to. It is used to look up per-mount config options.
+type mountPointer interface {
+	MountPoint(string) string
+}
+
 type payload struct {
 	Dir     string
 	DirName string
@@ -9,6 +9,7 @@ import (
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2i"
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2id"
+	"github.com/gopasspw/gopass/internal/sensitive"
 	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
 	"github.com/jsimonetti/pwscheme/md5crypt"
@@ -353,3 +354,42 @@ func TestVars(t *testing.T) {
 		})
 	}
 }
+
+func TestSensitive(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	cfg, _ := config.FromContext(ctx)
+	require.NoError(t, cfg.Set("", "show.sensitive-keys", "barkey"))
+	ctx = sensitive.WithMasking(ctx, true)
+
+	buf, err := Execute(ctx, `{{get "testdir"}}`, "testdir", nil, kvMock{})
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "barkey: *****")
+	assert.NotContains(t, string(buf), "barvalue")
+
+	// explicitly requested keys are not masked.
+	buf, err = Execute(ctx, `{{getval "testdir" "barkey"}}`, "testdir", nil, kvMock{})
+	require.NoError(t, err)
+	assert.Equal(t, "barvalue", string(buf))
+}
+
+type refMock map[string]string
+
+func (r refMock) Get(ctx context.Context, key string) (gopass.Secret, error) {
+	return secparse.Parse([]byte(r[key])) //nolint:wrapcheck
+}
+
+func TestReferences(t *testing.T) {
+	t.Parallel()
+
+	ctx := config.NewContextInMemory()
+	kv := refMock{
+		"db":  "dbpass\nuser: admin\n",
+		"svc": "!ref db\nuser: !ref db#user\n",
+	}
+
+	buf, err := Execute(ctx, `{{getpw "svc"}} {{getval "svc" "user"}}`, "svc", nil, kv)
+	require.NoError(t, err)
+	assert.Equal(t, "dbpass admin", string(buf))
+}
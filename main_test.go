@@ -74,6 +74,8 @@ var commandsWithError = set.Map([]string{
 	".git",
 	".git.push",
 	".git.pull",
+	".git.merge-driver",
+	".git.textconv",
 	".git.status",
 	".git.remote.add",
 	".git.remote.remove",
@@ -91,12 +93,17 @@ var commandsWithError = set.Map([]string{
 	".rcs.status",
 	".recipients.add",
 	".recipients.remove",
+	".restore",
+	".rotate",
 	".show",
 	".sum",
+	".tag.add",
+	".tag.remove",
 	".templates.edit",
 	".templates.remove",
 	".templates.show",
 	".unclip",
+	".undelete",
 })
 
 func TestGetCommands(t *testing.T) {
@@ -134,7 +141,7 @@ func TestGetCommands(t *testing.T) {
 	c.Context = ctx
 
 	commands := getCommands(act, app)
-	assert.Len(t, commands, 41)
+	assert.Len(t, commands, 47)
 
 	prefix := ""
 	testCommands(t, c, commands, prefix)
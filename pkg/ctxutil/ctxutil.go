@@ -31,6 +31,7 @@ const (
 	ctxKeyCommitTimestamp
 	ctxKeyShowParsing
 	ctxKeyHidden
+	ctxKeyCommandName
 )
 
 // ErrNoCallback is returned when no callback is set in the context.
@@ -39,11 +40,16 @@ var ErrNoCallback = fmt.Errorf("no callback")
 // WithGlobalFlags parses any global flags from the cli context and returns
 // a regular context.
 func WithGlobalFlags(c *cli.Context) context.Context {
+	ctx := c.Context
+	if c.Command != nil && c.Command.Name != "" {
+		ctx = WithCommandName(ctx, c.Command.FullName())
+	}
+
 	if c.Bool("yes") {
-		return WithAlwaysYes(c.Context, true)
+		return WithAlwaysYes(ctx, true)
 	}
 
-	return c.Context
+	return ctx
 }
 
 // ProgressCallback is a callback for updateing progress.
@@ -489,3 +495,20 @@ func IsHidden(ctx context.Context) bool {
 
 	return bv
 }
+
+// WithCommandName returns a context with the name of the command that is
+// being run.
+func WithCommandName(ctx context.Context, sv string) context.Context {
+	return context.WithValue(ctx, ctxKeyCommandName, sv)
+}
+
+// GetCommandName returns the name of the command that is being run or an
+// empty string.
+func GetCommandName(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyCommandName).(string)
+	if !ok {
+		return ""
+	}
+
+	return sv
+}
@@ -10,6 +10,7 @@ import (
 	_ "github.com/gopasspw/gopass/internal/backend/storage"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/queue"
+	"github.com/gopasspw/gopass/internal/reference"
 	"github.com/gopasspw/gopass/internal/store/root"
 	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/gopass"
@@ -58,11 +59,23 @@ func (g *Gopass) List(ctx context.Context) ([]string, error) {
 }
 
 // Get returns a single, encrypted secret. It must be unwrapped before use.
-// Use "latest" to get the latest revision.
+// Use "latest" to get the latest revision. References to other secrets are
+// returned as stored, so the secret can be modified and written back.
 func (g *Gopass) Get(ctx context.Context, name, revision string) (gopass.Secret, error) {
 	return g.rs.Get(ctx, name) //nolint:wrapcheck
 }
 
+// GetResolved is like Get but replaces references to other secrets with the
+// values they point to. The result must not be written back with Set.
+func (g *Gopass) GetResolved(ctx context.Context, name, revision string) (gopass.Secret, error) {
+	sec, err := g.Get(ctx, name, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return reference.Resolve(ctx, g.rs, name, sec) //nolint:wrapcheck
+}
+
 // Set adds a new revision to an existing secret or creates a new one.
 // Create new secrets with secrets.New().
 func (g *Gopass) Set(ctx context.Context, name string, sec gopass.Byter) error {
@@ -0,0 +1,235 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// TagsKey is the reserved key that holds the tags of a secret, e.g.
+// `tags: prod, pci, owner:payments`.
+const TagsKey = "tags"
+
+// tagger is implemented by secret types that need special handling to
+// retain their formatting when tags change.
+type tagger interface {
+	Tags() []string
+	SetTags(tags []string) error
+}
+
+// Tags returns the tags of the secret.
+func Tags(sec gopass.Secret) []string {
+	if t, ok := sec.(tagger); ok {
+		return t.Tags()
+	}
+
+	vs, found := sec.Values(TagsKey)
+	if !found {
+		return nil
+	}
+
+	return splitTags(vs...)
+}
+
+// SetTags replaces the tags of the secret. An empty list removes the tags
+// key. All other content of the secret is left untouched.
+func SetTags(sec gopass.Secret, tags []string) error {
+	if t, ok := sec.(tagger); ok {
+		return t.SetTags(tags)
+	}
+
+	tags = normalizeTags(tags)
+
+	// tags given on multiple lines are merged into the first one.
+	if vs, _ := sec.Values(TagsKey); len(vs) > 1 || len(tags) < 1 {
+		sec.Del(TagsKey)
+	}
+
+	if len(tags) < 1 {
+		return nil
+	}
+
+	return sec.Set(TagsKey, strings.Join(tags, ", "))
+}
+
+// ValidTag returns an error if the tag can not be stored.
+func ValidTag(tag string) error {
+	if strings.TrimSpace(tag) == "" {
+		return fmt.Errorf("tag must not be empty")
+	}
+
+	if strings.ContainsAny(tag, ",\n") {
+		return fmt.Errorf("tag %q must not contain commas or newlines", tag)
+	}
+
+	return nil
+}
+
+func splitTags(vs ...string) []string {
+	var tags []string
+	for _, v := range vs {
+		tags = append(tags, strings.Split(v, ",")...)
+	}
+
+	return normalizeTags(tags)
+}
+
+// normalizeTags trims and de-duplicates the tags. The order is kept.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+
+	return out
+}
+
+// Tags returns the tags of the secret. They can either be a YAML list or
+// a comma separated string.
+func (y *YAML) Tags() []string {
+	switch v := y.data[TagsKey].(type) {
+	case string:
+		return splitTags(v)
+	case []string:
+		return normalizeTags(v)
+	case []any:
+		tags := make([]string, 0, len(v))
+		for _, t := range v {
+			tags = append(tags, fmt.Sprintf("%v", t))
+		}
+
+		return normalizeTags(tags)
+	case nil:
+		return nil
+	default:
+		return splitTags(fmt.Sprintf("%v", v))
+	}
+}
+
+// SetTags replaces the tags of the secret. If the secret was parsed and not
+// modified otherwise only the lines holding the tags are rewritten.
+func (y *YAML) SetTags(tags []string) error {
+	tags = normalizeTags(tags)
+
+	if y.src == "" {
+		if len(tags) < 1 {
+			delete(y.data, TagsKey)
+
+			return nil
+		}
+		if y.data == nil {
+			y.data = make(map[string]any, 1)
+		}
+		y.data[TagsKey] = tags
+
+		return nil
+	}
+
+	src := setYAMLTags(y.src, tags)
+
+	data := make(map[string]any, len(y.data))
+	if err := yaml.NewDecoder(strings.NewReader(src)).Decode(data); err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	y.src = src
+	y.data = data
+	y.verbatim = true
+
+	return nil
+}
+
+// setYAMLTags replaces the top level tags key in the YAML source. A block
+// list stays a block list, a flow list a flow list and a string a string.
+// New tags are added as flow list.
+func setYAMLTags(src string, tags []string) string {
+	lines := strings.SplitAfter(src, "\n")
+
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, TagsKey+":") {
+			start = i
+
+			break
+		}
+	}
+
+	if start < 0 {
+		if len(tags) < 1 {
+			return src
+		}
+		if src != "" && !strings.HasSuffix(src, "\n") {
+			src += "\n"
+		}
+
+		return src + TagsKey + ": " + flowTags(tags) + "\n"
+	}
+
+	// the value continues on indented lines or block list items.
+	end := start + 1
+	for end < len(lines) {
+		line := lines[end]
+		if line == "" || (!strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-")) {
+			break
+		}
+		end++
+	}
+
+	value := strings.TrimSpace(strings.TrimPrefix(lines[start], TagsKey+":"))
+
+	var repl string
+	switch {
+	case len(tags) < 1:
+		repl = ""
+	case value == "" && end > start+1:
+		indent := lines[start+1][:len(lines[start+1])-len(strings.TrimLeft(lines[start+1], " \t"))]
+		var sb strings.Builder
+		sb.WriteString(TagsKey + ":\n")
+		for _, t := range tags {
+			sb.WriteString(indent + "- " + quoteTag(t) + "\n")
+		}
+		repl = sb.String()
+	case strings.HasPrefix(value, "[") || value == "":
+		repl = TagsKey + ": " + flowTags(tags) + "\n"
+	default:
+		repl = TagsKey + ": " + quoteTag(strings.Join(tags, ", ")) + "\n"
+	}
+
+	return strings.Join(lines[:start], "") + repl + strings.Join(lines[end:], "")
+}
+
+func flowTags(tags []string) string {
+	quoted := make([]string, 0, len(tags))
+	for _, t := range tags {
+		quoted = append(quoted, quoteTag(t))
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// quoteTag quotes the tag if it would not be parsed as the same string in
+// a YAML flow list, e.g. `yes`, `0123` or `owner:payments`.
+func quoteTag(t string) string {
+	buf, err := yaml.Marshal(t)
+	if err != nil {
+		return strconv.Quote(t)
+	}
+
+	plain := string(bytes.TrimSuffix(buf, []byte("\n")))
+	if plain != t || strings.ContainsAny(t, "[]{}:#") {
+		return strconv.Quote(t)
+	}
+
+	return t
+}
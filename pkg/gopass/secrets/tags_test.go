@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAKVTags(t *testing.T) {
+	t.Parallel()
+
+	in := "secret\nuser: john\n# a comment\ntags: prod, pci\nurl: https://example.com\nbody text\n"
+	a := ParseAKV([]byte(in))
+	assert.Equal(t, []string{"prod", "pci"}, Tags(a))
+
+	require.NoError(t, SetTags(a, []string{"prod", "pci", "owner:payments"}))
+	assert.Equal(t, "secret\nuser: john\n# a comment\ntags: prod, pci, owner:payments\nurl: https://example.com\nbody text\n", string(a.Bytes()))
+	assert.Equal(t, []string{"prod", "pci", "owner:payments"}, Tags(a))
+
+	require.NoError(t, SetTags(a, nil))
+	assert.Equal(t, "secret\nuser: john\n# a comment\nurl: https://example.com\nbody text\n", string(a.Bytes()))
+	assert.Empty(t, Tags(a))
+
+	require.NoError(t, SetTags(a, []string{" prod ", "prod", ""}))
+	assert.Equal(t, "secret\nuser: john\n# a comment\nurl: https://example.com\nbody text\ntags: prod\n", string(a.Bytes()))
+
+	// multiple lines are merged
+	a = ParseAKV([]byte("secret\ntags: a\ntags: b\n"))
+	assert.Equal(t, []string{"a", "b"}, Tags(a))
+	require.NoError(t, SetTags(a, []string{"a", "b", "c"}))
+	assert.Equal(t, "secret\ntags: a, b, c\n", string(a.Bytes()))
+}
+
+func TestYAMLTags(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		in   string
+		tags []string
+		want string
+	}{
+		{
+			name: "flow list",
+			in:   "secret\n---\n# comment\nuser:   john\ntags: [prod]\npin: \"0123\"\n",
+			tags: []string{"prod", "owner:payments", "yes"},
+			want: "secret\n---\n# comment\nuser:   john\ntags: [prod, \"owner:payments\", \"yes\"]\npin: \"0123\"\n",
+		},
+		{
+			name: "block list",
+			in:   "secret\n---\ntags:\n  - prod\n  - pci\nuser: john # inline\n",
+			tags: []string{"prod"},
+			want: "secret\n---\ntags:\n  - prod\nuser: john # inline\n",
+		},
+		{
+			name: "string",
+			in:   "secret\n---\ntags: prod, pci\nuser: john\n",
+			tags: []string{"pci"},
+			want: "secret\n---\ntags: pci\nuser: john\n",
+		},
+		{
+			name: "new",
+			in:   "secret\nbody\n---\nuser: john\n",
+			tags: []string{"prod"},
+			want: "secret\nbody\n---\nuser: john\ntags: [prod]\n",
+		},
+		{
+			name: "remove",
+			in:   "secret\n---\ntags:\n- prod\nuser: john\n",
+			tags: nil,
+			want: "secret\n---\nuser: john\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			y, err := ParseYAML([]byte(tc.in))
+			require.NoError(t, err)
+
+			require.NoError(t, SetTags(y, tc.tags))
+			assert.Equal(t, tc.want, string(y.Bytes()))
+			assert.Equal(t, tc.tags, Tags(y))
+
+			v, found := y.Get("user")
+			assert.True(t, found)
+			assert.Equal(t, "john", v)
+		})
+	}
+
+	t.Run("modified", func(t *testing.T) {
+		t.Parallel()
+
+		y, err := ParseYAML([]byte("secret\n---\nuser: john\n"))
+		require.NoError(t, err)
+		require.NoError(t, y.Set("url", "example.com"))
+		require.NoError(t, SetTags(y, []string{"prod"}))
+		assert.Equal(t, []string{"prod"}, Tags(y))
+		assert.Equal(t, "secret\n---\ntags:\n    - prod\nurl: example.com\nuser: john\n", string(y.Bytes()))
+	})
+}
+
+func TestValidTag(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidTag("owner:payments"))
+	require.Error(t, ValidTag(""))
+	require.Error(t, ValidTag("a,b"))
+}
@@ -42,6 +42,11 @@ type YAML struct {
 	password string
 	data     map[string]any
 	body     string
+	// src is the YAML section as parsed. It is discarded when a key is
+	// changed with Set or Del. After SetTags it is written back instead of
+	// the re-encoded data to retain comments and formatting.
+	src      string
+	verbatim bool
 }
 
 // Keys returns all keys.
@@ -80,6 +85,7 @@ func (y *YAML) Set(key string, value any) error {
 	}
 
 	y.data[key] = value
+	y.src, y.verbatim = "", false
 
 	return nil
 }
@@ -94,6 +100,7 @@ func (y *YAML) Del(key string) bool {
 	_, found := y.data[key]
 
 	delete(y.data, key)
+	y.src, y.verbatim = "", false
 
 	return found
 }
@@ -126,9 +133,20 @@ func ParseYAML(in []byte) (*YAML, error) {
 		y.body = body
 	}
 
-	if err := yaml.NewDecoder(r).Decode(y.data); err != nil && !errors.Is(err, io.EOF) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read YAML section: %w", err)
+	}
+
+	if err := yaml.NewDecoder(bytes.NewReader(src)).Decode(y.data); err != nil && !errors.Is(err, io.EOF) {
 		return nil, fmt.Errorf("failed to decode YAML secret: %w", err)
 	}
+	// the document marker is written by Bytes.
+	if first, rest, found := strings.Cut(string(src), "\n"); found && strings.TrimSpace(first) == "---" {
+		y.src = rest
+	} else {
+		y.src = string(src)
+	}
 
 	return y, nil
 }
@@ -205,6 +223,12 @@ func (y *YAML) Bytes() []byte {
 
 		buf.WriteString("---\n")
 
+		if y.verbatim {
+			buf.WriteString(y.src)
+
+			return buf.Bytes()
+		}
+
 		if err := yaml.NewEncoder(buf).Encode(y.data); err != nil {
 			debug.Log("failed to encode YAML: %s", err)
 		}
@@ -0,0 +1,214 @@
+package pwgen
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrInvalidMask is returned when a mask can not be parsed.
+var ErrInvalidMask = fmt.Errorf("invalid mask")
+
+// MaskClasses are the built-in character classes available in masks.
+//
+//	?l - lowercase letters
+//	?u - uppercase letters
+//	?d - digits
+//	?s - symbols
+//	?a - all of the above
+//	?h - lowercase hex digits
+//	?H - uppercase hex digits
+//	?? - a literal ?
+var MaskClasses = map[rune]string{
+	'l': Lower,
+	'u': Upper,
+	'd': Digits,
+	's': Syms,
+	'a': CharAll,
+	'h': Digits + "abcdef",
+	'H': Digits + "ABCDEF",
+}
+
+// Mask is a generator for passwords with a fixed shape, e.g. `?u?l?l?l-?d?d?d?d`.
+// Each position of the mask is either a literal character or a character
+// class. Classes are written as `?x` (see MaskClasses), as custom classes
+// `?1` to `?9` or as inline sets like `[a-f0-9]`. Any character can be
+// escaped with a backslash to be used literally.
+type Mask struct {
+	Pattern string
+	// Custom maps the custom classes ?1 to ?9 to their characters.
+	Custom map[rune]string
+
+	// each position holds the characters it can be chosen from.
+	positions []string
+}
+
+// NewMask parses the given mask pattern. custom may contain the definitions
+// for the custom classes ?1 to ?9.
+func NewMask(pattern string, custom map[rune]string) (*Mask, error) {
+	m := &Mask{
+		Pattern: pattern,
+		Custom:  custom,
+	}
+
+	if err := m.parse(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Mask) parse() error {
+	if m.Pattern == "" {
+		return fmt.Errorf("empty mask: %w", ErrInvalidMask)
+	}
+
+	rs := []rune(m.Pattern)
+	m.positions = make([]string, 0, len(rs))
+
+	for i := 0; i < len(rs); i++ {
+		switch rs[i] {
+		case '\\':
+			if i+1 >= len(rs) {
+				return fmt.Errorf("trailing escape character: %w", ErrInvalidMask)
+			}
+			i++
+			m.positions = append(m.positions, string(rs[i]))
+		case '?':
+			if i+1 >= len(rs) {
+				return fmt.Errorf("trailing ?: %w", ErrInvalidMask)
+			}
+			i++
+			chars, err := m.class(rs[i])
+			if err != nil {
+				return err
+			}
+			m.positions = append(m.positions, chars)
+		case '[':
+			end := i + 1
+			for end < len(rs) && rs[end] != ']' {
+				if rs[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(rs) {
+				return fmt.Errorf("unterminated character set at position %d: %w", i, ErrInvalidMask)
+			}
+			chars, err := expandSet(rs[i+1 : end])
+			if err != nil {
+				return err
+			}
+			m.positions = append(m.positions, chars)
+			i = end
+		default:
+			m.positions = append(m.positions, string(rs[i]))
+		}
+	}
+
+	return nil
+}
+
+func (m *Mask) class(r rune) (string, error) {
+	if r == '?' {
+		return "?", nil
+	}
+
+	if r >= '1' && r <= '9' {
+		chars, found := m.Custom[r]
+		if !found || chars == "" {
+			return "", fmt.Errorf("custom class ?%c is not defined: %w", r, ErrInvalidMask)
+		}
+
+		return uniqueChars(chars), nil
+	}
+
+	chars, found := MaskClasses[r]
+	if !found {
+		return "", fmt.Errorf("unknown character class ?%c: %w", r, ErrInvalidMask)
+	}
+
+	return chars, nil
+}
+
+// expandSet expands an inline set like a-f0-9 into its characters.
+func expandSet(rs []rune) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		if r == '\\' && i+1 < len(rs) {
+			i++
+			sb.WriteRune(rs[i])
+
+			continue
+		}
+
+		if i+2 < len(rs) && rs[i+1] == '-' {
+			to := rs[i+2]
+			if to < r {
+				return "", fmt.Errorf("invalid range %c-%c: %w", r, to, ErrInvalidMask)
+			}
+			for c := r; c <= to; c++ {
+				sb.WriteRune(c)
+			}
+			i += 2
+
+			continue
+		}
+
+		sb.WriteRune(r)
+	}
+
+	chars := uniqueChars(sb.String())
+	if chars == "" {
+		return "", fmt.Errorf("empty character set: %w", ErrInvalidMask)
+	}
+
+	return chars, nil
+}
+
+// Password generates a new password matching the mask.
+func (m *Mask) Password() string {
+	var sb strings.Builder
+
+	for _, chars := range m.positions {
+		rs := []rune(chars)
+		if len(rs) == 1 {
+			sb.WriteRune(rs[0])
+
+			continue
+		}
+		sb.WriteRune(rs[randomInteger(len(rs))])
+	}
+
+	return sb.String()
+}
+
+// Len returns the length of the generated passwords (in runes).
+func (m *Mask) Len() int {
+	return len(m.positions)
+}
+
+// Entropy returns the entropy of passwords generated from this mask in bits.
+func (m *Mask) Entropy() float64 {
+	var e float64
+
+	for _, chars := range m.positions {
+		if n := len([]rune(chars)); n > 1 {
+			e += math.Log2(float64(n))
+		}
+	}
+
+	return e
+}
+
+// GenerateMask generates a password matching the given mask.
+func GenerateMask(pattern string) (string, error) {
+	m, err := NewMask(pattern, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return m.Password(), nil
+}
@@ -0,0 +1,82 @@
+package pwgen
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleGenerateMask() { //nolint:testableexamples
+	fmt.Println(GenerateMask("?u?l?l?l-?d?d?d?d"))
+}
+
+func TestMask(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		mask    string
+		custom  map[rune]string
+		re      string
+		entropy float64
+	}{
+		{
+			mask:    "?u?l?l?l-?d?d?d?d",
+			re:      `^[A-Z][a-z]{3}-[0-9]{4}$`,
+			entropy: math.Log2(26)*4 + math.Log2(10)*4,
+		},
+		{
+			mask:    "XXXX-?d?d",
+			re:      `^XXXX-[0-9]{2}$`,
+			entropy: math.Log2(10) * 2,
+		},
+		{
+			mask:    `\?\[??[a-c][x\]]`,
+			re:      `^\?\[\?[a-c][x\]]$`,
+			entropy: math.Log2(3) + 1,
+		},
+		{
+			mask:    "?1?1?2",
+			custom:  map[rune]string{'1': "ab", '2': "xyzx"},
+			re:      `^[ab]{2}[xyz]$`,
+			entropy: 2 + math.Log2(3),
+		},
+		{
+			mask:    "?h?h?H?H",
+			re:      `^[0-9a-f]{2}[0-9A-F]{2}$`,
+			entropy: 16,
+		},
+	} {
+		m, err := NewMask(tc.mask, tc.custom)
+		require.NoError(t, err, tc.mask)
+		assert.InDelta(t, tc.entropy, m.Entropy(), 0.0001, tc.mask)
+
+		re := regexp.MustCompile(tc.re)
+		for range 20 {
+			pw := m.Password()
+			assert.Regexp(t, re, pw, tc.mask)
+			assert.Len(t, []rune(pw), m.Len())
+		}
+	}
+}
+
+func TestMaskInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, mask := range []string{
+		"",
+		"abc?",
+		`abc\`,
+		"?x",
+		"?1",
+		"[abc",
+		"[]",
+		"[z-a]",
+	} {
+		_, err := NewMask(mask, nil)
+		require.ErrorIs(t, err, ErrInvalidMask, mask)
+	}
+}
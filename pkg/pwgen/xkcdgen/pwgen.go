@@ -34,3 +34,20 @@ func RandomLengthDelim(length int, delim, lang string, capitalize, numbers bool)
 
 	return g.GeneratePasswordString(), nil
 }
+
+// RandomLengthDelimWordlist returns a random passphrase combined from the desired
+// number of words and the given delimiter. Words are drawn from the given wordlist.
+func RandomLengthDelimWordlist(length int, delim string, wl Wordlist, capitalize, numbers bool) (string, error) {
+	if len(wl) < 2 {
+		return "", fmt.Errorf("wordlist needs at least two words: %w", ErrInvalidWordlist)
+	}
+
+	g := xkcdpwgen.NewGenerator()
+	g.SetNumWords(length)
+	g.SetDelimiter(delim)
+	g.SetCapitalize(delim == "" || capitalize)
+	g.SetRandomNumbers(numbers)
+	g.UseCustomWordlist(wl)
+
+	return g.GeneratePasswordString(), nil
+}
@@ -0,0 +1,169 @@
+package xkcdgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidWordlist is returned when a wordlist fails validation.
+var ErrInvalidWordlist = fmt.Errorf("invalid wordlist")
+
+// builtinSizes are the number of words in the wordlists that are shipped
+// with goxkcdpwgen. They are not exported, so we need to keep track of them
+// here to compute the entropy.
+var builtinSizes = map[string]int{
+	"en":           7776,
+	"en_eff_short": 1296,
+	"de":           7776,
+	"de_short":     1296,
+}
+
+// Wordlist is a list of unique words for passphrase generation.
+type Wordlist []string
+
+// ParseWordlist parses a wordlist. It accepts either one word per line
+// or the EFF / diceware format where each word is prefixed by its dice roll,
+// e.g. "11111	abacus". Empty lines and lines starting with # are ignored.
+// The words must be unique, ignoring case, since capitalized words would
+// otherwise repeat.
+func ParseWordlist(buf []byte) (Wordlist, error) {
+	wl := make(Wordlist, 0, 7776)
+	seen := make(map[string]int, 7776)
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	var lineNo int
+	for scanner.Scan() {
+		lineNo++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		word := fields[0]
+		if len(fields) == 2 && isDiceRoll(fields[0]) {
+			word = fields[1]
+		}
+		if len(fields) > 2 || (len(fields) == 2 && !isDiceRoll(fields[0])) {
+			return nil, fmt.Errorf("line %d: expected one word per line, got %q: %w", lineNo, line, ErrInvalidWordlist)
+		}
+
+		key := strings.ToLower(word)
+		if prev, found := seen[key]; found {
+			return nil, fmt.Errorf("line %d: duplicate word %q (first seen on line %d): %w", lineNo, word, prev, ErrInvalidWordlist)
+		}
+		seen[key] = lineNo
+
+		wl = append(wl, word)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wordlist: %w", err)
+	}
+
+	if len(wl) < 2 {
+		return nil, fmt.Errorf("wordlist needs at least two words, got %d: %w", len(wl), ErrInvalidWordlist)
+	}
+
+	return wl, nil
+}
+
+func isDiceRoll(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// CheckSeparable checks that passphrases built with the given separator can
+// be split into their words again. Without a separator the words are
+// capitalized, which already marks where each word starts. Only lists with
+// words that capitalization does not mark have to be prefix-free.
+func (w Wordlist) CheckSeparable(sep string) error {
+	if sep != "" {
+		return nil
+	}
+
+	for _, word := range w {
+		if !marksStart(word) {
+			return w.CheckPrefixFree()
+		}
+	}
+
+	return nil
+}
+
+// marksStart returns true if capitalizing the word only changes its first
+// letter from lower to upper case, so the upper case letters of a
+// passphrase are exactly the starts of its words.
+func marksStart(word string) bool {
+	for i, r := range word {
+		if i == 0 && !unicode.IsLower(r) {
+			return false
+		}
+		// strings.Title would capitalize letters following other characters.
+		if !unicode.IsLetter(r) || unicode.IsUpper(r) || unicode.IsTitle(r) {
+			return false
+		}
+	}
+
+	return word != ""
+}
+
+// CheckPrefixFree checks that no word in the list is a prefix of another word,
+// ignoring case. Passphrases built from such lists without a separator can not
+// be split into their words unambiguously, which reduces their entropy.
+func (w Wordlist) CheckPrefixFree() error {
+	sorted := make([]string, len(w))
+	for i, word := range w {
+		sorted[i] = strings.ToLower(word)
+	}
+	sort.Strings(sorted)
+
+	// after sorting, a word that is a prefix of another word is always
+	// directly followed by a word it is a prefix of.
+	for i := 1; i < len(sorted); i++ {
+		if strings.HasPrefix(sorted[i], sorted[i-1]) {
+			return fmt.Errorf("%q is a prefix of %q: %w", sorted[i-1], sorted[i], ErrInvalidWordlist)
+		}
+	}
+
+	return nil
+}
+
+// Entropy returns the entropy in bits of a passphrase with the given
+// number of words drawn from this list.
+func (w Wordlist) Entropy(numWords int) float64 {
+	return Entropy(numWords, len(w))
+}
+
+// Entropy returns the entropy in bits of a passphrase with numWords
+// words drawn from a list of listLen words. Random numbers added to the words
+// are not taken into account, so this is a lower bound.
+func Entropy(numWords, listLen int) float64 {
+	if numWords < 1 || listLen < 2 {
+		return 0
+	}
+
+	return float64(numWords) * math.Log2(float64(listLen))
+}
+
+// LangEntropy returns the entropy in bits of a passphrase with numWords words
+// drawn from the built-in wordlist for lang.
+func LangEntropy(numWords int, lang string) (float64, error) {
+	size, found := builtinSizes[lang]
+	if !found {
+		return 0, fmt.Errorf("language %q has no matching wordlist", lang)
+	}
+
+	return Entropy(numWords, size), nil
+}
@@ -0,0 +1,123 @@
+package xkcdgen
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWordlist(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		in   string
+		want Wordlist
+		err  bool
+	}{
+		{
+			name: "plain",
+			in:   "apfel\nbirne\n\n# comment\nkirsche\n",
+			want: Wordlist{"apfel", "birne", "kirsche"},
+		},
+		{
+			name: "eff",
+			in:   "11111\tabacus\n11112\tabdomen\n11113\tabdominal\n",
+			want: Wordlist{"abacus", "abdomen", "abdominal"},
+		},
+		{
+			name: "duplicates",
+			in:   "pomme\npoire\npomme\n",
+			err:  true,
+		},
+		{
+			name: "duplicates ignoring case",
+			in:   "Pomme\npoire\npomme\n",
+			err:  true,
+		},
+		{
+			name: "too short",
+			in:   "pomme\n",
+			err:  true,
+		},
+		{
+			name: "multiple words per line",
+			in:   "pomme de terre\npoire\n",
+			err:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			wl, err := ParseWordlist([]byte(tc.in))
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidWordlist)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, wl)
+		})
+	}
+}
+
+func TestCheckPrefixFree(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Wordlist{"apfel", "birne", "kirsche"}.CheckPrefixFree())
+	require.ErrorIs(t, Wordlist{"abdominal", "birne", "abdomen", "abdomen2"}.CheckPrefixFree(), ErrInvalidWordlist)
+	require.ErrorIs(t, Wordlist{"Abdomen", "birne", "abdomen2"}.CheckPrefixFree(), ErrInvalidWordlist)
+}
+
+func TestCheckSeparable(t *testing.T) {
+	t.Parallel()
+
+	// capitalization marks the start of each word.
+	require.NoError(t, Wordlist{"abdomen", "abdominal", "birne"}.CheckSeparable(""))
+	require.NoError(t, Wordlist{"ab1", "ab12", "birne"}.CheckSeparable("-"))
+
+	// unless a word does not start with a lower case letter or would be
+	// capitalized in the middle.
+	for _, wl := range []Wordlist{
+		{"ab1", "ab12", "birne"},
+		{"yo", "yo-yo", "birne"},
+		{"Abdomen", "abdomen2", "birne"},
+	} {
+		require.ErrorIs(t, wl.CheckSeparable(""), ErrInvalidWordlist, wl)
+	}
+	require.NoError(t, Wordlist{"yo-yo", "birne"}.CheckSeparable(""))
+}
+
+func TestEntropy(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 4*math.Log2(7776), Wordlist(make([]string, 7776)).Entropy(4), 0.0001)
+	assert.InDelta(t, 0.0, Entropy(0, 7776), 0.0001)
+
+	e, err := LangEntropy(5, "de")
+	require.NoError(t, err)
+	assert.InDelta(t, 5*math.Log2(7776), e, 0.0001)
+
+	_, err = LangEntropy(5, "fr")
+	require.Error(t, err)
+}
+
+func TestRandomLengthDelimWordlist(t *testing.T) {
+	t.Parallel()
+
+	wl := Wordlist{"pomme", "poire", "cerise"}
+	pw, err := RandomLengthDelimWordlist(5, "-", wl, false, false)
+	require.NoError(t, err)
+
+	words := strings.Split(pw, "-")
+	assert.Len(t, words, 5)
+	for _, w := range words {
+		assert.Contains(t, wl, w)
+	}
+
+	_, err = RandomLengthDelimWordlist(5, "-", Wordlist{"pomme"}, false, false)
+	require.Error(t, err)
+}
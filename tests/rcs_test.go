@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRCS runs the same workflow against every storage backend with a
+// revision control system: history, revisions, fsck, clone, sync with key
+// export, conversion, trash and undelete.
+func TestRCS(t *testing.T) {
+	for _, tc := range []struct {
+		storage string
+		binary  string
+		convert string
+		remote  func(t *testing.T, ts *tester) string
+	}{
+		{
+			storage: "gitfs",
+			binary:  "git",
+			convert: "gogit",
+			remote:  gitRemote,
+		},
+		{
+			storage: "fossilfs",
+			binary:  "fossil",
+			convert: "gitfs",
+			remote:  fossilRemote,
+		},
+	} {
+		t.Run(tc.storage, func(t *testing.T) {
+			if _, err := exec.LookPath(tc.binary); err != nil {
+				t.Skipf("%s not found", tc.binary)
+			}
+
+			ts := newTester(t)
+			defer ts.teardown()
+
+			t.Setenv("GIT_AUTHOR_NAME", "Dead Beef")
+			t.Setenv("GIT_AUTHOR_EMAIL", "dead.beef@example.org")
+			t.Setenv("GIT_COMMITTER_NAME", "Dead Beef")
+			t.Setenv("GIT_COMMITTER_EMAIL", "dead.beef@example.org")
+			// fossil keeps its global config in FOSSIL_HOME and needs a
+			// user name to create repositories.
+			t.Setenv("FOSSIL_HOME", ts.tempDir)
+			t.Setenv("USER", "gopass")
+
+			out, err := ts.run("init --crypto=gpgcli --storage=" + tc.storage + " " + keyID)
+			require.NoError(t, err, out)
+
+			_, err = ts.runCmd([]string{ts.Binary, "insert", "foo"}, []byte("first"))
+			require.NoError(t, err)
+			_, err = ts.runCmd([]string{ts.Binary, "insert", "-f", "foo"}, []byte("second"))
+			require.NoError(t, err)
+
+			t.Run("history", func(t *testing.T) {
+				out, err := ts.run("history foo")
+				require.NoError(t, err, out)
+				assert.Equal(t, 2, countRevisions(out), out)
+			})
+
+			t.Run("show revision", func(t *testing.T) {
+				out, err := ts.run("show --revision=-1 -o foo")
+				require.NoError(t, err, out)
+				assert.Equal(t, "first", out)
+			})
+
+			t.Run("fsck", func(t *testing.T) {
+				out, err := ts.run("fsck")
+				require.NoError(t, err, out)
+			})
+
+			remote := tc.remote(t, ts)
+			out, err = ts.run("sync")
+			require.NoError(t, err, out)
+
+			t.Run("clone", func(t *testing.T) {
+				out, err := ts.run("clone --storage=" + tc.storage + " " + remote + " team")
+				require.NoError(t, err, out)
+
+				out, err = ts.run("show -o team/foo")
+				require.NoError(t, err, out)
+				assert.Equal(t, "second", out)
+			})
+
+			t.Run("sync", func(t *testing.T) {
+				_, err := ts.runCmd([]string{ts.Binary, "insert", "bar"}, []byte("from root"))
+				require.NoError(t, err)
+				_, err = ts.runCmd([]string{ts.Binary, "insert", "team/baz"}, []byte("from team"))
+				require.NoError(t, err)
+
+				// the root store is synced before the team store, so the
+				// second sync brings the changes of the team to the root.
+				for range 2 {
+					out, err := ts.run("sync")
+					require.NoError(t, err, out)
+				}
+
+				out, err := ts.run("show -o team/bar")
+				require.NoError(t, err, out)
+				assert.Equal(t, "from root", out)
+
+				out, err = ts.run("show -o baz")
+				require.NoError(t, err, out)
+				assert.Equal(t, "from team", out)
+			})
+
+			t.Run("export keys", func(t *testing.T) {
+				out, err := ts.run("config core.exportkeys true")
+				require.NoError(t, err, out)
+
+				for range 2 {
+					out, err := ts.run("sync")
+					require.NoError(t, err, out)
+				}
+
+				for _, mount := range []string{"root", "team"} {
+					keys, err := os.ReadDir(filepath.Join(ts.storeDir(mount), ".public-keys"))
+					require.NoError(t, err, mount)
+					assert.NotEmpty(t, keys, mount)
+				}
+			})
+
+			t.Run("convert", func(t *testing.T) {
+				for _, storage := range []string{tc.convert, tc.storage} {
+					out, err := ts.run("convert --store=team --storage=" + storage + " --move=true")
+					require.NoError(t, err, out)
+
+					out, err = ts.run("history team/foo")
+					require.NoError(t, err, out)
+					assert.Equal(t, 2, countRevisions(out), out)
+
+					out, err = ts.run("show --revision=-1 -o team/foo")
+					require.NoError(t, err, out)
+					assert.Equal(t, "first", out)
+				}
+			})
+
+			t.Run("undelete", func(t *testing.T) {
+				out, err := ts.run("rm -f foo")
+				require.NoError(t, err, out)
+
+				out, err = ts.run("trash")
+				require.NoError(t, err, out)
+				assert.Contains(t, out, "foo")
+
+				out, err = ts.run("undelete foo")
+				require.NoError(t, err, out)
+
+				out, err = ts.run("show -o foo")
+				require.NoError(t, err, out)
+				assert.Equal(t, "second", out)
+			})
+		})
+	}
+}
+
+// gitRemote adds an empty bare repository as the origin of the root store.
+func gitRemote(t *testing.T, ts *tester) string {
+	t.Helper()
+
+	remote := filepath.Join(ts.tempDir, "remote.git")
+	out, err := ts.runCmd([]string{"git", "init", "--bare", remote}, nil)
+	require.NoError(t, err, out)
+
+	out, err = ts.runCmd([]string{"git", "-C", ts.storeDir("root"), "remote", "add", "origin", remote}, nil)
+	require.NoError(t, err, out)
+
+	return remote
+}
+
+// fossilRemote clones the repository of the root store and makes the clone
+// its default remote.
+func fossilRemote(t *testing.T, ts *tester) string {
+	t.Helper()
+
+	repo := filepath.Join(filepath.Dir(ts.storeDir("root")), ".root.fossil")
+	require.FileExists(t, repo)
+
+	remote := filepath.Join(ts.tempDir, "remote.fossil")
+	out, err := ts.runCmd([]string{"fossil", "clone", "file://" + filepath.ToSlash(repo), remote}, nil)
+	require.NoError(t, err, out)
+
+	cmd := exec.Command("fossil", "remote", "file://"+filepath.ToSlash(remote))
+	cmd.Dir = ts.storeDir("root")
+	buf, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(buf))
+
+	return remote
+}
+
+// countRevisions counts the revisions in the output of gopass history.
+func countRevisions(out string) int {
+	n := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+
+	return n
+}